@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"os"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/go-resty/resty/v2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/htrandev/metrics/internal/agent"
@@ -22,6 +24,7 @@ import (
 	"github.com/htrandev/metrics/pkg/crypto"
 	"github.com/htrandev/metrics/pkg/logger"
 	"github.com/htrandev/metrics/pkg/netutil"
+	"github.com/htrandev/metrics/pkg/tlsutil"
 )
 
 func main() {
@@ -60,10 +63,23 @@ func run() error {
 		return fmt.Errorf("get local ip addr: %w", err)
 	}
 
+	var tlsConfig *tls.Config
+	if conf.UseTLS() {
+		zl.Info("init tls config")
+		tlsConfig, err = tlsutil.ClientConfig(conf.TLSCertFile, conf.TLSKeyFile, conf.TLSCAFile, conf.TLSServerName)
+		if err != nil {
+			return fmt.Errorf("init tls config: %w", err)
+		}
+	}
+
 	var client agent.Client
 	if conf.UseGRPC {
 		zl.Info("init grpc conn")
-		conn, err := grpc.NewClient(conf.GRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		creds := insecure.NewCredentials()
+		if tlsConfig != nil {
+			creds = credentials.NewTLS(tlsConfig)
+		}
+		conn, err := grpc.NewClient(conf.GRPCAddr, grpc.WithTransportCredentials(creds))
 		if err != nil {
 			return fmt.Errorf("init grpc client conn: %w", err)
 		}
@@ -78,6 +94,8 @@ func run() error {
 			metricsclient.WithLogger(zl),
 			metricsclient.WithPublicKey(publicKey),
 			metricsclient.WithSignature(conf.Signature),
+			metricsclient.WithSignatureKeyID(conf.SignatureKeyID),
+			metricsclient.WithToken(conf.Token),
 			metricsclient.WithIP(ip.String()),
 		)
 	} else {
@@ -87,11 +105,14 @@ func run() error {
 
 		zl.Info("create http client")
 		client = metricsclient.NewHTTP(restyClient,
+			metricsclient.WithTLS(tlsConfig),
 			metricsclient.WithMaxRetry(conf.MaxRetry),
 			metricsclient.WithAddr(conf.Addr),
 			metricsclient.WithLogger(zl),
 			metricsclient.WithPublicKey(publicKey),
 			metricsclient.WithSignature(conf.Signature),
+			metricsclient.WithSignatureKeyID(conf.SignatureKeyID),
+			metricsclient.WithToken(conf.Token),
 			metricsclient.WithIP(ip.String()),
 		)
 	}
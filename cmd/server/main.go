@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -19,23 +22,34 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/htrandev/metrics/internal/alerting"
 	"github.com/htrandev/metrics/internal/audit"
+	"github.com/htrandev/metrics/internal/auth"
 	"github.com/htrandev/metrics/internal/config"
 	grpcserver "github.com/htrandev/metrics/internal/grpc"
 	"github.com/htrandev/metrics/internal/grpc/interceptors"
 	"github.com/htrandev/metrics/internal/handler"
 	"github.com/htrandev/metrics/internal/info"
+	"github.com/htrandev/metrics/internal/lineproto"
 	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/internal/notify"
 	"github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/internal/recording"
+	"github.com/htrandev/metrics/internal/remotewrite"
 	"github.com/htrandev/metrics/internal/repository/local"
 	"github.com/htrandev/metrics/internal/repository/postgres"
 	"github.com/htrandev/metrics/internal/router"
 	"github.com/htrandev/metrics/internal/service/metrics"
+	"github.com/htrandev/metrics/internal/silence"
+	"github.com/htrandev/metrics/internal/statsd"
 	"github.com/htrandev/metrics/migrations"
 	"github.com/htrandev/metrics/pkg/crypto"
 	"github.com/htrandev/metrics/pkg/logger"
 	"github.com/htrandev/metrics/pkg/netutil"
+	"github.com/htrandev/metrics/pkg/sign"
+	"github.com/htrandev/metrics/pkg/tlsutil"
 
 	_ "net/http/pprof"
 
@@ -43,12 +57,56 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == verifyAuditCmd {
+		if err := verifyAudit(os.Args[2:]); err != nil {
+			log.Printf("verify audit: %s", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		log.Printf("run ends with error: %s", err.Error())
 		os.Exit(1)
 	}
 }
 
+// verifyAuditCmd подкоманда проверки цепочки хэшей файла аудита:
+//
+//	server verify-audit <путь к файлу аудита>
+//
+// Проверяются все ротированные сегменты файла и сам файл.
+const verifyAuditCmd = "verify-audit"
+
+// verifyAudit проверяет цепочку хэшей файла аудита и его сегментов.
+func verifyAudit(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s %s <audit file>", os.Args[0], verifyAuditCmd)
+	}
+
+	segments, err := audit.Segments(args[0])
+	if err != nil {
+		return fmt.Errorf("find segments: %w", err)
+	}
+
+	res, err := audit.VerifyChain(append(segments, args[0])...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("audit chain is valid: records=%d head=%s", res.Records, res.Head)
+	if !res.Genesis {
+		log.Printf("chain does not start at genesis record: older segments were removed by retention")
+	}
+	return nil
+}
+
+// shutdownTimeout время на завершение серверов и доставку событий аудита.
+const shutdownTimeout = 10 * time.Second
+
+// auditReportInterval период записи в лог отброшенных событий аудита.
+const auditReportInterval = time.Minute
+
 func run() error {
 	info.PrintBuildInfo()
 
@@ -81,10 +139,20 @@ func run() error {
 	})
 
 	zl.Info("init publisher")
-	auditor := audit.NewAuditor()
+	auditPolicy, err := audit.ParsePolicy(cfg.AuditOverflow)
+	if err != nil {
+		return fmt.Errorf("parse audit overflow policy: %w", err)
+	}
+	auditor := audit.NewAuditor(
+		audit.WithQueueSize(cfg.AuditQueue),
+		audit.WithPolicy(auditPolicy),
+		audit.WithLogger(zl),
+	)
 
 	zl.Info("init subscribers")
 	subs := make([]audit.Observer, 0, 2)
+	// closers закрываются после доставки оставшихся событий аудита
+	closers := make([]io.Closer, 0, 2)
 
 	if cfg.AuditFile != "" {
 		zl.Info("init file auditor")
@@ -94,8 +162,23 @@ func run() error {
 			return fmt.Errorf("open audit file: %w", err)
 		}
 
-		fileAudit := audit.NewFile(uuid.New(), f, zl)
+		fileOpts := []audit.FileOption{
+			audit.WithRotation(cfg.AuditMaxSize, cfg.AuditMaxAge),
+			audit.WithRetention(cfg.AuditRetention),
+		}
+		if cfg.AuditCompress {
+			fileOpts = append(fileOpts, audit.WithCompression())
+		}
+		if cfg.AuditChain {
+			fileOpts = append(fileOpts, audit.WithHashChain())
+		}
+
+		fileAudit, err := audit.NewFile(uuid.New(), f, zl, fileOpts...)
+		if err != nil {
+			return fmt.Errorf("init file auditor: %w", err)
+		}
 		subs = append(subs, fileAudit)
+		closers = append(closers, fileAudit)
 	}
 
 	if cfg.AuditURL != "" {
@@ -103,15 +186,108 @@ func run() error {
 		auditClient := resty.New().
 			SetTimeout(30 * time.Second)
 
-		urlAudit := audit.NewURL(uuid.New(), cfg.AuditURL, auditClient, zl)
+		urlOpts := []audit.URLOption{
+			audit.WithBatch(cfg.AuditBatch, cfg.AuditFlush),
+			audit.WithRetry(cfg.AuditRetry, 0, 0),
+			audit.WithDeadLetter(cfg.AuditDeadFile),
+		}
+		if cfg.AuditSecret != "" {
+			urlOpts = append(urlOpts, audit.WithSigning(sign.DefaultKeyID, sign.Signature(cfg.AuditSecret)))
+		}
+
+		urlAudit := audit.NewURL(uuid.New(), cfg.AuditURL, auditClient, zl, urlOpts...)
 		subs = append(subs, urlAudit)
+		closers = append(closers, urlAudit)
 	}
 
 	zl.Info("register subscribers")
 	registerSubscribers(auditor, subs...)
 
+	// HTTP и TCP прием InfluxDB line protocol учитывают накопленные значения счетчиков вместе
+	lineConverter := lineproto.NewConverter()
+	handlerOpts := []handler.Option{
+		handler.WithRemoteWrite(remotewrite.NewConverter(cfg.CounterSuffix)),
+		handler.WithLineProtocol(lineConverter),
+	}
+
+	var recordingEngine *recording.Engine
+	if cfg.RecordingRules != "" {
+		zl.Info("load recording rules")
+		rules, err := recording.LoadRules(cfg.RecordingRules)
+		if err != nil {
+			return fmt.Errorf("load recording rules: %w", err)
+		}
+		recordingEngine = recording.NewEngine(&recording.EngineOptions{
+			Rules:    rules,
+			Source:   metricService,
+			Sink:     metricService,
+			Interval: cfg.RecordInterval,
+			Logger:   zl,
+		})
+	}
+
+	var alertEngine *alerting.Engine
+	if cfg.AlertRules != "" {
+		zl.Info("load alert rules")
+		rules, err := alerting.LoadRules(cfg.AlertRules)
+		if err != nil {
+			return fmt.Errorf("load alert rules: %w", err)
+		}
+		inhibit, err := alerting.LoadInhibitRules(cfg.AlertRules)
+		if err != nil {
+			return fmt.Errorf("load inhibit rules: %w", err)
+		}
+		// активность агентов отслеживается по событиям аудита записи метрик
+		agents := alerting.NewAgentTracker()
+		auditor.Register(agents)
+
+		zl.Info("load alert silences")
+		silenceStorage, err := newSilenceStorage(cfg)
+		if err != nil {
+			return fmt.Errorf("init silence storage: %w", err)
+		}
+		silences, err := silence.NewManager(ctx, silenceStorage)
+		if err != nil {
+			return fmt.Errorf("load silences: %w", err)
+		}
+
+		alertEngine = alerting.NewEngine(&alerting.EngineOptions{
+			Rules:             rules,
+			Source:            metricService,
+			Agents:            agents,
+			Inhibit:           inhibit,
+			Silencer:          silences,
+			Interval:          cfg.AlertInterval,
+			ResolvedRetention: cfg.AlertRetention,
+			StateFile:         cfg.AlertState,
+			Logger:            zl,
+		})
+		if err := alertEngine.RestoreState(); err != nil {
+			return fmt.Errorf("restore alert state: %w", err)
+		}
+		handlerOpts = append(handlerOpts, handler.WithAlerts(alertEngine), handler.WithBaselines(alertEngine), handler.WithSilences(silences))
+	}
+
+	var dispatcher *notify.Dispatcher
+	if cfg.NotifyConfig != "" {
+		if alertEngine == nil {
+			return fmt.Errorf("notify config requires alert rules")
+		}
+
+		zl.Info("init alert notifications")
+		notifyCfg, err := notify.LoadConfig(cfg.NotifyConfig)
+		if err != nil {
+			return fmt.Errorf("load notify config: %w", err)
+		}
+		dispatcher, err = notify.NewFromConfig(notifyCfg, alertEngine, zl)
+		if err != nil {
+			return fmt.Errorf("init notify dispatcher: %w", err)
+		}
+		defer dispatcher.Close()
+	}
+
 	zl.Info("init handler")
-	metricHandler := handler.NewMetricsHandler(zl, metricService, auditor)
+	metricHandler := handler.NewMetricsHandler(zl, metricService, auditor, handlerOpts...)
 
 	zl.Info("init private key")
 	privateKey, err := crypto.PrivateKey(cfg.PrivateKeyFile)
@@ -119,44 +295,87 @@ func run() error {
 		return fmt.Errorf("init private key: %w", err)
 	}
 
+	zl.Info("init signature verifier")
+	verifier, err := newVerifier(cfg)
+	if err != nil {
+		return fmt.Errorf("init signature verifier: %w", err)
+	}
+
+	zl.Info("init token registry")
+	tokens, err := newTokenRegistry(cfg)
+	if err != nil {
+		return fmt.Errorf("init token registry: %w", err)
+	}
+
 	zl.Info("configure router options")
 	ro := router.RouterOptions{
-		Signature: cfg.Signature,
-		Key:       privateKey,
-		Logger:    zl,
-		Handler:   metricHandler,
+		Verifier: verifier,
+		Key:      privateKey,
+		Logger:   zl,
+		Handler:  metricHandler,
+		Tokens:   tokens,
 	}
-
-	zl.Info("parse subnet")
-	if cfg.TrustedSubnet != "" {
-		subnet, err := netutil.CIDR(cfg.TrustedSubnet)
+	if cfg.SignLegacy != "" {
+		until, err := time.Parse(time.RFC3339, cfg.SignLegacy)
 		if err != nil {
-			return fmt.Errorf("get subnet: %w", err)
+			return fmt.Errorf("parse signature legacy until: %w", err)
 		}
-		ro.Subnet = subnet
+		zl.Warn("legacy HashSHA256 signatures and unsigned writes are accepted", zap.Time("until", until))
+		ro.LegacyKey = sign.Signature(cfg.Signature)
+		ro.LegacyUntil = until
+	}
+
+	zl.Info("parse subnets")
+	subnets, err := netutil.ParseCIDRs(cfg.TrustedSubnet)
+	if err != nil {
+		return fmt.Errorf("parse trusted subnets: %w", err)
+	}
+	proxies, err := netutil.ParseCIDRs(cfg.TrustedProxies...)
+	if err != nil {
+		return fmt.Errorf("parse trusted proxies: %w", err)
+	}
+	ro.Subnets = subnets
+	ro.Proxies = proxies
+	ro.StrictIP = cfg.StrictIP
+
+	zl.Info("init tls config")
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("init tls config: %w", err)
+	}
+	ro.CertIdentity = cfg.CertIdentity
+	ro.UnsignedIngest = cfg.UnsignedIngest
+	if cfg.UnsignedIngest && tokens == nil && len(subnets) == 0 {
+		zl.Warn("unsigned ingest requires tokens or trusted subnets, signatures are still verified")
 	}
 
 	zl.Info("init router")
 	router := router.New(ro)
 
-	group := errgroup.Group{}
+	group, gctx := errgroup.WithContext(ctx)
 
 	pprofSrv := http.Server{Addr: cfg.PprofAddr}
 	group.Go(func() error {
 		zl.Info("starting pprof on /debug/pprof/", zap.String("server-address", cfg.PprofAddr))
-		if err := pprofSrv.ListenAndServe(); err != nil {
+		if err := pprofSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			return fmt.Errorf("starting pprof server: %w", err)
 		}
 		return nil
 	})
 
 	srv := http.Server{
-		Addr:    cfg.Addr,
-		Handler: router,
+		Addr:      cfg.Addr,
+		Handler:   router,
+		TLSConfig: tlsConfig,
 	}
 	group.Go(func() error {
-		zl.Info("start serving", zap.String("addr", cfg.Addr))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		zl.Info("start serving", zap.String("addr", cfg.Addr), zap.Bool("tls", tlsConfig != nil))
+		serve := srv.ListenAndServe
+		if tlsConfig != nil {
+			// сертификаты уже загружены в TLSConfig
+			serve = func() error { return srv.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			return fmt.Errorf("can't start server: %v", err)
 		}
 		return nil
@@ -168,18 +387,27 @@ func run() error {
 		return fmt.Errorf("init grpc listener: %w", err)
 	}
 	zl.Info("init server interceptors")
-	intrcs, err := getInterceptors(cfg.TrustedSubnet, cfg.Signature, zl)
-	if err != nil {
-		return fmt.Errorf("init server interceptors: %w", err)
-	}
+	intrcs := getInterceptors(interceptorOptions{
+		subnets:      subnets,
+		proxies:      proxies,
+		strictIP:     cfg.StrictIP,
+		verifier:     verifier,
+		tokens:       tokens,
+		certIdentity: cfg.CertIdentity,
+	}, zl)
 
 	zl.Info("init grpc server")
-	grpcSrv := grpc.NewServer(grpc.ChainUnaryInterceptor(intrcs...))
+	grpcOpts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(intrcs...)}
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcSrv := grpc.NewServer(grpcOpts...)
 
 	zl.Info("register grpc server")
 	proto.RegisterMetricsServer(grpcSrv, grpcserver.New(&grpcserver.MetricServerOptions{
 		Service:   metricService,
-		Signature: cfg.Signature,
+		Publisher: auditor,
+		Key:       privateKey,
 	}))
 
 	group.Go(func() error {
@@ -190,19 +418,121 @@ func run() error {
 		return nil
 	})
 
-	if err := group.Wait(); err != nil {
-		if err := pprofSrv.Shutdown(ctx); err != nil {
+	if cfg.StatsdAddr != "" {
+		zl.Info("init statsd listener")
+		conn, err := net.ListenPacket("udp", cfg.StatsdAddr)
+		if err != nil {
+			return fmt.Errorf("init statsd listener: %w", err)
+		}
+
+		statsdSrv := statsd.New(&statsd.ServerOptions{
+			FlushInterval: cfg.StatsdFlush,
+			Storer:        metricService,
+			Logger:        zl,
+		})
+		group.Go(func() error {
+			zl.Info("start serving statsd", zap.String("addr", cfg.StatsdAddr))
+			if err := statsdSrv.Serve(gctx, conn); err != nil {
+				return fmt.Errorf("serve statsd: %w", err)
+			}
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		auditor.ReportDropped(gctx, auditReportInterval)
+		return nil
+	})
+
+	if recordingEngine != nil {
+		group.Go(func() error {
+			zl.Info("start evaluating recording rules", zap.Duration("interval", cfg.RecordInterval))
+			return recordingEngine.Run(gctx)
+		})
+	}
+	if alertEngine != nil {
+		group.Go(func() error {
+			zl.Info("start evaluating alert rules", zap.Duration("interval", cfg.AlertInterval))
+			return alertEngine.Run(gctx)
+		})
+	}
+	if dispatcher != nil {
+		group.Go(func() error {
+			zl.Info("start sending alert notifications")
+			return dispatcher.Run(gctx)
+		})
+	}
+
+	lineListeners := []struct {
+		name   string
+		addr   string
+		parser lineproto.Parser
+	}{
+		{name: "graphite", addr: cfg.GraphiteAddr, parser: lineproto.ParseGraphite},
+		{name: "influx", addr: cfg.InfluxAddr, parser: lineproto.ParseInflux},
+	}
+	for _, ll := range lineListeners {
+		if ll.addr == "" {
+			continue
+		}
+
+		zl.Info("init line protocol listener", zap.String("protocol", ll.name))
+		ln, err := net.Listen("tcp", ll.addr)
+		if err != nil {
+			return fmt.Errorf("init %s listener: %w", ll.name, err)
+		}
+
+		lineSrv := lineproto.NewServer(&lineproto.ServerOptions{
+			Parser:    ll.parser,
+			Storer:    metricService,
+			Logger:    zl,
+			Subnets:   subnets,
+			Converter: lineConverter,
+			Getter:    metricService,
+		})
+		group.Go(func() error {
+			zl.Info("start serving line protocol", zap.String("protocol", ll.name), zap.String("addr", ll.addr))
+			if err := lineSrv.Serve(gctx, ln); err != nil {
+				return fmt.Errorf("serve %s: %w", ll.name, err)
+			}
+			return nil
+		})
+	}
+
+	group.Go(func() error {
+		<-gctx.Done()
+
+		zl.Info("shutdown servers")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := pprofSrv.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("shutdown pprof server: %w", err)
 		}
 
-		if err := srv.Shutdown(ctx); err != nil {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
 			return fmt.Errorf("shutdown server: %w", err)
 		}
 
-		grpcSrv.Stop()
+		grpcSrv.GracefulStop()
+		return nil
+	})
+
+	serveErr := group.Wait()
+
+	zl.Info("drain audit queues")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := auditor.Shutdown(shutdownCtx); err != nil {
+		zl.Error("shutdown auditor", zap.Error(err), zap.Any("dropped", auditor.Dropped()))
+	}
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			zl.Error("close auditor", zap.Error(err))
+		}
 	}
 
-	return nil
+	return serveErr
 }
 
 func newStorage(ctx context.Context, cfg config.Server, logger *zap.Logger) (model.Storager, error) {
@@ -215,7 +545,7 @@ func newStorage(ctx context.Context, cfg config.Server, logger *zap.Logger) (mod
 		if err != nil {
 			return nil, fmt.Errorf("open db: %w", err)
 		}
-		storage = postgres.New(db, cfg.MaxRetry)
+		storage = postgres.New(db, cfg.MaxRetry, postgres.WithBatchTTL(cfg.BatchTTL))
 
 		logger.Info("init provider")
 		provider, err := goose.NewProvider(database.DialectPostgres, db, migrations.Embed)
@@ -233,6 +563,10 @@ func newStorage(ctx context.Context, cfg config.Server, logger *zap.Logger) (mod
 			Interval: cfg.StoreInterval,
 			Logger:   logger,
 			MaxRetry: cfg.MaxRetry,
+
+			HistorySize:      cfg.HistorySize,
+			HistoryRetention: cfg.HistoryRetain,
+			BatchTTL:         cfg.BatchTTL,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("creating restore: %w", err)
@@ -242,6 +576,10 @@ func newStorage(ctx context.Context, cfg config.Server, logger *zap.Logger) (mod
 			FileName: cfg.StoreFilePath,
 			Interval: cfg.StoreInterval,
 			Logger:   logger,
+
+			HistorySize:      cfg.HistorySize,
+			HistoryRetention: cfg.HistoryRetain,
+			BatchTTL:         cfg.BatchTTL,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("creating default storage: %w", err)
@@ -251,28 +589,118 @@ func newStorage(ctx context.Context, cfg config.Server, logger *zap.Logger) (mod
 	return storage, nil
 }
 
+// newTLSConfig возвращает TLS конфигурацию HTTP и gRPC серверов
+// или nil, если сертификат сервера не задан.
+// При идентификации агентов по сертификату сертификат клиента обязателен,
+// иначе запросы без сертификата обходили бы идентификацию.
+func newTLSConfig(cfg config.Server) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" {
+		if cfg.CertIdentity {
+			return nil, errors.New("certificate identity requires tls")
+		}
+		return nil, nil
+	}
+	if cfg.CertIdentity && cfg.TLSCAFile == "" {
+		return nil, errors.New("certificate identity requires ca file")
+	}
+	return tlsutil.ServerConfig(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile, cfg.TLSClientAuth || cfg.CertIdentity)
+}
+
+// newVerifier возвращает верификатор подписей с активными ключами из конфигурации.
+// Ключ SIGNATURE добавляется под идентификатором sign.DefaultKeyID.
+// HTTP и gRPC серверы используют общий кэш nonce.
+func newVerifier(cfg config.Server) (*sign.Verifier, error) {
+	keys, err := sign.ParseKeyring(cfg.SignatureKeys)
+	if err != nil {
+		return nil, fmt.Errorf("parse signature keys: %w", err)
+	}
+	if cfg.Signature != "" {
+		keys[sign.DefaultKeyID] = sign.Signature(cfg.Signature)
+	}
+	return sign.NewVerifier(keys, cfg.SignWindow), nil
+}
+
+// newSilenceStorage возвращает хранилище заглушений оповещений
+// или nil, если заглушения хранятся только в памяти.
+func newSilenceStorage(cfg config.Server) (silence.Storage, error) {
+	switch {
+	case cfg.SilencesFile != "":
+		return silence.NewFileStorage(cfg.SilencesFile), nil
+	case cfg.SilencesDB:
+		if cfg.DatabaseDsn == "" {
+			return nil, errors.New("silences db requires database dsn")
+		}
+		db, err := sql.Open("pgx", cfg.DatabaseDsn)
+		if err != nil {
+			return nil, fmt.Errorf("open db: %w", err)
+		}
+		return silence.NewPostgresStorage(db), nil
+	default:
+		return nil, nil
+	}
+}
+
+// newTokenRegistry возвращает реестр API токенов агентов
+// или nil, если проверка токенов не настроена.
+func newTokenRegistry(cfg config.Server) (auth.Registry, error) {
+	switch {
+	case cfg.TokensFile != "":
+		reg, err := auth.NewFileRegistry(cfg.TokensFile)
+		if err != nil {
+			return nil, fmt.Errorf("load tokens file: %w", err)
+		}
+		return reg, nil
+	case cfg.TokensDB:
+		if cfg.DatabaseDsn == "" {
+			return nil, errors.New("tokens db requires database dsn")
+		}
+		db, err := sql.Open("pgx", cfg.DatabaseDsn)
+		if err != nil {
+			return nil, fmt.Errorf("open db: %w", err)
+		}
+		return auth.NewPostgresRegistry(db), nil
+	default:
+		return nil, nil
+	}
+}
+
 func registerSubscribers(p *audit.Auditor, subs ...audit.Observer) {
 	for _, sub := range subs {
 		p.Register(sub)
 	}
 }
 
-func getInterceptors(cidr, signature string, log *zap.Logger) ([]grpc.UnaryServerInterceptor, error) {
+// interceptorOptions параметры перехватчиков gRPC сервера.
+type interceptorOptions struct {
+	subnets      netutil.Subnets
+	proxies      netutil.Subnets
+	strictIP     bool
+	verifier     *sign.Verifier
+	tokens       auth.Registry
+	certIdentity bool
+}
+
+func getInterceptors(opts interceptorOptions, log *zap.Logger) []grpc.UnaryServerInterceptor {
 	var intrcs []grpc.UnaryServerInterceptor
 
-	intrcs = append(intrcs, interceptors.Logger(log))
+	intrcs = append(intrcs, interceptors.Logger(log), interceptors.RequestID(), interceptors.RealIP(opts.proxies))
 
-	if cidr != "" {
-		subnet, err := netutil.CIDR(cidr)
-		if err != nil {
-			return nil, fmt.Errorf("get subnet: %w", err)
-		}
+	if opts.certIdentity {
+		intrcs = append(intrcs, interceptors.CertIdentity())
+	}
+
+	if opts.tokens != nil {
+		intrcs = append(intrcs, interceptors.Auth(opts.tokens, map[string]auth.Scope{
+			proto.Metrics_UpdateMetrics_FullMethodName: auth.ScopeWrite,
+		}))
+	}
 
-		intrcs = append(intrcs, interceptors.Subnet(subnet))
+	if len(opts.subnets) > 0 {
+		intrcs = append(intrcs, interceptors.Subnet(opts.subnets, opts.strictIP))
 	}
 
-	if signature != "" {
-		intrcs = append(intrcs, interceptors.Signature(signature))
+	if opts.verifier.Enabled() {
+		intrcs = append(intrcs, interceptors.Signature(opts.verifier))
 	}
-	return intrcs, nil
+	return intrcs
 }
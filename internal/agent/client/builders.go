@@ -21,27 +21,29 @@ func (c *HTTPClient) buildManyBody(metrics model.MetricsSlice) ([]byte, error) {
 		return nil, fmt.Errorf("buildManyBody: can't marshal metrics: %w", err)
 	}
 
-	if c.opts.key != nil {
-		encrypted, err := crypto.Encrypt(c.opts.key, p)
-		if err != nil {
-			return nil, fmt.Errorf("buildManyBody: encrypt body: %w", err)
-		}
-		p = encrypted
-	}
-
 	_, err = gz.Write(p)
 	if err != nil {
 		return nil, fmt.Errorf("buildManyBody: can't write: %w", err)
 	}
 
 	gz.Close()
+
+	// шифруем уже сжатое тело: зашифрованные данные не сжимаются
+	if c.opts.key != nil {
+		encrypted, err := crypto.Encrypt(c.opts.key, buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("buildManyBody: encrypt body: %w", err)
+		}
+		return encrypted, nil
+	}
 	return buf.Bytes(), nil
 }
 
 func buildSingleRequest(metric model.MetricDto) model.Metrics {
 	m := model.Metrics{
-		ID:    metric.Name,
-		MType: metric.Value.Type.String(),
+		ID:     metric.Name,
+		MType:  metric.Value.Type.String(),
+		Labels: metric.Labels,
 	}
 
 	switch metric.Value.Type {
@@ -49,6 +51,8 @@ func buildSingleRequest(metric model.MetricDto) model.Metrics {
 		m.Value = &metric.Value.Gauge
 	case model.TypeCounter:
 		m.Delta = &metric.Value.Counter
+	case model.TypeHistogram:
+		m.Histogram = metric.Value.Histogram
 	}
 
 	return m
@@ -79,9 +83,9 @@ func buildSingleBody(m model.Metrics) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func buildSingleURL(addr string) string {
+func buildSingleURL(scheme, addr string) string {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: scheme,
 		Host:   addr,
 		Path:   "/update/",
 	}
@@ -89,11 +93,14 @@ func buildSingleURL(addr string) string {
 	return u.String()
 }
 
-func buildManyURL(addr string) string {
+// manyPath путь отправки батча метрик.
+const manyPath = "/updates/"
+
+func buildManyURL(scheme, addr string) string {
 	u := url.URL{
-		Scheme: "http",
+		Scheme: scheme,
 		Host:   addr,
-		Path:   "/updates/",
+		Path:   manyPath,
 	}
 
 	return u.String()
@@ -2,14 +2,18 @@ package client
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/htrandev/metrics/internal/agent"
 	"github.com/htrandev/metrics/internal/model"
 	pb "github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/pkg/crypto"
 	"github.com/htrandev/metrics/pkg/metadatautil"
-	"github.com/htrandev/metrics/pkg/sign"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -30,10 +34,43 @@ func NewGRPC(client pb.MetricsClient, opts ...Option) *GRPCClient {
 	return c
 }
 
+// Send отправляет пакет метрик на сервер,
+// если произошла ошибка, пытается повторно отправить указанное в MaxRetry количество раз.
+// Повторные попытки отправляют пакет с тем же идентификатором,
+// поэтому сервер не применит его дважды. Отклоненный сервером запрос не повторяется.
 func (c *GRPCClient) Send(ctx context.Context, metrics []model.MetricDto) error {
-	req := buildGRPCRequest(metrics)
+	req, err := c.buildRequest(metrics)
+	if err != nil {
+		return fmt.Errorf("grpc client: build request: %w", err)
+	}
+
+	batchID := uuid.NewString()
+	err = c.send(ctx, batchID, req)
+	if err == nil {
+		return nil
+	}
+
+	c.opts.logger.Error("send metrics", zap.Error(err), zap.String("scope", "agent/grpcSend"))
+	for i := 0; i < c.opts.maxRetry && retryable(err); i++ {
+		c.opts.logger.Debug("try to resend metrics", zap.Int("retry", i+1), zap.String("scope", "agent/grpcSend"))
+		retryDelay := i*2 + 1
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("grpc client: %w", ctx.Err())
+		case <-time.After(time.Second * time.Duration(retryDelay)):
+		}
 
-	ctx, err := c.setMetadata(ctx, req)
+		if err = c.send(ctx, batchID, req); err == nil {
+			return nil
+		}
+		c.opts.logger.Error("send metrics", zap.Int("retry", i+1), zap.Error(err), zap.String("scope", "agent/grpcSend"))
+	}
+	return err
+}
+
+// send выполняет одну попытку отправки пакета с идентификатором batchID.
+func (c *GRPCClient) send(ctx context.Context, batchID string, req *pb.UpdateMetricsRequest) error {
+	ctx, err := c.setMetadata(ctx, batchID, req)
 	if err != nil {
 		return fmt.Errorf("grpc client: set metadata: %w", err)
 	}
@@ -44,21 +81,59 @@ func (c *GRPCClient) Send(ctx context.Context, metrics []model.MetricDto) error
 	return nil
 }
 
-func (c *GRPCClient) setMetadata(ctx context.Context, req *pb.UpdateMetricsRequest) (context.Context, error) {
+// retryable сообщает, имеет ли смысл повторить запрос, завершившийся ошибкой err.
+// Запросы, отклоненные сервером как некорректные или неавторизованные, не повторяются.
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied, codes.Unimplemented:
+		return false
+	}
+	return true
+}
+
+func (c *GRPCClient) setMetadata(ctx context.Context, batchID string, req *pb.UpdateMetricsRequest) (context.Context, error) {
 	ctx = metadatautil.SetRealIP(ctx, c.opts.ip)
-	if c.opts.signature == "" {
-		b, err := proto.Marshal(req)
+	ctx = metadatautil.SetBatchID(ctx, batchID)
+	if c.opts.token != "" {
+		ctx = metadatautil.SetBearerToken(ctx, c.opts.token)
+	}
+	if c.opts.signature != "" {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
 		if err != nil {
 			return ctx, fmt.Errorf("unable to marshal req: %w", err)
 		}
-		si := sign.Signature(c.opts.signature)
-		signature := si.Sign(b)
-		hash := base64.RawURLEncoding.EncodeToString(signature)
-		ctx = metadatautil.SetHash256(ctx, hash)
+		st, err := c.opts.stamp(pb.Metrics_UpdateMetrics_FullMethodName, b)
+		if err != nil {
+			return ctx, fmt.Errorf("sign req: %w", err)
+		}
+		ctx = metadatautil.SetStamp(ctx, st)
 	}
 	return ctx, nil
 }
 
+// buildRequest собирает запрос с метриками.
+// Если задан публичный ключ, метрики передаются только в зашифрованном виде.
+func (c *GRPCClient) buildRequest(metrics []model.MetricDto) (*pb.UpdateMetricsRequest, error) {
+	req := buildGRPCRequest(metrics)
+	if c.opts.key == nil {
+		return req, nil
+	}
+
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal metrics: %w", err)
+	}
+	encrypted, err := crypto.Encrypt(c.opts.key, b)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt metrics: %w", err)
+	}
+
+	builder := pb.UpdateMetricsRequest_builder{
+		Encrypted: encrypted,
+	}
+	return builder.Build(), nil
+}
+
 func buildGRPCRequest(metrics []model.MetricDto) *pb.UpdateMetricsRequest {
 	pbMetrics := make([]*pb.Metric, 0, len(metrics))
 	for _, metric := range metrics {
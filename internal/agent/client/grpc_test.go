@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/htrandev/metrics/internal/model"
+	pb "github.com/htrandev/metrics/internal/proto"
+)
+
+// mockMetricsClient возвращает ошибки errs по очереди и запоминает идентификаторы пакетов.
+type mockMetricsClient struct {
+	errs     []error
+	batchIDs []string
+}
+
+func (m *mockMetricsClient) UpdateMetrics(ctx context.Context, _ *pb.UpdateMetricsRequest, _ ...grpc.CallOption) (*pb.UpdateMetricsResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	m.batchIDs = append(m.batchIDs, md.Get("batch_id")...)
+
+	var err error
+	if len(m.errs) > 0 {
+		err, m.errs = m.errs[0], m.errs[1:]
+	}
+	return &pb.UpdateMetricsResponse{}, err
+}
+
+func TestGRPCSendRetry(t *testing.T) {
+	testCases := []struct {
+		name          string
+		errs          []error
+		wantErr       bool
+		expectedCalls int
+	}{
+		{
+			name:          "retry with same batch id",
+			errs:          []error{status.Error(codes.Unavailable, "unavailable")},
+			expectedCalls: 2,
+		},
+		{
+			name:          "invalid argument is not retried",
+			errs:          []error{status.Error(codes.InvalidArgument, "invalid")},
+			wantErr:       true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockMetricsClient{errs: tc.errs}
+			c := NewGRPC(mock, WithMaxRetry(1), WithLogger(zap.NewNop()))
+
+			err := c.Send(context.Background(), []model.MetricDto{model.Counter("counter", 1)})
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, mock.batchIDs, tc.expectedCalls)
+			for _, id := range mock.batchIDs {
+				require.NotEmpty(t, id)
+				require.Equal(t, mock.batchIDs[0], id)
+			}
+		})
+	}
+}
@@ -2,17 +2,17 @@ package client
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
 	"github.com/htrandev/metrics/internal/agent"
 	"github.com/htrandev/metrics/internal/handler/middleware"
 	"github.com/htrandev/metrics/internal/model"
-	"github.com/htrandev/metrics/pkg/sign"
 	"go.uber.org/zap"
 )
 
@@ -30,6 +30,12 @@ func NewHTTP(client *resty.Client, opts ...Option) *HTTPClient {
 	for _, opt := range opts {
 		opt(&c.opts)
 	}
+	if c.opts.tls != nil {
+		c.client.SetTLSClientConfig(c.opts.tls)
+	}
+	if c.opts.token != "" {
+		c.client.SetAuthToken(c.opts.token)
+	}
 	return c
 }
 
@@ -44,7 +50,7 @@ func (c *HTTPClient) SendSingleMetric(ctx context.Context, metric model.MetricDt
 	if err != nil {
 		return fmt.Errorf("build body: %w", err)
 	}
-	url := buildSingleURL(c.opts.addr)
+	url := buildSingleURL(c.opts.scheme(), c.opts.addr)
 
 	_, err = c.client.R().
 		SetHeader("Content-Type", "application/json").
@@ -62,7 +68,14 @@ func (c *HTTPClient) SendSingleMetric(ctx context.Context, metric model.MetricDt
 }
 
 // SendManyMetrics отправляет за раз несколько метрик на сервер.
+// Каждый вызов отправляет пакет с новым идентификатором.
 func (c *HTTPClient) SendManyMetrics(ctx context.Context, metrics []model.MetricDto) error {
+	return c.sendMany(ctx, uuid.NewString(), metrics)
+}
+
+// sendMany отправляет пакет метрик с идентификатором batchID,
+// по которому сервер отбрасывает повторно доставленные пакеты.
+func (c *HTTPClient) sendMany(ctx context.Context, batchID string, metrics []model.MetricDto) error {
 	if len(metrics) == 0 {
 		return nil
 	}
@@ -73,20 +86,22 @@ func (c *HTTPClient) SendManyMetrics(ctx context.Context, metrics []model.Metric
 		return fmt.Errorf("build body: %w", err)
 	}
 
-	url := buildManyURL(c.opts.addr)
+	url := buildManyURL(c.opts.scheme(), c.opts.addr)
 
 	r := c.client.R().
 		SetHeader(middleware.IPHeader, c.opts.ip).
+		SetHeader(middleware.BatchIDHeader, batchID).
 		SetHeader("Content-Type", "application/json").
 		SetHeader("Content-Encoding", "gzip").
 		SetBody(body).
 		SetContext(ctx)
 
 	if c.opts.signature != "" {
-		s := sign.Signature(c.opts.signature)
-		signature := s.Sign(body)
-		hash := base64.RawURLEncoding.EncodeToString(signature)
-		r.SetHeader("HashSHA256", hash)
+		st, err := c.opts.stamp(middleware.SignTarget(http.MethodPost, manyPath), body)
+		if err != nil {
+			return fmt.Errorf("sign body: %w", err)
+		}
+		st.SetHeader(r.Header)
 	}
 
 	_, err = r.Post(url)
@@ -101,8 +116,11 @@ func (c *HTTPClient) SendManyMetrics(ctx context.Context, metrics []model.Metric
 
 // SendManyWithRetry отправляет за раз несколько метрик на сервер,
 // если произошла ошибка, пытается повторно отправить указанное в MaxRetry количество раз.
+// Повторные попытки отправляют пакет с тем же идентификатором,
+// поэтому сервер не применит его дважды.
 func (c *HTTPClient) SendManyWithRetry(ctx context.Context, metrics []model.MetricDto) error {
-	err := c.SendManyMetrics(ctx, metrics)
+	batchID := uuid.NewString()
+	err := c.sendMany(ctx, batchID, metrics)
 
 	if err != nil {
 		c.opts.logger.Error("send many metrics", zap.Error(err), zap.String("scope", "agent/sendWithRetry"))
@@ -112,7 +130,7 @@ func (c *HTTPClient) SendManyWithRetry(ctx context.Context, metrics []model.Metr
 			c.opts.logger.Debug("", zap.Int("retry", i+1))
 			retryDelay := i*2 + 1
 			time.Sleep(time.Second * time.Duration(retryDelay))
-			err := c.SendManyMetrics(ctx, metrics)
+			err := c.sendMany(ctx, batchID, metrics)
 			if err != nil {
 				c.opts.logger.Error("send many metrics", zap.Int("retry", i+1), zap.Error(err), zap.String("scope", "agent/sendWithRetry"))
 				continue
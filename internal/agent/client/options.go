@@ -2,8 +2,11 @@ package client
 
 import (
 	"crypto/rsa"
+	"crypto/tls"
 
 	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/pkg/sign"
 )
 
 type Option func(*CommonOptions)
@@ -13,10 +16,21 @@ type CommonOptions struct {
 	addr      string
 	ip        string
 	signature string
+	keyID     string
+	token     string
 	key       *rsa.PublicKey
+	tls       *tls.Config
 	logger    *zap.Logger
 }
 
+// scheme возвращает схему URL сервера.
+func (o CommonOptions) scheme() string {
+	if o.tls != nil {
+		return "https"
+	}
+	return "http"
+}
+
 func WithMaxRetry(retry int) Option {
 	return func(opt *CommonOptions) {
 		opt.maxRetry = retry
@@ -52,3 +66,35 @@ func WithSignature(signature string) Option {
 		opt.signature = signature
 	}
 }
+
+// WithSignatureKeyID задает идентификатор ключа подписи.
+// По умолчанию используется sign.DefaultKeyID.
+func WithSignatureKeyID(id string) Option {
+	return func(opt *CommonOptions) {
+		opt.keyID = id
+	}
+}
+
+// stamp подписывает тело запроса к target.
+func (o CommonOptions) stamp(target string, body []byte) (sign.Stamp, error) {
+	keyID := o.keyID
+	if keyID == "" {
+		keyID = sign.DefaultKeyID
+	}
+	return sign.NewStamp(keyID, sign.Signature(o.signature), target, body)
+}
+
+// WithTLS задает TLS конфигурацию HTTP клиента: проверку сервера
+// и клиентский сертификат. Запросы отправляются по HTTPS.
+func WithTLS(cfg *tls.Config) Option {
+	return func(opt *CommonOptions) {
+		opt.tls = cfg
+	}
+}
+
+// WithToken задает API токен агента.
+func WithToken(token string) Option {
+	return func(opt *CommonOptions) {
+		opt.token = token
+	}
+}
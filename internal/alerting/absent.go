@@ -0,0 +1,89 @@
+package alerting
+
+import (
+	"sort"
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// LabelAgent метка оповещения с именем пропавшего агента.
+const LabelAgent = "agent"
+
+// absent условие отсутствия обновлений серий дольше window.
+// Значение оповещения - секунды с последнего обновления.
+type absent struct {
+	selector
+	window time.Duration
+}
+
+func (a *absent) eval(ec *evalContext) []sample {
+	var samples []sample
+	found := false
+	for _, m := range ec.metrics {
+		if !a.matches(m) {
+			continue
+		}
+		found = true
+
+		last := m.UpdatedAt
+		if last.IsZero() {
+			last = ec.since
+		}
+		if age := ec.now.Sub(last); age >= a.window {
+			samples = append(samples, sample{metric: m, value: age.Seconds()})
+		}
+	}
+
+	// ни одной серии: метрика не появлялась с запуска или удалена
+	if !found {
+		if age := ec.now.Sub(ec.since); age >= a.window {
+			m := model.MetricDto{Name: a.metric, Labels: a.equalLabels()}
+			samples = append(samples, sample{metric: m, value: age.Seconds()})
+		}
+	}
+	return samples
+}
+
+// equalLabels возвращает метки из матчеров на точное совпадение,
+// которыми описывается отсутствующая серия.
+func (a *absent) equalLabels() model.Labels {
+	labels := make(model.Labels)
+	for _, m := range a.matchers {
+		if m.Type == model.MatchEqual && m.Value != "" {
+			labels[m.Name] = m.Value
+		}
+	}
+	return labels
+}
+
+// agentAbsent условие отсутствия записей метрик агентом дольше window.
+// Значение оповещения - секунды с последней записи.
+type agentAbsent struct {
+	agents []string
+	window time.Duration
+}
+
+func (a *agentAbsent) eval(ec *evalContext) []sample {
+	names := a.agents
+	if len(names) == 0 {
+		names = make([]string, 0, len(ec.agents))
+		for name := range ec.agents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var samples []sample
+	for _, name := range names {
+		last, ok := ec.agents[name]
+		if !ok {
+			last = ec.since
+		}
+		if age := ec.now.Sub(last); age >= a.window {
+			m := model.MetricDto{Labels: model.Labels{LabelAgent: name}}
+			samples = append(samples, sample{metric: m, value: age.Seconds()})
+		}
+	}
+	return samples
+}
@@ -0,0 +1,67 @@
+package alerting
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/htrandev/metrics/internal/audit"
+)
+
+var _ audit.Observer = (*AgentTracker)(nil)
+
+// AgentTracker реализует audit.Observer и запоминает время последней
+// успешной записи метрик каждым агентом. Агент определяется идентичностью
+// из клиентского сертификата или API токена, а без нее - IP-адресом.
+// Состояние хранится в памяти и после перезапуска сервера
+// восстанавливается по мере записи метрик агентами.
+type AgentTracker struct {
+	mu       sync.RWMutex
+	lastSeen map[string]time.Time
+}
+
+// NewAgentTracker возвращает новый экземпляр AgentTracker.
+func NewAgentTracker() *AgentTracker {
+	return &AgentTracker{lastSeen: make(map[string]time.Time)}
+}
+
+// GetID возвращает идентификатор наблюдателя.
+func (t *AgentTracker) GetID() string {
+	return "alerting/agents"
+}
+
+// Update запоминает время события записи метрик агентом.
+// Неуспешные записи не учитываются.
+func (t *AgentTracker) Update(_ context.Context, info audit.AuditInfo) {
+	if info.Outcome == audit.OutcomeError {
+		return
+	}
+	agent := info.Agent
+	if agent == "" {
+		agent = info.IP
+	}
+	if agent == "" {
+		return
+	}
+
+	ts := time.Unix(info.Timestamp, 0)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if ts.After(t.lastSeen[agent]) {
+		t.lastSeen[agent] = ts
+	}
+}
+
+// LastSeen возвращает время последней записи метрик по агентам.
+func (t *AgentTracker) LastSeen() map[string]time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	seen := make(map[string]time.Time, len(t.lastSeen))
+	for agent, ts := range t.lastSeen {
+		seen[agent] = ts
+	}
+	return seen
+}
@@ -0,0 +1,84 @@
+package alerting
+
+import (
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// State состояние оповещения.
+type State string
+
+const (
+	// StatePending условие выполняется меньше времени for правила.
+	StatePending State = "pending"
+	// StateFiring условие выполняется дольше времени for правила.
+	StateFiring State = "firing"
+	// StateResolved условие сработавшего оповещения перестало выполняться.
+	StateResolved State = "resolved"
+)
+
+// Метки, добавляемые к меткам серии оповещения.
+const (
+	LabelAlertName = "alertname"
+	LabelSeverity  = "severity"
+)
+
+// Alert оповещение по одной серии правила.
+//
+//easyjson:json
+type Alert struct {
+	Rule     string       `json:"rule"`
+	Metric   string       `json:"metric"`
+	Severity string       `json:"severity,omitempty"`
+	Labels   model.Labels `json:"labels"`
+	State    State        `json:"state"`
+	// Value последнее значение серии, для которого выполнялось условие.
+	Value float64 `json:"value"`
+
+	ActiveAt   time.Time  `json:"active_at"`
+	FiredAt    *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	// SilencedBy идентификаторы действующих заглушений оповещения.
+	SilencedBy []string `json:"silenced_by,omitempty"`
+	// InhibitedBy ключи сработавших оповещений, подавляющих оповещение.
+	InhibitedBy []string `json:"inhibited_by,omitempty"`
+}
+
+// Alerts список оповещений.
+//
+//easyjson:json
+type Alerts []Alert
+
+// Key возвращает идентификатор оповещения: правило и серия.
+func (a Alert) Key() string {
+	return alertKey(a.Rule, a.Metric, a.Labels)
+}
+
+// Muted сообщает, что оповещение заглушено или подавлено
+// и уведомления о нем не отправляются.
+func (a Alert) Muted() bool {
+	return len(a.SilencedBy) > 0 || len(a.InhibitedBy) > 0
+}
+
+func alertKey(rule, metric string, labels model.Labels) string {
+	return rule + "/" + metric + labels.String()
+}
+
+// alertLabels возвращает метки оповещения: метки серии, метки правила,
+// имя правила и важность. Метки правила перекрывают метки серии.
+func alertLabels(rule Rule, series model.Labels) model.Labels {
+	labels := make(model.Labels, len(series)+len(rule.Labels)+2)
+	for k, v := range series {
+		labels[k] = v
+	}
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+	labels[LabelAlertName] = rule.Name
+	if rule.Severity != "" {
+		labels[LabelSeverity] = rule.Severity
+	}
+	return labels
+}
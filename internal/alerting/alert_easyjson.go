@@ -0,0 +1,378 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	model "github.com/htrandev/metrics/internal/model"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson76a405cDecodeGithubComHtrandevMetricsInternalAlerting(in *jlexer.Lexer, out *Alerts) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Alerts, 0, 0)
+			} else {
+				*out = Alerts{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Alert
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson76a405cEncodeGithubComHtrandevMetricsInternalAlerting(out *jwriter.Writer, in Alerts) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Alerts) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson76a405cEncodeGithubComHtrandevMetricsInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Alerts) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson76a405cEncodeGithubComHtrandevMetricsInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Alerts) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson76a405cDecodeGithubComHtrandevMetricsInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Alerts) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson76a405cDecodeGithubComHtrandevMetricsInternalAlerting(l, v)
+}
+func easyjson76a405cDecodeGithubComHtrandevMetricsInternalAlerting1(in *jlexer.Lexer, out *Alert) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "rule":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Rule = string(in.String())
+			}
+		case "metric":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Metric = string(in.String())
+			}
+		case "severity":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Severity = string(in.String())
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Labels = make(model.Labels)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v4 = string(in.String())
+					}
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "state":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.State = State(in.String())
+			}
+		case "value":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Value = float64(in.Float64())
+			}
+		case "active_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.ActiveAt).UnmarshalJSON(data))
+				}
+			}
+		case "fired_at":
+			if in.IsNull() {
+				in.Skip()
+				out.FiredAt = nil
+			} else {
+				if out.FiredAt == nil {
+					out.FiredAt = new(time.Time)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					if data := in.Raw(); in.Ok() {
+						in.AddError((*out.FiredAt).UnmarshalJSON(data))
+					}
+				}
+			}
+		case "resolved_at":
+			if in.IsNull() {
+				in.Skip()
+				out.ResolvedAt = nil
+			} else {
+				if out.ResolvedAt == nil {
+					out.ResolvedAt = new(time.Time)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					if data := in.Raw(); in.Ok() {
+						in.AddError((*out.ResolvedAt).UnmarshalJSON(data))
+					}
+				}
+			}
+		case "silenced_by":
+			if in.IsNull() {
+				in.Skip()
+				out.SilencedBy = nil
+			} else {
+				in.Delim('[')
+				if out.SilencedBy == nil {
+					if !in.IsDelim(']') {
+						out.SilencedBy = make([]string, 0, 4)
+					} else {
+						out.SilencedBy = []string{}
+					}
+				} else {
+					out.SilencedBy = (out.SilencedBy)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v5 = string(in.String())
+					}
+					out.SilencedBy = append(out.SilencedBy, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "inhibited_by":
+			if in.IsNull() {
+				in.Skip()
+				out.InhibitedBy = nil
+			} else {
+				in.Delim('[')
+				if out.InhibitedBy == nil {
+					if !in.IsDelim(']') {
+						out.InhibitedBy = make([]string, 0, 4)
+					} else {
+						out.InhibitedBy = []string{}
+					}
+				} else {
+					out.InhibitedBy = (out.InhibitedBy)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v6 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v6 = string(in.String())
+					}
+					out.InhibitedBy = append(out.InhibitedBy, v6)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson76a405cEncodeGithubComHtrandevMetricsInternalAlerting1(out *jwriter.Writer, in Alert) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rule\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Rule))
+	}
+	{
+		const prefix string = ",\"metric\":"
+		out.RawString(prefix)
+		out.String(string(in.Metric))
+	}
+	if in.Severity != "" {
+		const prefix string = ",\"severity\":"
+		out.RawString(prefix)
+		out.String(string(in.Severity))
+	}
+	{
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		if in.Labels == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v7First := true
+			for v7Name, v7Value := range in.Labels {
+				if v7First {
+					v7First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v7Name))
+				out.RawByte(':')
+				out.String(string(v7Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	{
+		const prefix string = ",\"active_at\":"
+		out.RawString(prefix)
+		out.Raw((in.ActiveAt).MarshalJSON())
+	}
+	if in.FiredAt != nil {
+		const prefix string = ",\"fired_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.FiredAt).MarshalJSON())
+	}
+	if in.ResolvedAt != nil {
+		const prefix string = ",\"resolved_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.ResolvedAt).MarshalJSON())
+	}
+	if len(in.SilencedBy) != 0 {
+		const prefix string = ",\"silenced_by\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v8, v9 := range in.SilencedBy {
+				if v8 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v9))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.InhibitedBy) != 0 {
+		const prefix string = ",\"inhibited_by\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v10, v11 := range in.InhibitedBy {
+				if v10 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v11))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Alert) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson76a405cEncodeGithubComHtrandevMetricsInternalAlerting1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Alert) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson76a405cEncodeGithubComHtrandevMetricsInternalAlerting1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Alert) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson76a405cDecodeGithubComHtrandevMetricsInternalAlerting1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Alert) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson76a405cDecodeGithubComHtrandevMetricsInternalAlerting1(l, v)
+}
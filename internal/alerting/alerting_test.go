@@ -0,0 +1,712 @@
+package alerting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/audit"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+type mockSource struct {
+	metrics []model.MetricDto
+}
+
+func (m *mockSource) GetAll(_ context.Context, _ ...model.Matcher) ([]model.MetricDto, error) {
+	return m.metrics, nil
+}
+
+func writeRules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expectedLen int
+		wantErr     bool
+	}{
+		{
+			name: "valid",
+			content: `
+rules:
+  - name: HighCPU
+    metric: cpu
+    matchers: ['host=~"web-.*"']
+    op: ">"
+    threshold: 90
+    for: 5m
+    severity: critical
+    labels:
+      team: ops
+  - name: LowMemory
+    matchers: ['kind="memory"']
+    op: "<="
+    threshold: 100
+`,
+			expectedLen: 2,
+		},
+		{
+			name: "unknown operator",
+			content: `
+rules:
+  - name: HighCPU
+    metric: cpu
+    op: "=>"
+`,
+			wantErr: true,
+		},
+		{
+			name: "no selector",
+			content: `
+rules:
+  - name: HighCPU
+    op: ">"
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid matcher",
+			content: `
+rules:
+  - name: HighCPU
+    matchers: ['host']
+    op: ">"
+`,
+			wantErr: true,
+		},
+		{
+			name: "duplicate name",
+			content: `
+rules:
+  - {name: A, metric: cpu, op: ">"}
+  - {name: A, metric: mem, op: ">"}
+`,
+			wantErr: true,
+		},
+		{
+			name: "absent without window",
+			content: `
+rules:
+  - {name: A, type: absent, metric: cpu}
+`,
+			wantErr: true,
+		},
+		{
+			name: "deadman rules",
+			content: `
+rules:
+  - {name: CPUAbsent, type: absent, metric: cpu, window: 2m}
+  - {name: AgentDown, type: agent_absent, agents: [agent-1], window: 5m}
+`,
+			expectedLen: 2,
+		},
+		{
+			name: "anomaly rules",
+			content: `
+rules:
+  - {name: LoadAnomaly, type: ewma, metric_regex: 'CPUutilization\d+', sigma: 4, alpha: 0.2}
+  - {name: RPSAnomaly, type: seasonal, metric: rps, season: 24h, bucket: 1h}
+`,
+			expectedLen: 2,
+		},
+		{
+			name: "metric and metric_regex",
+			content: `
+rules:
+  - {name: A, metric: cpu, metric_regex: 'cpu.*', op: ">"}
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid alpha",
+			content: `
+rules:
+  - {name: A, type: ewma, metric: cpu, alpha: 1.5}
+`,
+			wantErr: true,
+		},
+		{
+			name: "seasonal without season",
+			content: `
+rules:
+  - {name: A, type: seasonal, metric: cpu}
+`,
+			wantErr: true,
+		},
+		{
+			name: "bucket does not divide season",
+			content: `
+rules:
+  - {name: A, type: seasonal, metric: cpu, season: 24h, bucket: 7h}
+`,
+			wantErr: true,
+		},
+		{
+			name: "inhibit rule without target",
+			content: `
+rules:
+  - {name: A, metric: cpu, op: ">"}
+inhibit_rules:
+  - source_matchers: ['severity="critical"']
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown type",
+			content: `
+rules:
+  - {name: A, type: magic, metric: cpu, op: ">"}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeRules(t, tc.content)
+			rules, err := LoadRules(path)
+			if err == nil {
+				_, err = LoadInhibitRules(path)
+			}
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rules, tc.expectedLen)
+		})
+	}
+
+	rules, err := LoadRules(writeRules(t, testCases[0].content))
+	require.NoError(t, err)
+	require.Equal(t, 5*time.Minute, rules[0].For)
+	require.Equal(t, model.Labels{"team": "ops"}, rules[0].Labels)
+}
+
+func TestEngineStates(t *testing.T) {
+	rule, err := NewRule(RuleConfig{
+		Name:      "HighCPU",
+		Metric:    "cpu",
+		Matchers:  []string{`host=~"web-.*"`},
+		Op:        ">",
+		Threshold: 90,
+		For:       time.Minute,
+		Severity:  "critical",
+	})
+	require.NoError(t, err)
+
+	cpu := func(host string, v float64) model.MetricDto {
+		m := model.Gauge("cpu", v)
+		m.Labels = model.Labels{"host": host}
+		return m
+	}
+
+	source := &mockSource{}
+	e := NewEngine(&EngineOptions{
+		Rules:             []Rule{rule},
+		Source:            source,
+		ResolvedRetention: 5 * time.Minute,
+	})
+
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		name     string
+		at       time.Duration
+		metrics  []model.MetricDto
+		expected []State
+	}{
+		{
+			name:     "below threshold",
+			at:       0,
+			metrics:  []model.MetricDto{cpu("web-1", 50), cpu("db-1", 99)},
+			expected: []State{},
+		},
+		{
+			name:     "pending",
+			at:       10 * time.Second,
+			metrics:  []model.MetricDto{cpu("web-1", 95), cpu("db-1", 99)},
+			expected: []State{StatePending},
+		},
+		{
+			name:     "still pending",
+			at:       30 * time.Second,
+			metrics:  []model.MetricDto{cpu("web-1", 96)},
+			expected: []State{StatePending},
+		},
+		{
+			name:     "firing",
+			at:       70 * time.Second,
+			metrics:  []model.MetricDto{cpu("web-1", 97)},
+			expected: []State{StateFiring},
+		},
+		{
+			name:     "resolved",
+			at:       80 * time.Second,
+			metrics:  []model.MetricDto{cpu("web-1", 10)},
+			expected: []State{StateResolved},
+		},
+		{
+			name:     "resolved retention",
+			at:       80*time.Second + 5*time.Minute,
+			metrics:  []model.MetricDto{cpu("web-1", 10)},
+			expected: []State{},
+		},
+	}
+
+	for _, step := range steps {
+		source.metrics = step.metrics
+		require.NoError(t, e.Eval(context.Background(), start.Add(step.at)), step.name)
+
+		alerts := e.Alerts()
+		states := make([]State, 0, len(alerts))
+		for _, a := range alerts {
+			states = append(states, a.State)
+		}
+		require.Equal(t, step.expected, states, step.name)
+	}
+}
+
+func TestEnginePendingReset(t *testing.T) {
+	rule, err := NewRule(RuleConfig{Name: "High", Metric: "load", Op: ">=", Threshold: 1, For: time.Minute})
+	require.NoError(t, err)
+
+	source := &mockSource{}
+	e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: source})
+	start := time.Now()
+
+	source.metrics = []model.MetricDto{model.Counter("load", 2)}
+	require.NoError(t, e.Eval(context.Background(), start))
+
+	// условие перестало выполняться до истечения for: оповещение удаляется
+	source.metrics = []model.MetricDto{model.Counter("load", 0)}
+	require.NoError(t, e.Eval(context.Background(), start.Add(30*time.Second)))
+	require.Empty(t, e.Alerts())
+
+	source.metrics = []model.MetricDto{model.Counter("load", 3)}
+	require.NoError(t, e.Eval(context.Background(), start.Add(70*time.Second)))
+
+	alerts := e.Alerts()
+	require.Len(t, alerts, 1)
+	require.Equal(t, StatePending, alerts[0].State)
+	require.Equal(t, float64(3), alerts[0].Value)
+	require.Equal(t, model.Labels{LabelAlertName: "High"}, alerts[0].Labels)
+}
+
+type mockAgents map[string]time.Time
+
+func (m mockAgents) LastSeen() map[string]time.Time {
+	return m
+}
+
+func TestEngineAbsent(t *testing.T) {
+	rule, err := NewRule(RuleConfig{
+		Name:     "CPUAbsent",
+		Type:     TypeAbsent,
+		Metric:   "cpu",
+		Matchers: []string{`host="web-1"`},
+		Window:   2 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	cpu := func(updatedAt time.Time) model.MetricDto {
+		m := model.Gauge("cpu", 1)
+		m.Labels = model.Labels{"host": "web-1"}
+		m.UpdatedAt = updatedAt
+		return m
+	}
+
+	source := &mockSource{}
+	e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: source})
+
+	// серии нет с первого вычисления, окно еще не истекло
+	require.NoError(t, e.Eval(context.Background(), start))
+	require.Empty(t, e.Alerts())
+
+	require.NoError(t, e.Eval(context.Background(), start.Add(2*time.Minute)))
+	alerts := e.Alerts()
+	require.Len(t, alerts, 1)
+	require.Equal(t, StateFiring, alerts[0].State)
+	require.Equal(t, "cpu", alerts[0].Metric)
+	require.Equal(t, "web-1", alerts[0].Labels["host"])
+	require.Equal(t, float64(120), alerts[0].Value)
+
+	// серия появилась и обновляется
+	source.metrics = []model.MetricDto{cpu(start.Add(3 * time.Minute))}
+	require.NoError(t, e.Eval(context.Background(), start.Add(3*time.Minute)))
+	alerts = e.Alerts()
+	require.Len(t, alerts, 1)
+	require.Equal(t, StateResolved, alerts[0].State)
+
+	// серия перестала обновляться
+	require.NoError(t, e.Eval(context.Background(), start.Add(6*time.Minute)))
+	alerts = e.Alerts()
+	require.Len(t, alerts, 1)
+	require.Equal(t, StateFiring, alerts[0].State)
+	require.Equal(t, float64(180), alerts[0].Value)
+}
+
+func TestEngineAgentAbsent(t *testing.T) {
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		agents   []string
+		seen     mockAgents
+		expected []string
+	}{
+		{
+			name:     "silent seen agent",
+			seen:     mockAgents{"agent-1": start.Add(-10 * time.Minute), "agent-2": start},
+			expected: []string{"agent-1"},
+		},
+		{
+			name:     "expected agent never seen",
+			agents:   []string{"agent-1", "agent-3"},
+			seen:     mockAgents{"agent-1": start, "agent-2": start.Add(-time.Hour)},
+			expected: []string{"agent-3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := NewRule(RuleConfig{
+				Name:   "AgentDown",
+				Type:   TypeAgentAbsent,
+				Agents: tc.agents,
+				Window: 5 * time.Minute,
+			})
+			require.NoError(t, err)
+
+			e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: &mockSource{}, Agents: tc.seen})
+			require.NoError(t, e.Eval(context.Background(), start.Add(-5*time.Minute)))
+			require.NoError(t, e.Eval(context.Background(), start.Add(time.Minute)))
+
+			agents := make([]string, 0)
+			for _, a := range e.Alerts() {
+				require.Equal(t, StateFiring, a.State)
+				agents = append(agents, a.Labels[LabelAgent])
+			}
+			require.Equal(t, tc.expected, agents)
+		})
+	}
+}
+
+func TestAgentTracker(t *testing.T) {
+	tracker := NewAgentTracker()
+	ctx := context.Background()
+
+	tracker.Update(ctx, audit.AuditInfo{Timestamp: 100, Agent: "agent-1", IP: "10.0.0.1", Outcome: audit.OutcomeSuccess})
+	tracker.Update(ctx, audit.AuditInfo{Timestamp: 90, Agent: "agent-1", Outcome: audit.OutcomeSuccess})
+	tracker.Update(ctx, audit.AuditInfo{Timestamp: 110, IP: "10.0.0.2", Outcome: audit.OutcomeSuccess})
+	tracker.Update(ctx, audit.AuditInfo{Timestamp: 120, IP: "10.0.0.3", Outcome: audit.OutcomeError})
+
+	require.Equal(t, map[string]time.Time{
+		"agent-1":  time.Unix(100, 0),
+		"10.0.0.2": time.Unix(110, 0),
+	}, tracker.LastSeen())
+}
+
+func TestSelectorMetricRegex(t *testing.T) {
+	sel, err := newSelector("", `CPUutilization\d+`, nil)
+	require.NoError(t, err)
+
+	require.True(t, sel.matches(model.Gauge("CPUutilization1", 1)))
+	require.True(t, sel.matches(model.Gauge("CPUutilization12", 1)))
+	require.False(t, sel.matches(model.Gauge("CPUutilization", 1)))
+	require.False(t, sel.matches(model.Gauge("TotalCPUutilization1", 1)))
+}
+
+func TestEngineEWMA(t *testing.T) {
+	rule, err := NewRule(RuleConfig{
+		Name:   "LoadAnomaly",
+		Type:   TypeEWMA,
+		Metric: "load",
+		Alpha:  0.3,
+		Warmup: 10,
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	load := func(v float64, updatedAt time.Time) model.MetricDto {
+		m := model.Gauge("load", v)
+		m.UpdatedAt = updatedAt
+		return m
+	}
+
+	source := &mockSource{}
+	e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: source})
+
+	now := start
+	for i := 0; i < 20; i++ {
+		now = now.Add(time.Minute)
+		source.metrics = []model.MetricDto{load(10+float64(i%3), now)}
+		require.NoError(t, e.Eval(context.Background(), now))
+		require.Empty(t, e.Alerts(), "value %d", i)
+	}
+
+	baselines := e.Baselines()
+	require.Len(t, baselines, 1)
+	require.Equal(t, uint64(19), baselines[0].Samples)
+	require.InDelta(t, 11, baselines[0].Mean, 1)
+	require.False(t, baselines[0].Anomalous)
+
+	// выброс
+	now = now.Add(time.Minute)
+	source.metrics = []model.MetricDto{load(50, now)}
+	require.NoError(t, e.Eval(context.Background(), now))
+	alerts := e.Alerts()
+	require.Len(t, alerts, 1)
+	require.Equal(t, StateFiring, alerts[0].State)
+	require.Equal(t, float64(50), alerts[0].Value)
+
+	baselines = e.Baselines()
+	require.True(t, baselines[0].Anomalous)
+	require.Greater(t, baselines[0].ZScore, float64(DefaultSigma))
+	require.Greater(t, baselines[0].Value, baselines[0].Upper)
+
+	// серия не обновлялась: модель не обучается, оповещение остается
+	require.NoError(t, e.Eval(context.Background(), now.Add(30*time.Second)))
+	require.Equal(t, StateFiring, e.Alerts()[0].State)
+	require.Equal(t, uint64(20), e.Baselines()[0].Samples)
+
+	now = now.Add(time.Minute)
+	source.metrics = []model.MetricDto{load(11, now)}
+	require.NoError(t, e.Eval(context.Background(), now))
+	require.Equal(t, StateResolved, e.Alerts()[0].State)
+	require.Equal(t, uint64(21), e.Baselines()[0].Samples)
+}
+
+func TestEngineAnomalyStale(t *testing.T) {
+	rule, err := NewRule(RuleConfig{
+		Name:   "LoadAnomaly",
+		Type:   TypeEWMA,
+		Metric: "load",
+		Warmup: 5,
+		Window: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	load := func(v float64, updatedAt time.Time) model.MetricDto {
+		m := model.Gauge("load", v)
+		m.UpdatedAt = updatedAt
+		return m
+	}
+
+	source := &mockSource{}
+	e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: source})
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Minute)
+		source.metrics = []model.MetricDto{load(10+float64(i%3), now)}
+		require.NoError(t, e.Eval(context.Background(), now))
+	}
+	now = now.Add(time.Minute)
+	source.metrics = []model.MetricDto{load(50, now)}
+	require.NoError(t, e.Eval(context.Background(), now))
+	require.Equal(t, StateFiring, e.Alerts()[0].State)
+
+	// значение устарело: оповещение разрешается
+	now = now.Add(6 * time.Minute)
+	require.NoError(t, e.Eval(context.Background(), now))
+	require.Equal(t, StateResolved, e.Alerts()[0].State)
+	require.False(t, e.Baselines()[0].Anomalous)
+
+	// серия пропала: модель удаляется
+	source.metrics = nil
+	require.NoError(t, e.Eval(context.Background(), now.Add(time.Hour)))
+	require.Len(t, e.Baselines(), 1)
+	require.NoError(t, e.Eval(context.Background(), now.Add(seriesTTL+time.Minute)))
+	require.Empty(t, e.Baselines())
+}
+
+func TestEngineSeasonal(t *testing.T) {
+	rule, err := NewRule(RuleConfig{
+		Name:   "RPSAnomaly",
+		Type:   TypeSeasonal,
+		Metric: "rps",
+		Warmup: 5,
+		Season: 24 * time.Hour,
+		Bucket: time.Hour,
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	rps := func(v float64, updatedAt time.Time) model.MetricDto {
+		m := model.Gauge("rps", v)
+		m.UpdatedAt = updatedAt
+		return m
+	}
+
+	source := &mockSource{}
+	e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: source})
+
+	// днем в 12:00 нагрузка высокая, ночью в 03:00 низкая
+	for day := 0; day < 10; day++ {
+		for _, sample := range []struct {
+			hour  time.Duration
+			value float64
+		}{
+			{hour: 3, value: 10 + float64(day%2)},
+			{hour: 12, value: 100 + float64(day%3)},
+		} {
+			ts := start.Add(time.Duration(day)*24*time.Hour + sample.hour*time.Hour)
+			source.metrics = []model.MetricDto{rps(sample.value, ts)}
+			require.NoError(t, e.Eval(context.Background(), ts))
+			require.Empty(t, e.Alerts(), "day %d hour %d", day, sample.hour)
+		}
+	}
+
+	// дневная нагрузка ночью
+	ts := start.Add(10*24*time.Hour + 3*time.Hour)
+	source.metrics = []model.MetricDto{rps(100, ts)}
+	require.NoError(t, e.Eval(context.Background(), ts))
+	require.Len(t, e.Alerts(), 1)
+
+	baselines := e.Baselines()
+	require.Len(t, baselines, 1)
+	require.Equal(t, 3, baselines[0].Bucket)
+	require.Equal(t, uint64(10), baselines[0].Samples)
+	require.InDelta(t, 10.5, baselines[0].Mean, 1)
+	require.True(t, baselines[0].Anomalous)
+}
+
+func TestEngineState(t *testing.T) {
+	cfg := RuleConfig{
+		Name:   "RPSAnomaly",
+		Type:   TypeSeasonal,
+		Metric: "rps",
+		Season: 24 * time.Hour,
+	}
+	path := filepath.Join(t.TempDir(), "state.json")
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	newEngine := func(t *testing.T, cfg RuleConfig) *Engine {
+		rule, err := NewRule(cfg)
+		require.NoError(t, err)
+		source := &mockSource{}
+		e := NewEngine(&EngineOptions{Rules: []Rule{rule}, Source: source, StateFile: path})
+		return e
+	}
+
+	e := newEngine(t, cfg)
+	// отсутствие файла не является ошибкой
+	require.NoError(t, e.RestoreState())
+
+	source := e.opts.Source.(*mockSource)
+	for i := 0; i < 5; i++ {
+		m := model.Gauge("rps", float64(i))
+		m.Labels = model.Labels{"host": "a"}
+		m.UpdatedAt = start.Add(time.Duration(i) * time.Minute)
+		source.metrics = []model.MetricDto{m}
+		require.NoError(t, e.Eval(context.Background(), m.UpdatedAt))
+	}
+	require.NoError(t, e.SaveState())
+	expected := e.Baselines()
+	require.Len(t, expected, 1)
+
+	restored := newEngine(t, cfg)
+	require.NoError(t, restored.RestoreState())
+	require.Equal(t, expected, restored.Baselines())
+
+	// модели с другим количеством интервалов отбрасываются
+	cfg.Bucket = 2 * time.Hour
+	changed := newEngine(t, cfg)
+	require.NoError(t, changed.RestoreState())
+	require.Empty(t, changed.Baselines())
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+	require.Error(t, newEngine(t, cfg).RestoreState())
+}
+
+type mockSilencer map[string]model.Labels
+
+func (m mockSilencer) Silenced(labels model.Labels, _ time.Time) []string {
+	var ids []string
+	for id, match := range m {
+		if model.MatchLabels(labels, matchersOf(match)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func matchersOf(labels model.Labels) []model.Matcher {
+	matchers := make([]model.Matcher, 0, len(labels))
+	for k, v := range labels {
+		matchers = append(matchers, model.Matcher{Type: model.MatchEqual, Name: k, Value: v})
+	}
+	return matchers
+}
+
+func TestEngineMute(t *testing.T) {
+	newRule := func(name, metric, severity string) Rule {
+		rule, err := NewRule(RuleConfig{Name: name, Metric: metric, Op: ">", Threshold: 0, Severity: severity})
+		require.NoError(t, err)
+		return rule
+	}
+	inhibit, err := NewInhibitRule(InhibitRuleConfig{
+		SourceMatchers: []string{`severity="critical"`},
+		TargetMatchers: []string{`severity="warning"`},
+		Equal:          []string{"host"},
+	})
+	require.NoError(t, err)
+
+	gauge := func(name, host string) model.MetricDto {
+		m := model.Gauge(name, 1)
+		m.Labels = model.Labels{"host": host}
+		return m
+	}
+
+	source := &mockSource{metrics: []model.MetricDto{
+		gauge("down", "web-1"),
+		gauge("load", "web-1"),
+		gauge("load", "web-2"),
+		gauge("disk", "web-2"),
+	}}
+	e := NewEngine(&EngineOptions{
+		Rules: []Rule{
+			newRule("HostDown", "down", "critical"),
+			newRule("HighLoad", "load", "warning"),
+			newRule("DiskFull", "disk", "critical"),
+		},
+		Source:   source,
+		Inhibit:  []InhibitRule{inhibit},
+		Silencer: mockSilencer{"maintenance": {LabelAlertName: "DiskFull"}},
+	})
+
+	require.NoError(t, e.Eval(context.Background(), time.Now()))
+
+	muted := make(map[string]Alert)
+	for _, a := range e.Alerts() {
+		muted[a.Rule+"/"+a.Labels["host"]] = a
+	}
+	require.Len(t, muted, 4)
+
+	require.False(t, muted["HostDown/web-1"].Muted())
+	require.Equal(t, []string{muted["HostDown/web-1"].Key()}, muted["HighLoad/web-1"].InhibitedBy)
+	// заглушенное оповещение продолжает подавлять предупреждения о своем хосте
+	require.Equal(t, []string{muted["DiskFull/web-2"].Key()}, muted["HighLoad/web-2"].InhibitedBy)
+	require.Equal(t, []string{"maintenance"}, muted["DiskFull/web-2"].SilencedBy)
+	require.Empty(t, muted["DiskFull/web-2"].InhibitedBy)
+
+	// подавление снимается, когда источник перестает срабатывать
+	source.metrics = source.metrics[1:3]
+	require.NoError(t, e.Eval(context.Background(), time.Now()))
+	for _, a := range e.Alerts() {
+		if a.Rule == "HighLoad" {
+			require.False(t, a.Muted(), a.Key())
+		}
+	}
+}
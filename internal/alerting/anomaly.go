@@ -0,0 +1,336 @@
+package alerting
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+const (
+	// DefaultSigma допустимое отклонение по умолчанию.
+	DefaultSigma = 3
+	// DefaultAlpha коэффициент сглаживания EWMA по умолчанию.
+	DefaultAlpha = 0.1
+	// DefaultWarmup количество значений для обучения модели по умолчанию.
+	DefaultWarmup = 30
+	// DefaultStale время, после которого значение серии устаревает, по умолчанию.
+	DefaultStale = 5 * time.Minute
+
+	// defaultSeasonBuckets количество интервалов периода по умолчанию.
+	defaultSeasonBuckets = 24
+	// maxSeasonBuckets ограничивает память модели одной серии.
+	maxSeasonBuckets = 10080
+	// seriesTTL время, через которое удаляется модель пропавшей серии.
+	// Для сезонных правил не меньше периода.
+	seriesTTL = 24 * time.Hour
+)
+
+// Baseline текущая оценка нормального значения серии правилом аномалий.
+//
+//easyjson:json
+type Baseline struct {
+	Rule   string       `json:"rule"`
+	Metric string       `json:"metric"`
+	Labels model.Labels `json:"labels,omitempty"`
+
+	// Value последнее значение серии.
+	Value float64 `json:"value"`
+	// Mean и StdDev среднее и стандартное отклонение модели до учета Value.
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	// Lower и Upper границы нормального значения: Mean ± Sigma*StdDev.
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	// ZScore отклонение Value от Mean в стандартных отклонениях.
+	ZScore float64 `json:"z_score"`
+	// Samples количество значений, по которым обучена модель.
+	Samples uint64 `json:"samples"`
+	// Bucket номер интервала периода для сезонных правил.
+	Bucket int `json:"bucket"`
+	// Anomalous сообщает, что Value вышло за границы обученной модели.
+	Anomalous bool `json:"anomalous"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Baselines список оценок.
+//
+//easyjson:json
+type Baselines []Baseline
+
+// ewma экспоненциально взвешенные среднее и дисперсия.
+//
+//easyjson:json
+type ewma struct {
+	Mean float64 `json:"mean"`
+	Var  float64 `json:"var"`
+	N    uint64  `json:"n"`
+}
+
+// update учитывает значение v с коэффициентом сглаживания alpha.
+// Первое значение становится средним.
+func (e *ewma) update(v, alpha float64) {
+	if e.N == 0 {
+		e.Mean = v
+		e.N = 1
+		return
+	}
+	diff := v - e.Mean
+	incr := alpha * diff
+	e.Mean += incr
+	e.Var = (1 - alpha) * (e.Var + diff*incr)
+	e.N++
+}
+
+// seriesModel модель одной серии.
+type seriesModel struct {
+	metric  model.MetricDto
+	buckets []ewma
+
+	// последнее учтенное значение и результат его проверки
+	updatedAt time.Time
+	baseline  Baseline
+
+	// seenAt время последнего вычисления, в котором серия присутствовала
+	seenAt time.Time
+}
+
+// anomaly условие отклонения значения серии от ее модели больше чем на sigma.
+// Модель обновляется только новыми значениями серии, то есть при изменении
+// времени ее обновления, поэтому частота вычисления правил не влияет на модель.
+// Устаревшие значения не считаются аномальными, модели пропавших серий удаляются.
+type anomaly struct {
+	selector
+	rule string
+
+	sigma  float64
+	alpha  float64
+	warmup uint64
+	season time.Duration
+	bucket time.Duration
+	stale  time.Duration
+	ttl    time.Duration
+
+	series map[string]*seriesModel
+}
+
+func newAnomaly(cfg RuleConfig, sel selector) (*anomaly, error) {
+	a := &anomaly{
+		selector: sel,
+		rule:     cfg.Name,
+		sigma:    cfg.Sigma,
+		alpha:    cfg.Alpha,
+		warmup:   DefaultWarmup,
+		stale:    cfg.Window,
+		ttl:      seriesTTL,
+		series:   make(map[string]*seriesModel),
+	}
+	if a.stale == 0 {
+		a.stale = DefaultStale
+	}
+	if a.sigma == 0 {
+		a.sigma = DefaultSigma
+	}
+	if a.alpha == 0 {
+		a.alpha = DefaultAlpha
+	}
+	if cfg.Warmup > 0 {
+		a.warmup = uint64(cfg.Warmup)
+	}
+
+	if a.sigma < 0 {
+		return nil, fmt.Errorf("sigma must be positive: %w", ErrInvalidRule)
+	}
+	if a.alpha < 0 || a.alpha > 1 {
+		return nil, fmt.Errorf("alpha must be in (0, 1]: %w", ErrInvalidRule)
+	}
+	if a.stale < 0 {
+		return nil, fmt.Errorf("window must be positive: %w", ErrInvalidRule)
+	}
+
+	if cfg.Type != TypeSeasonal {
+		return a, nil
+	}
+
+	if cfg.Season <= 0 {
+		return nil, fmt.Errorf("season must be positive: %w", ErrInvalidRule)
+	}
+	a.season = cfg.Season
+	a.bucket = cfg.Bucket
+	if a.bucket == 0 {
+		a.bucket = a.season / defaultSeasonBuckets
+	}
+	if a.bucket <= 0 || a.season%a.bucket != 0 {
+		return nil, fmt.Errorf("bucket must divide season: %w", ErrInvalidRule)
+	}
+	if a.season/a.bucket > maxSeasonBuckets {
+		return nil, fmt.Errorf("too many buckets in season, max %d: %w", maxSeasonBuckets, ErrInvalidRule)
+	}
+	a.ttl = max(a.ttl, a.season)
+	return a, nil
+}
+
+// buckets возвращает количество интервалов модели серии.
+func (a *anomaly) buckets() int {
+	if a.season == 0 {
+		return 1
+	}
+	return int(a.season / a.bucket)
+}
+
+// bucketAt возвращает номер интервала периода для момента ts.
+func (a *anomaly) bucketAt(ts time.Time) int {
+	if a.season == 0 {
+		return 0
+	}
+	offset := time.Duration(ts.UnixNano() % int64(a.season))
+	return int(offset / a.bucket)
+}
+
+func (a *anomaly) eval(ec *evalContext) []sample {
+	var samples []sample
+	for _, m := range ec.metrics {
+		if !a.matches(m) {
+			continue
+		}
+		v, ok := value(m)
+		if !ok {
+			continue
+		}
+
+		key := m.Key()
+		sm, ok := a.series[key]
+		if !ok {
+			sm = &seriesModel{
+				metric:  model.MetricDto{Name: m.Name, Labels: m.Labels.Clone()},
+				buckets: make([]ewma, a.buckets()),
+			}
+			a.series[key] = sm
+		}
+		sm.seenAt = ec.now
+
+		ts := m.UpdatedAt
+		if ts.IsZero() {
+			ts = ec.now
+		}
+		// значение не менялось с прошлого вычисления
+		if m.UpdatedAt.IsZero() || !m.UpdatedAt.Equal(sm.updatedAt) {
+			a.observe(sm, v, ts)
+		}
+		// устаревшее значение не характеризует текущее состояние серии
+		if ec.now.Sub(ts) > a.stale {
+			sm.baseline.Anomalous = false
+		}
+
+		if sm.baseline.Anomalous {
+			samples = append(samples, sample{metric: m, value: v})
+		}
+	}
+	a.prune(ec.now)
+	return samples
+}
+
+// prune удаляет модели серий, отсутствующих дольше ttl.
+func (a *anomaly) prune(now time.Time) {
+	for key, sm := range a.series {
+		// восстановленная модель, серия еще не вычислялась
+		if sm.seenAt.IsZero() {
+			sm.seenAt = now
+			continue
+		}
+		if now.Sub(sm.seenAt) > a.ttl {
+			delete(a.series, key)
+		}
+	}
+}
+
+// observe проверяет значение v по модели серии и обучает модель.
+func (a *anomaly) observe(sm *seriesModel, v float64, ts time.Time) {
+	b := a.bucketAt(ts)
+	st := &sm.buckets[b]
+
+	std := math.Sqrt(st.Var)
+	z := 0.0
+	if std > 0 {
+		z = (v - st.Mean) / std
+	}
+
+	sm.updatedAt = ts
+	sm.baseline = Baseline{
+		Rule:      a.rule,
+		Metric:    sm.metric.Name,
+		Labels:    sm.metric.Labels,
+		Value:     v,
+		Mean:      st.Mean,
+		StdDev:    std,
+		Lower:     st.Mean - a.sigma*std,
+		Upper:     st.Mean + a.sigma*std,
+		ZScore:    z,
+		Samples:   st.N,
+		Bucket:    b,
+		Anomalous: st.N >= a.warmup && std > 0 && math.Abs(z) >= a.sigma,
+		UpdatedAt: ts,
+	}
+
+	st.update(v, a.alpha)
+}
+
+// baselines возвращает оценки всех серий правила.
+func (a *anomaly) baselines() []Baseline {
+	result := make([]Baseline, 0, len(a.series))
+	for _, sm := range a.series {
+		if sm.updatedAt.IsZero() {
+			continue
+		}
+		b := sm.baseline
+		b.Labels = b.Labels.Clone()
+		result = append(result, b)
+	}
+	return result
+}
+
+// modelState сохраненная модель серии.
+//
+//easyjson:json
+type modelState struct {
+	Rule      string       `json:"rule"`
+	Metric    string       `json:"metric"`
+	Labels    model.Labels `json:"labels,omitempty"`
+	Buckets   []ewma       `json:"buckets"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Baseline  Baseline     `json:"baseline"`
+}
+
+// snapshot возвращает модели всех серий правила.
+func (a *anomaly) snapshot() []modelState {
+	states := make([]modelState, 0, len(a.series))
+	for _, sm := range a.series {
+		states = append(states, modelState{
+			Rule:      a.rule,
+			Metric:    sm.metric.Name,
+			Labels:    sm.metric.Labels.Clone(),
+			Buckets:   append([]ewma(nil), sm.buckets...),
+			UpdatedAt: sm.updatedAt,
+			Baseline:  sm.baseline,
+		})
+	}
+	return states
+}
+
+// restore восстанавливает модель серии. Модели, сохраненные
+// с другим количеством интервалов периода, отбрасываются.
+func (a *anomaly) restore(st modelState) bool {
+	if len(st.Buckets) != a.buckets() {
+		return false
+	}
+	m := model.MetricDto{Name: st.Metric, Labels: st.Labels.Clone()}
+	a.series[m.Key()] = &seriesModel{
+		metric:    m,
+		buckets:   append([]ewma(nil), st.Buckets...),
+		updatedAt: st.UpdatedAt,
+		baseline:  st.Baseline,
+	}
+	return true
+}
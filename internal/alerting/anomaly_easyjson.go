@@ -0,0 +1,594 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	model "github.com/htrandev/metrics/internal/model"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting(in *jlexer.Lexer, out *modelState) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "rule":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Rule = string(in.String())
+			}
+		case "metric":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Metric = string(in.String())
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(model.Labels)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v1 = string(in.String())
+					}
+					(out.Labels)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "buckets":
+			if in.IsNull() {
+				in.Skip()
+				out.Buckets = nil
+			} else {
+				in.Delim('[')
+				if out.Buckets == nil {
+					if !in.IsDelim(']') {
+						out.Buckets = make([]ewma, 0, 2)
+					} else {
+						out.Buckets = []ewma{}
+					}
+				} else {
+					out.Buckets = (out.Buckets)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 ewma
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v2).UnmarshalEasyJSON(in)
+					}
+					out.Buckets = append(out.Buckets, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "updated_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+				}
+			}
+		case "baseline":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(out.Baseline).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting(out *jwriter.Writer, in modelState) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rule\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Rule))
+	}
+	{
+		const prefix string = ",\"metric\":"
+		out.RawString(prefix)
+		out.String(string(in.Metric))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.Labels {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v3Name))
+				out.RawByte(':')
+				out.String(string(v3Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"buckets\":"
+		out.RawString(prefix)
+		if in.Buckets == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v4, v5 := range in.Buckets {
+				if v4 > 0 {
+					out.RawByte(',')
+				}
+				(v5).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"baseline\":"
+		out.RawString(prefix)
+		(in.Baseline).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v modelState) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v modelState) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *modelState) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *modelState) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting(l, v)
+}
+func easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting1(in *jlexer.Lexer, out *ewma) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "mean":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Mean = float64(in.Float64())
+			}
+		case "var":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Var = float64(in.Float64())
+			}
+		case "n":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.N = uint64(in.Uint64())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting1(out *jwriter.Writer, in ewma) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"mean\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.Mean))
+	}
+	{
+		const prefix string = ",\"var\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Var))
+	}
+	{
+		const prefix string = ",\"n\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.N))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ewma) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ewma) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ewma) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ewma) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting1(l, v)
+}
+func easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting2(in *jlexer.Lexer, out *Baselines) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Baselines, 0, 0)
+			} else {
+				*out = Baselines{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v6 Baseline
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v6).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v6)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting2(out *jwriter.Writer, in Baselines) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v7, v8 := range in {
+			if v7 > 0 {
+				out.RawByte(',')
+			}
+			(v8).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Baselines) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Baselines) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Baselines) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Baselines) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting2(l, v)
+}
+func easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting3(in *jlexer.Lexer, out *Baseline) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "rule":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Rule = string(in.String())
+			}
+		case "metric":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Metric = string(in.String())
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(model.Labels)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v9 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v9 = string(in.String())
+					}
+					(out.Labels)[key] = v9
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "value":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Value = float64(in.Float64())
+			}
+		case "mean":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Mean = float64(in.Float64())
+			}
+		case "stddev":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.StdDev = float64(in.Float64())
+			}
+		case "lower":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Lower = float64(in.Float64())
+			}
+		case "upper":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Upper = float64(in.Float64())
+			}
+		case "z_score":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.ZScore = float64(in.Float64())
+			}
+		case "samples":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Samples = uint64(in.Uint64())
+			}
+		case "bucket":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Bucket = int(in.Int())
+			}
+		case "anomalous":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Anomalous = bool(in.Bool())
+			}
+		case "updated_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting3(out *jwriter.Writer, in Baseline) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rule\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Rule))
+	}
+	{
+		const prefix string = ",\"metric\":"
+		out.RawString(prefix)
+		out.String(string(in.Metric))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v10First := true
+			for v10Name, v10Value := range in.Labels {
+				if v10First {
+					v10First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v10Name))
+				out.RawByte(':')
+				out.String(string(v10Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	{
+		const prefix string = ",\"mean\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Mean))
+	}
+	{
+		const prefix string = ",\"stddev\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.StdDev))
+	}
+	{
+		const prefix string = ",\"lower\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Lower))
+	}
+	{
+		const prefix string = ",\"upper\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Upper))
+	}
+	{
+		const prefix string = ",\"z_score\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.ZScore))
+	}
+	{
+		const prefix string = ",\"samples\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Samples))
+	}
+	{
+		const prefix string = ",\"bucket\":"
+		out.RawString(prefix)
+		out.Int(int(in.Bucket))
+	}
+	{
+		const prefix string = ",\"anomalous\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Anomalous))
+	}
+	{
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Baseline) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Baseline) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBabac89EncodeGithubComHtrandevMetricsInternalAlerting3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Baseline) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Baseline) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBabac89DecodeGithubComHtrandevMetricsInternalAlerting3(l, v)
+}
@@ -0,0 +1,243 @@
+// Package alerting реализует правила оповещений по значениям метрик.
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+const (
+	// DefaultInterval интервал вычисления правил по умолчанию.
+	DefaultInterval = 15 * time.Second
+	// DefaultResolvedRetention время, в течение которого разрешенное оповещение
+	// остается в списке, по умолчанию.
+	DefaultResolvedRetention = 15 * time.Minute
+)
+
+// Source возвращает текущие значения всех серий.
+type Source interface {
+	GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error)
+}
+
+// AgentSource возвращает время последней записи метрик по агентам.
+type AgentSource interface {
+	LastSeen() map[string]time.Time
+}
+
+// Silencer возвращает идентификаторы действующих заглушений оповещения.
+type Silencer interface {
+	Silenced(labels model.Labels, now time.Time) []string
+}
+
+// EngineOptions параметры движка правил.
+type EngineOptions struct {
+	Rules  []Rule
+	Source Source
+	// Agents источник активности агентов для правил TypeAgentAbsent.
+	// Если nil, такие правила срабатывают только для ожидаемых агентов.
+	Agents AgentSource
+	// Inhibit правила подавления оповещений.
+	Inhibit []InhibitRule
+	// Silencer источник заглушений. Если nil, оповещения не заглушаются.
+	Silencer Silencer
+
+	// Interval интервал вычисления правил. По умолчанию DefaultInterval.
+	Interval time.Duration
+	// ResolvedRetention время хранения разрешенных оповещений.
+	// По умолчанию DefaultResolvedRetention.
+	ResolvedRetention time.Duration
+	// StateFile файл, в котором сохраняются модели правил аномалий
+	// после каждого вычисления. Если пусто, модели не сохраняются.
+	StateFile string
+
+	Logger *zap.Logger
+}
+
+// Engine периодически вычисляет правила и отслеживает состояния оповещений:
+// pending, пока условие выполняется меньше времени for правила,
+// firing после этого и resolved, когда условие сработавшего оповещения
+// перестает выполняться. Оповещение в состоянии pending, условие которого
+// перестало выполняться, удаляется. Заглушенные и подавленные оповещения
+// отмечаются при каждом вычислении, см. Alert.Muted.
+type Engine struct {
+	opts *EngineOptions
+
+	mu     sync.RWMutex
+	alerts map[string]*Alert
+	// since время первого вычисления правил.
+	since time.Time
+}
+
+// NewEngine создает движок правил.
+func NewEngine(opts *EngineOptions) *Engine {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.ResolvedRetention <= 0 {
+		opts.ResolvedRetention = DefaultResolvedRetention
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	return &Engine{
+		opts:   opts,
+		alerts: make(map[string]*Alert),
+	}
+}
+
+// Run вычисляет правила с интервалом до отмены контекста.
+func (e *Engine) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Eval(ctx, time.Now()); err != nil {
+			e.opts.Logger.Error("eval rules", zap.Error(err), zap.String("scope", "alerting/Run"))
+		}
+		if err := e.SaveState(); err != nil {
+			e.opts.Logger.Error("save state", zap.Error(err), zap.String("scope", "alerting/Run"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Eval вычисляет все правила на момент now и обновляет состояния оповещений.
+func (e *Engine) Eval(ctx context.Context, now time.Time) error {
+	metrics, err := e.opts.Source.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("alerting/Eval: get all metrics: %w", err)
+	}
+
+	var agents map[string]time.Time
+	if e.opts.Agents != nil {
+		agents = e.opts.Agents.LastSeen()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.since.IsZero() {
+		e.since = now
+	}
+	ec := &evalContext{now: now, since: e.since, metrics: metrics, agents: agents}
+
+	seen := make(map[string]struct{}, len(e.alerts))
+	for _, rule := range e.opts.Rules {
+		for _, s := range rule.cond.eval(ec) {
+			key := e.activate(rule, s, now)
+			seen[key] = struct{}{}
+		}
+	}
+
+	for key, a := range e.alerts {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		e.deactivate(key, a, now)
+	}
+
+	e.mute(now)
+	return nil
+}
+
+// activate обновляет оповещение серии, для которой выполняется условие правила.
+func (e *Engine) activate(rule Rule, s sample, now time.Time) string {
+	labels := alertLabels(rule, s.metric.Labels)
+	key := alertKey(rule.Name, s.metric.Name, labels)
+
+	a, ok := e.alerts[key]
+	if !ok || a.State == StateResolved {
+		a = &Alert{
+			Rule:     rule.Name,
+			Metric:   s.metric.Name,
+			Severity: rule.Severity,
+			Labels:   labels,
+			State:    StatePending,
+			ActiveAt: now,
+		}
+		e.alerts[key] = a
+	}
+	a.Value = s.value
+
+	if a.State == StatePending && now.Sub(a.ActiveAt) >= rule.For {
+		firedAt := now
+		a.State = StateFiring
+		a.FiredAt = &firedAt
+		e.opts.Logger.Info("alert firing", zap.String("alert", key), zap.Float64("value", s.value), zap.String("scope", "alerting/Eval"))
+	}
+	return key
+}
+
+// deactivate обновляет оповещение, условие которого перестало выполняться.
+func (e *Engine) deactivate(key string, a *Alert, now time.Time) {
+	switch a.State {
+	case StatePending:
+		delete(e.alerts, key)
+	case StateFiring:
+		resolvedAt := now
+		a.State = StateResolved
+		a.ResolvedAt = &resolvedAt
+		e.opts.Logger.Info("alert resolved", zap.String("alert", key), zap.String("scope", "alerting/Eval"))
+	case StateResolved:
+		if now.Sub(*a.ResolvedAt) >= e.opts.ResolvedRetention {
+			delete(e.alerts, key)
+		}
+	}
+}
+
+// mute отмечает заглушенные и подавленные оповещения.
+// Подавлять другие оповещения могут только сработавшие оповещения.
+func (e *Engine) mute(now time.Time) {
+	for _, a := range e.alerts {
+		a.SilencedBy = nil
+		a.InhibitedBy = nil
+		if e.opts.Silencer != nil {
+			a.SilencedBy = e.opts.Silencer.Silenced(a.Labels, now)
+		}
+	}
+
+	if len(e.opts.Inhibit) == 0 {
+		return
+	}
+	for targetKey, target := range e.alerts {
+		for sourceKey, source := range e.alerts {
+			if sourceKey == targetKey || source.State != StateFiring {
+				continue
+			}
+			for _, rule := range e.opts.Inhibit {
+				if rule.inhibits(source, target) {
+					target.InhibitedBy = append(target.InhibitedBy, sourceKey)
+					break
+				}
+			}
+		}
+		sort.Strings(target.InhibitedBy)
+	}
+}
+
+// Alerts возвращает текущие оповещения, упорядоченные по правилу и серии.
+func (e *Engine) Alerts() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, a := range e.alerts {
+		alerts = append(alerts, *a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Key() < alerts[j].Key()
+	})
+	return alerts
+}
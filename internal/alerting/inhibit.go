@@ -0,0 +1,91 @@
+package alerting
+
+import (
+	"fmt"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// InhibitRuleConfig описание правила подавления в YAML файле.
+//
+// Пока срабатывает оповещение, удовлетворяющее SourceMatchers, оповещения,
+// удовлетворяющие TargetMatchers и совпадающие с ним по значениям меток Equal,
+// подавляются. Например, критическое оповещение о хосте подавляет
+// предупреждения о том же хосте:
+//
+//	inhibit_rules:
+//	  - source_matchers: ['severity="critical"']
+//	    target_matchers: ['severity=~"warning|info"']
+//	    equal: [host]
+type InhibitRuleConfig struct {
+	SourceMatchers []string `yaml:"source_matchers"`
+	TargetMatchers []string `yaml:"target_matchers"`
+	Equal          []string `yaml:"equal"`
+}
+
+// InhibitRule проверенное правило подавления.
+type InhibitRule struct {
+	source []model.Matcher
+	target []model.Matcher
+	equal  []string
+}
+
+// LoadInhibitRules загружает и проверяет правила подавления из YAML файла правил.
+func LoadInhibitRules(path string) ([]InhibitRule, error) {
+	f, err := loadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("alerting/LoadInhibitRules: %w", err)
+	}
+
+	rules := make([]InhibitRule, 0, len(f.InhibitRules))
+	for i, cfg := range f.InhibitRules {
+		rule, err := NewInhibitRule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("alerting/LoadInhibitRules: rule %d: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// NewInhibitRule проверяет описание правила подавления и возвращает правило.
+func NewInhibitRule(cfg InhibitRuleConfig) (InhibitRule, error) {
+	if len(cfg.SourceMatchers) == 0 || len(cfg.TargetMatchers) == 0 {
+		return InhibitRule{}, fmt.Errorf("source_matchers and target_matchers required: %w", ErrInvalidRule)
+	}
+
+	source, err := parseMatchers(cfg.SourceMatchers)
+	if err != nil {
+		return InhibitRule{}, fmt.Errorf("source_matchers: %w", err)
+	}
+	target, err := parseMatchers(cfg.TargetMatchers)
+	if err != nil {
+		return InhibitRule{}, fmt.Errorf("target_matchers: %w", err)
+	}
+	return InhibitRule{source: source, target: target, equal: cfg.Equal}, nil
+}
+
+func parseMatchers(exprs []string) ([]model.Matcher, error) {
+	matchers := make([]model.Matcher, 0, len(exprs))
+	for _, expr := range exprs {
+		m, err := model.ParseMatcher(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: matcher: %w", ErrInvalidRule, err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// inhibits сообщает, подавляет ли оповещение source оповещение target.
+func (r InhibitRule) inhibits(source, target *Alert) bool {
+	if !model.MatchLabels(target.Labels, r.target) || !model.MatchLabels(source.Labels, r.source) {
+		return false
+	}
+	for _, name := range r.equal {
+		if source.Labels[name] != target.Labels[name] {
+			return false
+		}
+	}
+	return true
+}
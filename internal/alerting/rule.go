@@ -0,0 +1,335 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ErrInvalidRule возвращается при некорректном описании правила.
+var ErrInvalidRule = errors.New("invalid rule")
+
+// Типы правил.
+const (
+	// TypeThreshold сравнивает значение серии с порогом.
+	TypeThreshold = "threshold"
+	// TypeAbsent срабатывает, когда серия не обновлялась дольше Window
+	// или ни одной подходящей серии нет.
+	TypeAbsent = "absent"
+	// TypeAgentAbsent срабатывает, когда агент не записывал метрики дольше Window.
+	TypeAgentAbsent = "agent_absent"
+	// TypeEWMA срабатывает, когда значение серии отклоняется от скользящего
+	// экспоненциального среднего больше чем на Sigma стандартных отклонений.
+	TypeEWMA = "ewma"
+	// TypeSeasonal аналогичен TypeEWMA, но хранит отдельное среднее
+	// для каждого интервала Bucket внутри периода Season, например часа суток.
+	TypeSeasonal = "seasonal"
+)
+
+// RuleConfig описание правила в YAML файле.
+type RuleConfig struct {
+	// Name имя правила, попадает в метку alertname.
+	Name string `yaml:"name"`
+	// Type тип правила, по умолчанию TypeThreshold.
+	Type string `yaml:"type"`
+	// Metric имя метрики. Если пусто, правило применяется ко всем сериям,
+	// удовлетворяющим MetricRegex и Matchers.
+	Metric string `yaml:"metric"`
+	// MetricRegex регулярное выражение имени метрики, например CPUutilization\d+.
+	MetricRegex string `yaml:"metric_regex"`
+	// Matchers матчеры меток в формате name="value", name=~"regexp".
+	Matchers []string `yaml:"matchers"`
+	// Op оператор сравнения: >, >=, <, <=, ==, !=.
+	Op string `yaml:"op"`
+	// Threshold порог срабатывания.
+	Threshold float64 `yaml:"threshold"`
+	// Window допустимое время без обновлений для TypeAbsent и TypeAgentAbsent.
+	// Для TypeEWMA и TypeSeasonal время, после которого значение серии устаревает
+	// и не считается аномальным. По умолчанию DefaultStale.
+	Window time.Duration `yaml:"window"`
+	// Agents ожидаемые агенты для TypeAgentAbsent. Агенты из списка,
+	// не записывавшие метрики с запуска сервера, тоже считаются пропавшими.
+	// Если пусто, проверяются все агенты, записывавшие метрики.
+	Agents []string `yaml:"agents"`
+	// Sigma допустимое отклонение в стандартных отклонениях для TypeEWMA
+	// и TypeSeasonal. По умолчанию DefaultSigma.
+	Sigma float64 `yaml:"sigma"`
+	// Alpha коэффициент сглаживания EWMA в интервале (0, 1].
+	// По умолчанию DefaultAlpha.
+	Alpha float64 `yaml:"alpha"`
+	// Warmup количество значений, по которым модель обучается до первого срабатывания.
+	// По умолчанию DefaultWarmup.
+	Warmup int `yaml:"warmup"`
+	// Season период сезонности для TypeSeasonal, например 24h.
+	Season time.Duration `yaml:"season"`
+	// Bucket интервал внутри периода со своим средним для TypeSeasonal.
+	// По умолчанию Season/24.
+	Bucket time.Duration `yaml:"bucket"`
+	// For время, в течение которого условие должно выполняться,
+	// прежде чем оповещение перейдет из pending в firing.
+	For time.Duration `yaml:"for"`
+	// Severity важность оповещения.
+	Severity string `yaml:"severity"`
+	// Labels дополнительные метки оповещения.
+	Labels map[string]string `yaml:"labels"`
+}
+
+// File содержимое файла правил.
+type File struct {
+	Rules        []RuleConfig        `yaml:"rules"`
+	InhibitRules []InhibitRuleConfig `yaml:"inhibit_rules"`
+}
+
+func loadFile(path string) (File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("read file: %w", err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return File{}, fmt.Errorf("unmarshal: %w", err)
+	}
+	return f, nil
+}
+
+// LoadRules загружает и проверяет правила из YAML файла.
+func LoadRules(path string) ([]Rule, error) {
+	f, err := loadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("alerting/LoadRules: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(f.Rules))
+	names := make(map[string]struct{}, len(f.Rules))
+	for i, cfg := range f.Rules {
+		rule, err := NewRule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("alerting/LoadRules: rule %d: %w", i, err)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return nil, fmt.Errorf("alerting/LoadRules: duplicate rule %q: %w", rule.Name, ErrInvalidRule)
+		}
+		names[rule.Name] = struct{}{}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Rule проверенное правило оповещения.
+type Rule struct {
+	Name     string
+	For      time.Duration
+	Severity string
+	Labels   model.Labels
+
+	cond condition
+}
+
+// condition вычисляет условие правила по текущим значениям серий.
+type condition interface {
+	// eval возвращает серии, для которых условие выполняется.
+	eval(ec *evalContext) []sample
+}
+
+// evalContext данные для вычисления условий правил.
+type evalContext struct {
+	now time.Time
+	// since время первого вычисления правил. Серии и агенты,
+	// время обновления которых неизвестно, отсчитываются от него.
+	since   time.Time
+	metrics []model.MetricDto
+	// agents время последней записи метрик по агентам.
+	agents map[string]time.Time
+}
+
+// sample серия, для которой выполняется условие правила.
+type sample struct {
+	metric model.MetricDto
+	value  float64
+}
+
+// NewRule проверяет описание правила и возвращает правило.
+func NewRule(cfg RuleConfig) (Rule, error) {
+	if cfg.Name == "" {
+		return Rule{}, fmt.Errorf("empty name: %w", ErrInvalidRule)
+	}
+	if cfg.For < 0 {
+		return Rule{}, fmt.Errorf("rule %q: negative for: %w", cfg.Name, ErrInvalidRule)
+	}
+
+	labels := model.Labels(cfg.Labels)
+	if err := labels.Validate(); err != nil {
+		return Rule{}, fmt.Errorf("rule %q: %w: labels: %w", cfg.Name, ErrInvalidRule, err)
+	}
+
+	var cond condition
+	switch cfg.Type {
+	case "", TypeThreshold:
+		sel, err := newSelector(cfg.Metric, cfg.MetricRegex, cfg.Matchers)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", cfg.Name, err)
+		}
+		op, err := parseOp(cfg.Op)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", cfg.Name, err)
+		}
+		cond = &threshold{selector: sel, op: op, threshold: cfg.Threshold}
+	case TypeAbsent:
+		sel, err := newSelector(cfg.Metric, cfg.MetricRegex, cfg.Matchers)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", cfg.Name, err)
+		}
+		if cfg.Window <= 0 {
+			return Rule{}, fmt.Errorf("rule %q: window must be positive: %w", cfg.Name, ErrInvalidRule)
+		}
+		cond = &absent{selector: sel, window: cfg.Window}
+	case TypeAgentAbsent:
+		if cfg.Window <= 0 {
+			return Rule{}, fmt.Errorf("rule %q: window must be positive: %w", cfg.Name, ErrInvalidRule)
+		}
+		cond = &agentAbsent{agents: cfg.Agents, window: cfg.Window}
+	case TypeEWMA, TypeSeasonal:
+		sel, err := newSelector(cfg.Metric, cfg.MetricRegex, cfg.Matchers)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", cfg.Name, err)
+		}
+		a, err := newAnomaly(cfg, sel)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %q: %w", cfg.Name, err)
+		}
+		cond = a
+	default:
+		return Rule{}, fmt.Errorf("rule %q: unknown type %q: %w", cfg.Name, cfg.Type, ErrInvalidRule)
+	}
+
+	return Rule{
+		Name:     cfg.Name,
+		For:      cfg.For,
+		Severity: cfg.Severity,
+		Labels:   labels,
+		cond:     cond,
+	}, nil
+}
+
+// selector выбирает серии по имени метрики и матчерам меток.
+type selector struct {
+	metric   string
+	nameRe   *regexp.Regexp
+	matchers []model.Matcher
+}
+
+func newSelector(metric, metricRegex string, exprs []string) (selector, error) {
+	if metric == "" && metricRegex == "" && len(exprs) == 0 {
+		return selector{}, fmt.Errorf("metric, metric_regex or matchers required: %w", ErrInvalidRule)
+	}
+	if metric != "" && metricRegex != "" {
+		return selector{}, fmt.Errorf("metric and metric_regex are mutually exclusive: %w", ErrInvalidRule)
+	}
+
+	sel := selector{metric: metric}
+	if metricRegex != "" {
+		re, err := regexp.Compile("^(?:" + metricRegex + ")$")
+		if err != nil {
+			return selector{}, fmt.Errorf("%w: metric_regex: %w", ErrInvalidRule, err)
+		}
+		sel.nameRe = re
+	}
+	matchers, err := parseMatchers(exprs)
+	if err != nil {
+		return selector{}, err
+	}
+	sel.matchers = matchers
+	return sel, nil
+}
+
+// matches сообщает, выбирает ли селектор серию.
+func (s selector) matches(m model.MetricDto) bool {
+	if s.metric != "" && m.Name != s.metric {
+		return false
+	}
+	if s.nameRe != nil && !s.nameRe.MatchString(m.Name) {
+		return false
+	}
+	return model.MatchLabels(m.Labels, s.matchers)
+}
+
+// value возвращает числовое значение серии.
+// Гистограммы не имеют скалярного значения и пропускаются.
+func value(m model.MetricDto) (float64, bool) {
+	switch m.Value.Type {
+	case model.TypeGauge:
+		return m.Value.Gauge, true
+	case model.TypeCounter:
+		return float64(m.Value.Counter), true
+	default:
+		return 0, false
+	}
+}
+
+// op оператор сравнения с порогом.
+type op string
+
+const (
+	opGreater      op = ">"
+	opGreaterEqual op = ">="
+	opLess         op = "<"
+	opLessEqual    op = "<="
+	opEqual        op = "=="
+	opNotEqual     op = "!="
+)
+
+func parseOp(s string) (op, error) {
+	switch o := op(s); o {
+	case opGreater, opGreaterEqual, opLess, opLessEqual, opEqual, opNotEqual:
+		return o, nil
+	default:
+		return "", fmt.Errorf("unknown operator %q: %w", s, ErrInvalidRule)
+	}
+}
+
+func (o op) compare(v, threshold float64) bool {
+	switch o {
+	case opGreater:
+		return v > threshold
+	case opGreaterEqual:
+		return v >= threshold
+	case opLess:
+		return v < threshold
+	case opLessEqual:
+		return v <= threshold
+	case opEqual:
+		return v == threshold
+	case opNotEqual:
+		return v != threshold
+	}
+	return false
+}
+
+// threshold условие сравнения значения серии с порогом.
+type threshold struct {
+	selector
+	op        op
+	threshold float64
+}
+
+func (t *threshold) eval(ec *evalContext) []sample {
+	var samples []sample
+	for _, m := range ec.metrics {
+		if !t.matches(m) {
+			continue
+		}
+		v, ok := value(m)
+		if !ok || !t.op.compare(v, t.threshold) {
+			continue
+		}
+		samples = append(samples, sample{metric: m, value: v})
+	}
+	return samples
+}
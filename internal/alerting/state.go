@@ -0,0 +1,133 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+)
+
+// stateful условие, модель которого сохраняется между перезапусками.
+type stateful interface {
+	snapshot() []modelState
+	restore(st modelState) bool
+	baselines() []Baseline
+}
+
+// engineState содержимое файла состояния движка.
+//
+//easyjson:json
+type engineState struct {
+	Models []modelState `json:"models"`
+}
+
+// RestoreState загружает модели правил аномалий из файла StateFile.
+// Отсутствие файла не является ошибкой. Модели удаленных правил
+// и модели с изменившимся количеством интервалов периода отбрасываются.
+func (e *Engine) RestoreState() error {
+	if e.opts.StateFile == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(e.opts.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("alerting/RestoreState: read file: %w", err)
+	}
+
+	var st engineState
+	if err := easyjson.Unmarshal(b, &st); err != nil {
+		return fmt.Errorf("alerting/RestoreState: unmarshal: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	conds := e.statefulRules()
+	restored := 0
+	for _, m := range st.Models {
+		cond, ok := conds[m.Rule]
+		if ok && cond.restore(m) {
+			restored++
+		}
+	}
+	e.opts.Logger.Info("anomaly models restored",
+		zap.Int("restored", restored),
+		zap.Int("discarded", len(st.Models)-restored),
+		zap.String("scope", "alerting/RestoreState"),
+	)
+	return nil
+}
+
+// SaveState атомарно записывает модели правил аномалий в файл StateFile.
+func (e *Engine) SaveState() error {
+	if e.opts.StateFile == "" {
+		return nil
+	}
+
+	e.mu.RLock()
+	var st engineState
+	for _, cond := range e.statefulRules() {
+		st.Models = append(st.Models, cond.snapshot()...)
+	}
+	e.mu.RUnlock()
+
+	b, err := easyjson.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("alerting/SaveState: marshal: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.opts.StateFile), filepath.Base(e.opts.StateFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("alerting/SaveState: create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("alerting/SaveState: write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("alerting/SaveState: close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), e.opts.StateFile); err != nil {
+		return fmt.Errorf("alerting/SaveState: rename: %w", err)
+	}
+	return nil
+}
+
+// Baselines возвращает текущие оценки серий правил аномалий,
+// упорядоченные по правилу и серии.
+func (e *Engine) Baselines() []Baseline {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var baselines []Baseline
+	for _, rule := range e.opts.Rules {
+		if cond, ok := rule.cond.(stateful); ok {
+			baselines = append(baselines, cond.baselines()...)
+		}
+	}
+	sort.Slice(baselines, func(i, j int) bool {
+		return alertKey(baselines[i].Rule, baselines[i].Metric, baselines[i].Labels) <
+			alertKey(baselines[j].Rule, baselines[j].Metric, baselines[j].Labels)
+	})
+	return baselines
+}
+
+// statefulRules возвращает условия с моделями по именам правил.
+func (e *Engine) statefulRules() map[string]stateful {
+	conds := make(map[string]stateful)
+	for _, rule := range e.opts.Rules {
+		if cond, ok := rule.cond.(stateful); ok {
+			conds[rule.Name] = cond
+		}
+	}
+	return conds
+}
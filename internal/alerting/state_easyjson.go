@@ -0,0 +1,116 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonBd887cf1DecodeGithubComHtrandevMetricsInternalAlerting(in *jlexer.Lexer, out *engineState) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "models":
+			if in.IsNull() {
+				in.Skip()
+				out.Models = nil
+			} else {
+				in.Delim('[')
+				if out.Models == nil {
+					if !in.IsDelim(']') {
+						out.Models = make([]modelState, 0, 0)
+					} else {
+						out.Models = []modelState{}
+					}
+				} else {
+					out.Models = (out.Models)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 modelState
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v1).UnmarshalEasyJSON(in)
+					}
+					out.Models = append(out.Models, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBd887cf1EncodeGithubComHtrandevMetricsInternalAlerting(out *jwriter.Writer, in engineState) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"models\":"
+		out.RawString(prefix[1:])
+		if in.Models == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Models {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v engineState) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBd887cf1EncodeGithubComHtrandevMetricsInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v engineState) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBd887cf1EncodeGithubComHtrandevMetricsInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *engineState) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBd887cf1DecodeGithubComHtrandevMetricsInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *engineState) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBd887cf1DecodeGithubComHtrandevMetricsInternalAlerting(l, v)
+}
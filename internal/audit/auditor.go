@@ -1,6 +1,17 @@
 package audit
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultQueueSize размер очереди событий наблюдателя по умолчанию.
+const DefaultQueueSize = 1024
 
 // Observer определяет интерфейс наблюдателя
 // для получения уведомлений о событиях.
@@ -9,13 +20,84 @@ type Observer interface {
 	GetID() string
 }
 
+// OverflowPolicy определяет поведение Auditor при переполнении очереди наблюдателя.
+type OverflowPolicy string
+
+const (
+	// PolicyDrop отбрасывает событие, если очередь наблюдателя заполнена.
+	PolicyDrop OverflowPolicy = "drop"
+	// PolicyBlock ожидает освобождения места в очереди,
+	// пока не будет отменен контекст события.
+	PolicyBlock OverflowPolicy = "block"
+)
+
+// ParsePolicy возвращает политику переполнения по ее названию.
+func ParsePolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(s); p {
+	case PolicyDrop, PolicyBlock:
+		return p, nil
+	default:
+		return "", fmt.Errorf("audit/ParsePolicy: unknown overflow policy %q", s)
+	}
+}
+
 // Auditor реализует паттерн "Наблюдатель" для распределения событий
 // между зарегистрированными наблюдателями.
+//
+// Каждый наблюдатель получает события асинхронно из собственной ограниченной
+// очереди, поэтому медленный наблюдатель не задерживает обработку запросов.
 type Auditor struct {
-	observers map[string]Observer
-	info      AuditInfo
+	mu        sync.RWMutex
+	observers map[string]*worker
+	closed    bool
+
+	queueSize int
+	policy    OverflowPolicy
+	logger    *zap.Logger
+}
+
+// Option настраивает Auditor.
+type Option func(*Auditor)
+
+// WithQueueSize задает размер очереди событий каждого наблюдателя.
+func WithQueueSize(size int) Option {
+	return func(a *Auditor) {
+		if size > 0 {
+			a.queueSize = size
+		}
+	}
+}
+
+// WithPolicy задает политику переполнения очереди.
+func WithPolicy(p OverflowPolicy) Option {
+	return func(a *Auditor) {
+		if p != "" {
+			a.policy = p
+		}
+	}
+}
+
+// WithLogger задает логгер.
+func WithLogger(l *zap.Logger) Option {
+	return func(a *Auditor) {
+		if l != nil {
+			a.logger = l
+		}
+	}
 }
 
+// Транспорт, по которому получены метрики.
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Результат обработки запроса.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
 // AuditInfo содержит информацию о событии.
 //
 //easyjson:json
@@ -23,35 +105,255 @@ type AuditInfo struct {
 	Timestamp int64    `json:"ts"`
 	Metrics   []string `json:"metrics"`
 	IP        string   `json:"ip_address"`
+	// Agent идентичность агента из клиентского сертификата или API токена, если она установлена.
+	Agent string `json:"agent,omitempty"`
+	// Transport транспорт запроса: TransportHTTP или TransportGRPC.
+	Transport string `json:"transport,omitempty"`
+	// RequestID идентификатор запроса.
+	RequestID string `json:"request_id,omitempty"`
+	// Values значения метрик в порядке Metrics.
+	Values []MetricValue `json:"values,omitempty"`
+	// Outcome результат сохранения: OutcomeSuccess или OutcomeError.
+	Outcome string `json:"outcome,omitempty"`
+	// Error описание ошибки сохранения.
+	Error string `json:"error,omitempty"`
+}
+
+// MetricValue значение метрики в событии аудита.
+type MetricValue struct {
+	// Name имя метрики с метками.
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// AuditBatch пакет событий.
+//
+//easyjson:json
+type AuditBatch []AuditInfo
+
+// event событие в очереди наблюдателя.
+type event struct {
+	ctx  context.Context
+	info AuditInfo
+}
+
+// worker доставляет события из очереди одному наблюдателю.
+type worker struct {
+	observer Observer
+	queue    chan event
+	done     chan struct{}
+	dropped  atomic.Uint64
+
+	// stopped закрывается, когда наблюдатель удален и события больше не принимаются.
+	stopped chan struct{}
+	// senders отправители, которые ставят событие в очередь без блокировки Auditor.
+	senders sync.WaitGroup
+}
+
+func newWorker(o Observer, queueSize int) *worker {
+	return &worker{
+		observer: o,
+		queue:    make(chan event, queueSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+func (w *worker) run() {
+	defer close(w.done)
+	for ev := range w.queue {
+		w.observer.Update(ev.ctx, ev.info)
+	}
+}
+
+// stop прекращает прием событий. Ожидающие отправители освобождаются,
+// после их завершения очередь закрывается, а оставшиеся в ней события доставляются.
+// Вызывается под блокировкой Auditor после удаления worker из observers,
+// поэтому новые отправители не появятся.
+func (w *worker) stop() {
+	close(w.stopped)
+	go func() {
+		w.senders.Wait()
+		close(w.queue)
+	}()
 }
 
 // NewAuditor создает и возвращает новый экземпляр Auditor.
-func NewAuditor() *Auditor {
-	return &Auditor{observers: make(map[string]Observer)}
+func NewAuditor(opts ...Option) *Auditor {
+	a := &Auditor{
+		observers: make(map[string]*worker),
+		queueSize: DefaultQueueSize,
+		policy:    PolicyDrop,
+		logger:    zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.logger = a.logger.With(zap.String("scope", "auditor"))
+	return a
 }
 
-// Register регистрирует нового наблюдателя в Auditor по его уникальному ID.
+// Register регистрирует нового наблюдателя в Auditor по его уникальному ID
+// и запускает доставку ему событий.
+// Наблюдатель с тем же ID заменяется, его очередь дообрабатывается.
 func (a *Auditor) Register(o Observer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		a.logger.Warn("register observer after shutdown", zap.String("observer", o.GetID()))
+		return
+	}
 	if a.observers == nil {
-		a.observers = make(map[string]Observer)
+		a.observers = make(map[string]*worker)
+	}
+	if old, ok := a.observers[o.GetID()]; ok {
+		old.stop()
 	}
-	a.observers[o.GetID()] = o
+
+	w := newWorker(o, a.queueSize)
+	a.observers[o.GetID()] = w
+	go w.run()
 }
 
 // Deregister удаляет наблюдателя из Auditor по его уникальному ID.
+// События, уже находящиеся в очереди наблюдателя, будут доставлены.
 func (a *Auditor) Deregister(o Observer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w, ok := a.observers[o.GetID()]
+	if !ok {
+		return
+	}
 	delete(a.observers, o.GetID())
+	w.stop()
 }
 
-// Update обновляет информацию о событии и уведомляет всех зарегистрированных наблюдателей.
+// Update ставит событие в очереди всех зарегистрированных наблюдателей.
+// Наблюдатели получают контекст события без отмены, так как событие
+// может быть доставлено после завершения запроса.
+// Ожидание места в очереди при PolicyBlock не блокирует регистрацию наблюдателей.
 func (a *Auditor) Update(ctx context.Context, info AuditInfo) {
-	a.info = info
-	a.notifyAll(ctx)
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+		return
+	}
+	workers := make([]*worker, 0, len(a.observers))
+	for _, w := range a.observers {
+		w.senders.Add(1)
+		workers = append(workers, w)
+	}
+	a.mu.RUnlock()
+
+	ev := event{ctx: context.WithoutCancel(ctx), info: info}
+	for _, w := range workers {
+		if !a.enqueue(ctx, w, ev) {
+			w.dropped.Add(1)
+			a.logger.Debug("audit event dropped", zap.String("observer", w.observer.GetID()))
+		}
+	}
+}
+
+// enqueue ставит событие в очередь наблюдателя согласно политике переполнения
+// и завершает отправку, начатую в Update. Возвращает false, если событие отброшено.
+func (a *Auditor) enqueue(ctx context.Context, w *worker, ev event) bool {
+	defer w.senders.Done()
+
+	select {
+	case <-w.stopped:
+		return false
+	default:
+	}
+
+	select {
+	case w.queue <- ev:
+		return true
+	default:
+	}
+
+	if a.policy != PolicyBlock {
+		return false
+	}
+	select {
+	case w.queue <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-w.stopped:
+		return false
+	}
+}
+
+// Dropped возвращает количество отброшенных событий по ID наблюдателей.
+func (a *Auditor) Dropped() map[string]uint64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	dropped := make(map[string]uint64, len(a.observers))
+	for id, w := range a.observers {
+		dropped[id] = w.dropped.Load()
+	}
+	return dropped
 }
 
-// notifyAll уведомляет всех зарегистрированных наблюдателей об обновлении.
-func (a *Auditor) notifyAll(ctx context.Context) {
-	for _, o := range a.observers {
-		o.Update(ctx, a.info)
+// ReportDropped каждые interval записывает в лог количество событий,
+// отброшенных с предыдущей записи, пока не будет отменен ctx.
+func (a *Auditor) ReportDropped(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	reported := make(map[string]uint64)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for id, total := range a.Dropped() {
+			n := total - reported[id]
+			if total < reported[id] {
+				// наблюдатель был зарегистрирован заново, счетчик начат с нуля
+				n = total
+			}
+			if n > 0 {
+				a.logger.Warn("audit events dropped",
+					zap.String("observer", id),
+					zap.Uint64("dropped", n),
+					zap.Uint64("total", total),
+				)
+			}
+			reported[id] = total
+		}
+	}
+}
+
+// Shutdown прекращает прием событий и ожидает доставки событий,
+// оставшихся в очередях наблюдателей, или отмены контекста.
+func (a *Auditor) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+
+	workers := make([]*worker, 0, len(a.observers))
+	for _, w := range a.observers {
+		w.stop()
+		workers = append(workers, w)
+	}
+	a.mu.Unlock()
+
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			return fmt.Errorf("audit/Shutdown: drain queues: %w", ctx.Err())
+		}
 	}
+	return nil
 }
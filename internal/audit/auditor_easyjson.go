@@ -17,7 +17,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit(in *jlexer.Lexer, out *Auditor) {
+func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit(in *jlexer.Lexer, out *AuditInfo) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -31,6 +31,98 @@ func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit(in *jlexer.Lexer,
 		key := in.UnsafeFieldName(false)
 		in.WantColon()
 		switch key {
+		case "ts":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Timestamp = int64(in.Int64())
+			}
+		case "metrics":
+			if in.IsNull() {
+				in.Skip()
+				out.Metrics = nil
+			} else {
+				in.Delim('[')
+				if out.Metrics == nil {
+					if !in.IsDelim(']') {
+						out.Metrics = make([]string, 0, 4)
+					} else {
+						out.Metrics = []string{}
+					}
+				} else {
+					out.Metrics = (out.Metrics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v1 = string(in.String())
+					}
+					out.Metrics = append(out.Metrics, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "ip_address":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.IP = string(in.String())
+			}
+		case "agent":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Agent = string(in.String())
+			}
+		case "transport":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Transport = string(in.String())
+			}
+		case "request_id":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.RequestID = string(in.String())
+			}
+		case "values":
+			if in.IsNull() {
+				in.Skip()
+				out.Values = nil
+			} else {
+				in.Delim('[')
+				if out.Values == nil {
+					if !in.IsDelim(']') {
+						out.Values = make([]MetricValue, 0, 1)
+					} else {
+						out.Values = []MetricValue{}
+					}
+				} else {
+					out.Values = (out.Values)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 MetricValue
+					easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(in, &v2)
+					out.Values = append(out.Values, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "outcome":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Outcome = string(in.String())
+			}
+		case "error":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Error = string(in.String())
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -41,37 +133,102 @@ func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit(out *jwriter.Writer, in Auditor) {
+func easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit(out *jwriter.Writer, in AuditInfo) {
 	out.RawByte('{')
 	first := true
 	_ = first
+	{
+		const prefix string = ",\"ts\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Timestamp))
+	}
+	{
+		const prefix string = ",\"metrics\":"
+		out.RawString(prefix)
+		if in.Metrics == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v3, v4 := range in.Metrics {
+				if v3 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v4))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"ip_address\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	if in.Agent != "" {
+		const prefix string = ",\"agent\":"
+		out.RawString(prefix)
+		out.String(string(in.Agent))
+	}
+	if in.Transport != "" {
+		const prefix string = ",\"transport\":"
+		out.RawString(prefix)
+		out.String(string(in.Transport))
+	}
+	if in.RequestID != "" {
+		const prefix string = ",\"request_id\":"
+		out.RawString(prefix)
+		out.String(string(in.RequestID))
+	}
+	if len(in.Values) != 0 {
+		const prefix string = ",\"values\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Values {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit1(out, v6)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Outcome != "" {
+		const prefix string = ",\"outcome\":"
+		out.RawString(prefix)
+		out.String(string(in.Outcome))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Auditor) MarshalJSON() ([]byte, error) {
+func (v AuditInfo) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
 	easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Auditor) MarshalEasyJSON(w *jwriter.Writer) {
+func (v AuditInfo) MarshalEasyJSON(w *jwriter.Writer) {
 	easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Auditor) UnmarshalJSON(data []byte) error {
+func (v *AuditInfo) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
 	easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Auditor) UnmarshalEasyJSON(l *jlexer.Lexer) {
+func (v *AuditInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit(l, v)
 }
-func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(in *jlexer.Lexer, out *AuditInfo) {
+func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(in *jlexer.Lexer, out *MetricValue) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -85,44 +242,23 @@ func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(in *jlexer.Lexer
 		key := in.UnsafeFieldName(false)
 		in.WantColon()
 		switch key {
-		case "ts":
+		case "name":
 			if in.IsNull() {
 				in.Skip()
 			} else {
-				out.Timestamp = int64(in.Int64())
+				out.Name = string(in.String())
 			}
-		case "metrics":
+		case "type":
 			if in.IsNull() {
 				in.Skip()
-				out.Metrics = nil
 			} else {
-				in.Delim('[')
-				if out.Metrics == nil {
-					if !in.IsDelim(']') {
-						out.Metrics = make([]string, 0, 4)
-					} else {
-						out.Metrics = []string{}
-					}
-				} else {
-					out.Metrics = (out.Metrics)[:0]
-				}
-				for !in.IsDelim(']') {
-					var v1 string
-					if in.IsNull() {
-						in.Skip()
-					} else {
-						v1 = string(in.String())
-					}
-					out.Metrics = append(out.Metrics, v1)
-					in.WantComma()
-				}
-				in.Delim(']')
+				out.Type = string(in.String())
 			}
-		case "ip_address":
+		case "value":
 			if in.IsNull() {
 				in.Skip()
 			} else {
-				out.IP = string(in.String())
+				out.Value = string(in.String())
 			}
 		default:
 			in.SkipRecursive()
@@ -134,59 +270,94 @@ func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(in *jlexer.Lexer
 		in.Consumed()
 	}
 }
-func easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit1(out *jwriter.Writer, in AuditInfo) {
+func easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit1(out *jwriter.Writer, in MetricValue) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"ts\":"
+		const prefix string = ",\"name\":"
 		out.RawString(prefix[1:])
-		out.Int64(int64(in.Timestamp))
+		out.String(string(in.Name))
 	}
 	{
-		const prefix string = ",\"metrics\":"
+		const prefix string = ",\"type\":"
 		out.RawString(prefix)
-		if in.Metrics == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
-			out.RawString("null")
-		} else {
-			out.RawByte('[')
-			for v2, v3 := range in.Metrics {
-				if v2 > 0 {
-					out.RawByte(',')
-				}
-				out.String(string(v3))
-			}
-			out.RawByte(']')
-		}
+		out.String(string(in.Type))
 	}
 	{
-		const prefix string = ",\"ip_address\":"
+		const prefix string = ",\"value\":"
 		out.RawString(prefix)
-		out.String(string(in.IP))
+		out.String(string(in.Value))
 	}
 	out.RawByte('}')
 }
+func easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit2(in *jlexer.Lexer, out *AuditBatch) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(AuditBatch, 0, 0)
+			} else {
+				*out = AuditBatch{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v7 AuditInfo
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v7).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v7)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit2(out *jwriter.Writer, in AuditBatch) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v8, v9 := range in {
+			if v8 > 0 {
+				out.RawByte(',')
+			}
+			(v9).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
 
 // MarshalJSON supports json.Marshaler interface
-func (v AuditInfo) MarshalJSON() ([]byte, error) {
+func (v AuditBatch) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit1(&w, v)
+	easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v AuditInfo) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit1(w, v)
+func (v AuditBatch) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAb7d04EncodeGithubComHtrandevMetricsInternalAudit2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *AuditInfo) UnmarshalJSON(data []byte) error {
+func (v *AuditBatch) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(&r, v)
+	easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *AuditInfo) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit1(l, v)
+func (v *AuditBatch) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAb7d04DecodeGithubComHtrandevMetricsInternalAudit2(l, v)
 }
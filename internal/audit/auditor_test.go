@@ -2,7 +2,9 @@ package audit
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -12,20 +14,37 @@ var _ Observer = (*mockObserver)(nil)
 
 type mockObserver struct {
 	id uuid.UUID
+
+	mu       sync.Mutex
+	received []AuditInfo
+	// release блокирует доставку событий, пока не будет закрыт.
+	release chan struct{}
 }
 
-func (m *mockObserver) Update(ctx context.Context, info AuditInfo) {}
+func (m *mockObserver) Update(ctx context.Context, info AuditInfo) {
+	if m.release != nil {
+		<-m.release
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received = append(m.received, info)
+}
 
 func (m *mockObserver) GetID() string {
 	return m.id.String()
 }
 
+func (m *mockObserver) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.received)
+}
+
 func TestRegister(t *testing.T) {
 	id := uuid.New()
 	o := &mockObserver{
 		id: id,
 	}
-
 	a := NewAuditor()
 	a.Register(o)
 
@@ -41,11 +60,144 @@ func TestDeregister(t *testing.T) {
 	o := &mockObserver{
 		id: id,
 	}
-
 	a := NewAuditor()
-	a.observers[id.String()] = o
+	a.Register(o)
 	a.Deregister(o)
 
-	_, ok := a.observers[uuid.New().String()]
+	_, ok := a.observers[id.String()]
 	require.False(t, ok)
+
+	a.Update(context.Background(), AuditInfo{})
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Equal(t, 0, o.count())
+}
+
+func TestUpdate(t *testing.T) {
+	o1 := &mockObserver{id: uuid.New()}
+	o2 := &mockObserver{id: uuid.New()}
+
+	a := NewAuditor()
+	a.Register(o1)
+	a.Register(o2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 10; i++ {
+		a.Update(ctx, AuditInfo{Timestamp: int64(i)})
+	}
+	// отмена контекста запроса не должна мешать доставке
+	cancel()
+
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Equal(t, 10, o1.count())
+	require.Equal(t, 10, o2.count())
+	require.Equal(t, int64(9), o1.received[9].Timestamp)
+
+	// после остановки события не принимаются
+	a.Update(context.Background(), AuditInfo{})
+	require.Equal(t, 10, o1.count())
+}
+
+func TestOverflowDrop(t *testing.T) {
+	slow := &mockObserver{id: uuid.New(), release: make(chan struct{})}
+	fast := &mockObserver{id: uuid.New()}
+
+	a := NewAuditor(WithQueueSize(2), WithPolicy(PolicyDrop))
+	a.Register(slow)
+	a.Register(fast)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			a.Update(context.Background(), AuditInfo{})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("update blocked by slow observer")
+	}
+
+	dropped := a.Dropped()
+	// одно событие может быть взято обработчиком, два находятся в очереди
+	require.GreaterOrEqual(t, dropped[slow.GetID()], uint64(7))
+
+	close(slow.release)
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Equal(t, 10, fast.count()+int(dropped[fast.GetID()]))
+	require.Equal(t, 10, slow.count()+int(dropped[slow.GetID()]))
+}
+
+func TestOverflowBlock(t *testing.T) {
+	slow := &mockObserver{id: uuid.New(), release: make(chan struct{})}
+
+	a := NewAuditor(WithQueueSize(1), WithPolicy(PolicyBlock))
+	a.Register(slow)
+
+	// заполняем обработчик и очередь
+	a.Update(context.Background(), AuditInfo{})
+	require.Eventually(t, func() bool { return len(a.observers[slow.GetID()].queue) == 0 }, time.Second, time.Millisecond)
+	a.Update(context.Background(), AuditInfo{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	a.Update(ctx, AuditInfo{})
+	require.Equal(t, uint64(1), a.Dropped()[slow.GetID()])
+
+	close(slow.release)
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Equal(t, 2, slow.count())
+}
+
+func TestBlockedUpdateDeregister(t *testing.T) {
+	slow := &mockObserver{id: uuid.New(), release: make(chan struct{})}
+	defer close(slow.release)
+
+	a := NewAuditor(WithQueueSize(1), WithPolicy(PolicyBlock))
+	a.Register(slow)
+	w := a.observers[slow.GetID()]
+
+	a.Update(context.Background(), AuditInfo{})
+	require.Eventually(t, func() bool { return len(w.queue) == 0 }, time.Second, time.Millisecond)
+	a.Update(context.Background(), AuditInfo{})
+
+	// отправитель ожидает места в очереди без блокировки Auditor
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		a.Update(context.Background(), AuditInfo{})
+	}()
+
+	other := &mockObserver{id: uuid.New()}
+	a.Register(other)
+	a.Deregister(slow)
+
+	select {
+	case <-blocked:
+	case <-time.After(time.Second):
+		t.Fatal("update is still blocked after deregister")
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	slow := &mockObserver{id: uuid.New(), release: make(chan struct{})}
+	defer close(slow.release)
+
+	a := NewAuditor()
+	a.Register(slow)
+	a.Update(context.Background(), AuditInfo{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, a.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("block")
+	require.NoError(t, err)
+	require.Equal(t, PolicyBlock, p)
+
+	_, err = ParsePolicy("wait")
+	require.Error(t, err)
 }
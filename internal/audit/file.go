@@ -1,8 +1,19 @@
 package audit
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mailru/easyjson"
@@ -11,26 +22,124 @@ import (
 
 var _ Observer = (*FileAudit)(nil)
 
+const (
+	// GenesisHash хэш, на который ссылается первая запись цепочки.
+	GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	// segmentTimeFormat формат метки времени в имени ротированного сегмента.
+	// Метки сортируются лексикографически в порядке ротации.
+	segmentTimeFormat = "20060102T150405.000000000"
+
+	// gzipExt расширение сжатого сегмента.
+	gzipExt = ".gz"
+
+	fileFlag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	fileMode = 0664
+)
+
 // FileAudit реализует Observer для записи событий в файл.
+//
+// Файл может ротироваться по размеру и времени, ротированные сегменты
+// хранятся рядом с файлом под именем <файл>.<метка времени>[.gz].
+// В режиме цепочки каждая запись содержит SHA-256 предыдущей записи,
+// что позволяет обнаружить измененные и удаленные строки, см. VerifyChain.
 type FileAudit struct {
-	id   uuid.UUID
-	file *os.File
+	id uuid.UUID
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	prevHash string
+
+	opts fileOptions
 
 	logger *zap.Logger
 }
 
+type fileOptions struct {
+	maxSize   int64
+	maxAge    time.Duration
+	compress  bool
+	retention int
+	hashChain bool
+}
+
+// FileOption настраивает FileAudit.
+type FileOption func(*fileOptions)
+
+// WithRotation включает ротацию файла при превышении размера maxSize байт
+// или по истечении maxAge с момента открытия. Нулевое значение отключает условие.
+func WithRotation(maxSize int64, maxAge time.Duration) FileOption {
+	return func(o *fileOptions) {
+		o.maxSize = maxSize
+		o.maxAge = maxAge
+	}
+}
+
+// WithCompression включает сжатие ротированных сегментов gzip.
+func WithCompression() FileOption {
+	return func(o *fileOptions) {
+		o.compress = true
+	}
+}
+
+// WithRetention задает количество хранимых ротированных сегментов.
+// Более старые сегменты удаляются. Ноль сохраняет все сегменты.
+func WithRetention(n int) FileOption {
+	return func(o *fileOptions) {
+		o.retention = n
+	}
+}
+
+// WithHashChain включает запись хэша предыдущей записи в каждую запись.
+func WithHashChain() FileOption {
+	return func(o *fileOptions) {
+		o.hashChain = true
+	}
+}
+
+// chainRecord запись аудита в режиме цепочки.
+//
+//easyjson:json
+type chainRecord struct {
+	AuditInfo
+	PrevHash string `json:"prev_hash"`
+}
+
 // NewFile возвращает новый экземпляр FileAudit.
-func NewFile(id uuid.UUID, f *os.File, l *zap.Logger) *FileAudit {
+// Файл f должен быть открыт на дозапись, при ротации он переоткрывается по имени.
+func NewFile(id uuid.UUID, f *os.File, l *zap.Logger, opts ...FileOption) (*FileAudit, error) {
 	if l == nil {
 		l = zap.NewNop()
 	}
-	l.With(zap.String("scope", "fileAudit"))
+	l = l.With(zap.String("scope", "fileAudit"))
 
-	return &FileAudit{
-		id:     id,
-		file:   f,
-		logger: l,
+	fa := &FileAudit{
+		id:       id,
+		file:     f,
+		openedAt: time.Now(),
+		prevHash: GenesisHash,
+		logger:   l,
 	}
+	for _, opt := range opts {
+		opt(&fa.opts)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("audit/NewFile: stat file: %w", err)
+	}
+	fa.size = stat.Size()
+
+	if fa.opts.hashChain {
+		prev, err := lastHash(f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("audit/NewFile: restore hash chain: %w", err)
+		}
+		fa.prevHash = prev
+	}
+	return fa, nil
 }
 
 // GetID возвращает уникальный идентификатор FileAudit.
@@ -42,20 +151,30 @@ func (f *FileAudit) GetID() string {
 func (f *FileAudit) Update(ctx context.Context, info AuditInfo) {
 	f.logger.Debug("write info to file")
 
-	b, err := easyjson.Marshal(info)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, err := f.marshal(info)
 	if err != nil {
 		f.logger.Error("marshal audit info", zap.Error(err))
 		return
 	}
 
-	if _, err := f.file.Write(b); err != nil {
+	if f.needRotate(int64(len(b)) + 1) {
+		if err := f.rotate(); err != nil {
+			f.logger.Error("rotate audit file", zap.Error(err))
+		}
+	}
+
+	n, err := f.file.Write(append(b, '\n'))
+	f.size += int64(n)
+	if err != nil {
 		f.logger.Error("write data", zap.Error(err))
 		return
 	}
 
-	if _, err := f.file.WriteString("\n"); err != nil {
-		f.logger.Error("write new line", zap.Error(err))
-		return
+	if f.opts.hashChain {
+		f.prevHash = hashRecord(b)
 	}
 
 	f.logger.Debug("audit info successfully written to file")
@@ -63,5 +182,203 @@ func (f *FileAudit) Update(ctx context.Context, info AuditInfo) {
 
 // Close закрывает файловый дескриптор.
 func (f *FileAudit) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.opts.hashChain {
+		f.logger.Info("audit hash chain head", zap.String("head", f.prevHash))
+	}
 	return f.file.Close()
 }
+
+// marshal сериализует запись, в режиме цепочки добавляя хэш предыдущей записи.
+func (f *FileAudit) marshal(info AuditInfo) ([]byte, error) {
+	if !f.opts.hashChain {
+		return easyjson.Marshal(info)
+	}
+	return easyjson.Marshal(chainRecord{AuditInfo: info, PrevHash: f.prevHash})
+}
+
+// needRotate сообщает, нужно ли ротировать файл перед записью n байт.
+func (f *FileAudit) needRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.maxSize > 0 && f.size+n > f.opts.maxSize {
+		return true
+	}
+	return f.opts.maxAge > 0 && time.Since(f.openedAt) >= f.opts.maxAge
+}
+
+// rotate переименовывает текущий файл в сегмент, сжимает его,
+// удаляет сегменты сверх retention и открывает новый файл.
+func (f *FileAudit) rotate() error {
+	path := f.file.Name()
+
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	segment := path + "." + time.Now().UTC().Format(segmentTimeFormat)
+	renameErr := os.Rename(path, segment)
+
+	file, err := os.OpenFile(path, fileFlag, fileMode)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	f.file = file
+	f.size = 0
+	f.openedAt = time.Now()
+
+	if renameErr != nil {
+		return fmt.Errorf("rename file: %w", renameErr)
+	}
+
+	f.logger.Info("audit file rotated", zap.String("segment", segment), zap.String("head", f.prevHash))
+
+	if f.opts.compress {
+		if err := compressFile(segment); err != nil {
+			return fmt.Errorf("compress segment: %w", err)
+		}
+	}
+
+	if f.opts.retention > 0 {
+		if err := removeOldSegments(path, f.opts.retention); err != nil {
+			return fmt.Errorf("apply retention: %w", err)
+		}
+	}
+	return nil
+}
+
+// Segments возвращает ротированные сегменты файла path от старых к новым.
+func Segments(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, fmt.Errorf("audit/Segments: glob: %w", err)
+	}
+
+	segments := matches[:0]
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, path+"."), gzipExt)
+		if _, err := time.Parse(segmentTimeFormat, stamp); err == nil {
+			segments = append(segments, m)
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// removeOldSegments удаляет самые старые сегменты файла path, оставляя keep сегментов.
+func removeOldSegments(path string, keep int) error {
+	segments, err := Segments(path)
+	if err != nil {
+		return err
+	}
+	for len(segments) > keep {
+		if err := os.Remove(segments[0]); err != nil {
+			return fmt.Errorf("remove segment: %w", err)
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
+
+// compressFile сжимает файл path в path.gz и удаляет исходный файл.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+gzipExt, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileMode)
+	if err != nil {
+		return fmt.Errorf("create compressed segment: %w", err)
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("compress: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return fmt.Errorf("close gzip writer: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("close compressed segment: %w", err)
+	}
+	return os.Remove(path)
+}
+
+// openSegment открывает файл аудита, распаковывая сжатые сегменты.
+func openSegment(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, gzipExt) {
+		return f, nil
+	}
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open gzip reader: %w", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{Reader: zr, Closer: f}, nil
+}
+
+// lastHash возвращает хэш последней записи файла path или его последнего сегмента.
+func lastHash(path string) (string, error) {
+	candidates, err := Segments(path)
+	if err != nil {
+		return "", err
+	}
+	candidates = append(candidates, path)
+
+	for i := len(candidates) - 1; i >= 0; i-- {
+		line, err := lastLine(candidates[i])
+		if err != nil {
+			return "", err
+		}
+		if line != nil {
+			return hashRecord(line), nil
+		}
+	}
+	return GenesisHash, nil
+}
+
+// lastLine возвращает последнюю непустую строку файла или nil, если файл пуст.
+func lastLine(path string) ([]byte, error) {
+	r, err := openSegment(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer r.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return last, nil
+}
+
+// hashRecord возвращает SHA-256 записи без завершающего перевода строки.
+func hashRecord(record []byte) string {
+	sum := sha256.Sum256(record)
+	return hex.EncodeToString(sum[:])
+}
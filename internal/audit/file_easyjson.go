@@ -0,0 +1,304 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package audit
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson8ceb9162DecodeGithubComHtrandevMetricsInternalAudit(in *jlexer.Lexer, out *chainRecord) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "prev_hash":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.PrevHash = string(in.String())
+			}
+		case "ts":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Timestamp = int64(in.Int64())
+			}
+		case "metrics":
+			if in.IsNull() {
+				in.Skip()
+				out.Metrics = nil
+			} else {
+				in.Delim('[')
+				if out.Metrics == nil {
+					if !in.IsDelim(']') {
+						out.Metrics = make([]string, 0, 4)
+					} else {
+						out.Metrics = []string{}
+					}
+				} else {
+					out.Metrics = (out.Metrics)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v1 = string(in.String())
+					}
+					out.Metrics = append(out.Metrics, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "ip_address":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.IP = string(in.String())
+			}
+		case "agent":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Agent = string(in.String())
+			}
+		case "transport":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Transport = string(in.String())
+			}
+		case "request_id":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.RequestID = string(in.String())
+			}
+		case "values":
+			if in.IsNull() {
+				in.Skip()
+				out.Values = nil
+			} else {
+				in.Delim('[')
+				if out.Values == nil {
+					if !in.IsDelim(']') {
+						out.Values = make([]MetricValue, 0, 1)
+					} else {
+						out.Values = []MetricValue{}
+					}
+				} else {
+					out.Values = (out.Values)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 MetricValue
+					easyjson8ceb9162DecodeGithubComHtrandevMetricsInternalAudit1(in, &v2)
+					out.Values = append(out.Values, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "outcome":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Outcome = string(in.String())
+			}
+		case "error":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Error = string(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8ceb9162EncodeGithubComHtrandevMetricsInternalAudit(out *jwriter.Writer, in chainRecord) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"prev_hash\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.PrevHash))
+	}
+	{
+		const prefix string = ",\"ts\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Timestamp))
+	}
+	{
+		const prefix string = ",\"metrics\":"
+		out.RawString(prefix)
+		if in.Metrics == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v3, v4 := range in.Metrics {
+				if v3 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v4))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"ip_address\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	if in.Agent != "" {
+		const prefix string = ",\"agent\":"
+		out.RawString(prefix)
+		out.String(string(in.Agent))
+	}
+	if in.Transport != "" {
+		const prefix string = ",\"transport\":"
+		out.RawString(prefix)
+		out.String(string(in.Transport))
+	}
+	if in.RequestID != "" {
+		const prefix string = ",\"request_id\":"
+		out.RawString(prefix)
+		out.String(string(in.RequestID))
+	}
+	if len(in.Values) != 0 {
+		const prefix string = ",\"values\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Values {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				easyjson8ceb9162EncodeGithubComHtrandevMetricsInternalAudit1(out, v6)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Outcome != "" {
+		const prefix string = ",\"outcome\":"
+		out.RawString(prefix)
+		out.String(string(in.Outcome))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v chainRecord) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson8ceb9162EncodeGithubComHtrandevMetricsInternalAudit(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v chainRecord) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson8ceb9162EncodeGithubComHtrandevMetricsInternalAudit(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *chainRecord) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson8ceb9162DecodeGithubComHtrandevMetricsInternalAudit(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *chainRecord) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson8ceb9162DecodeGithubComHtrandevMetricsInternalAudit(l, v)
+}
+func easyjson8ceb9162DecodeGithubComHtrandevMetricsInternalAudit1(in *jlexer.Lexer, out *MetricValue) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "name":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Name = string(in.String())
+			}
+		case "type":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Type = string(in.String())
+			}
+		case "value":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Value = string(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8ceb9162EncodeGithubComHtrandevMetricsInternalAudit1(out *jwriter.Writer, in MetricValue) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.String(string(in.Value))
+	}
+	out.RawByte('}')
+}
@@ -3,11 +3,14 @@ package audit
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 
@@ -33,7 +36,8 @@ func (s *FileAuditorSuite) SetupSuite() {
 	s.logger, err = logger.NewZapLogger("debug")
 	s.Require().NoError(err)
 
-	s.fileAudit = NewFile(s.id, s.file, s.logger)
+	s.fileAudit, err = NewFile(s.id, s.file, s.logger)
+	s.Require().NoError(err)
 }
 
 func (s *FileAuditorSuite) TearDownSuite() {
@@ -74,3 +78,172 @@ func (s *FileAuditorSuite) TestUpdate() {
 	s.Require().NoError(err)
 	s.Require().Equal(string(b)+"\n", string(data))
 }
+
+// openAudit открывает файл аудита в каталоге теста.
+func openAudit(t *testing.T, path string, opts ...FileOption) *FileAudit {
+	t.Helper()
+
+	f, err := os.OpenFile(path, fileFlag, fileMode)
+	require.NoError(t, err)
+
+	fa, err := NewFile(uuid.New(), f, nil, opts...)
+	require.NoError(t, err)
+	return fa
+}
+
+func TestFileAuditRotation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       []FileOption
+		expected   int
+		compressed bool
+	}{
+		{
+			name:     "by size",
+			opts:     []FileOption{WithRotation(100, 0)},
+			expected: 9,
+		},
+		{
+			name:     "with retention",
+			opts:     []FileOption{WithRotation(100, 0), WithRetention(3)},
+			expected: 3,
+		},
+		{
+			name:       "compressed",
+			opts:       []FileOption{WithRotation(100, 0), WithCompression(), WithRetention(2)},
+			expected:   2,
+			compressed: true,
+		},
+		{
+			name:     "disabled",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.log")
+			fa := openAudit(t, path, tc.opts...)
+
+			// каждая запись около 60 байт, в файл помещается одна запись
+			for i := 0; i < 10; i++ {
+				fa.Update(context.Background(), AuditInfo{Timestamp: int64(i), Metrics: []string{"metric"}, IP: "127.0.0.1"})
+			}
+			require.NoError(t, fa.Close())
+
+			segments, err := Segments(path)
+			require.NoError(t, err)
+			require.Len(t, segments, tc.expected)
+			for _, segment := range segments {
+				require.Equal(t, tc.compressed, strings.HasSuffix(segment, gzipExt))
+			}
+
+			line, err := lastLine(path)
+			require.NoError(t, err)
+			var info AuditInfo
+			require.NoError(t, easyjson.Unmarshal(line, &info))
+			require.Equal(t, int64(9), info.Timestamp)
+		})
+	}
+}
+
+func TestFileAuditRotationByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	fa := openAudit(t, path, WithRotation(0, time.Minute))
+
+	fa.Update(context.Background(), AuditInfo{Timestamp: 1})
+	fa.openedAt = time.Now().Add(-time.Hour)
+	fa.Update(context.Background(), AuditInfo{Timestamp: 2})
+	require.NoError(t, fa.Close())
+
+	segments, err := Segments(path)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+}
+
+func TestFileAuditHashChain(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+
+	opts := []FileOption{WithHashChain(), WithRotation(200, 0), WithCompression()}
+	fa := openAudit(t, path, opts...)
+	for i := 0; i < 5; i++ {
+		fa.Update(context.Background(), AuditInfo{Timestamp: int64(i), Metrics: []string{"metric"}})
+	}
+	require.NoError(t, fa.Close())
+
+	// цепочка продолжается после перезапуска
+	fa = openAudit(t, path, opts...)
+	for i := 5; i < 10; i++ {
+		fa.Update(context.Background(), AuditInfo{Timestamp: int64(i), Metrics: []string{"metric"}})
+	}
+	head := fa.prevHash
+	require.NoError(t, fa.Close())
+
+	segments, err := Segments(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, segments)
+
+	res, err := VerifyChain(append(segments, path)...)
+	require.NoError(t, err)
+	require.Equal(t, 10, res.Records)
+	require.Equal(t, head, res.Head)
+	require.True(t, res.Genesis)
+}
+
+func TestVerifyChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	fa := openAudit(t, path, WithHashChain())
+	for i := 0; i < 4; i++ {
+		fa.Update(context.Background(), AuditInfo{Timestamp: int64(i), IP: "10.0.0.1"})
+	}
+	require.NoError(t, fa.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+	require.Len(t, lines, 4)
+
+	testCases := []struct {
+		name         string
+		content      string
+		expectedLine int
+	}{
+		{
+			name:    "valid",
+			content: string(data),
+		},
+		{
+			name:         "edited line",
+			content:      lines[0] + strings.Replace(lines[1], "10.0.0.1", "10.0.0.2", 1) + lines[2] + lines[3],
+			expectedLine: 3,
+		},
+		{
+			name:         "deleted line",
+			content:      lines[0] + lines[2] + lines[3],
+			expectedLine: 2,
+		},
+		{
+			name:         "malformed line",
+			content:      lines[0] + "{\n" + lines[2] + lines[3],
+			expectedLine: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), "audit.log")
+			require.NoError(t, os.WriteFile(tampered, []byte(tc.content), 0600))
+
+			_, err := VerifyChain(tampered)
+			if tc.expectedLine == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var chainErr *ChainError
+			require.ErrorAs(t, err, &chainErr)
+			require.Equal(t, tc.expectedLine, chainErr.Line)
+		})
+	}
+}
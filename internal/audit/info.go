@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// NewInfo возвращает событие записи метрик с текущим временем, значениями и результатом.
+// Ненулевая ошибка storeErr означает, что метрики не сохранены.
+// Транспорт и данные клиента заполняет вызывающая сторона.
+func NewInfo(metrics []model.MetricDto, storeErr error) AuditInfo {
+	info := AuditInfo{
+		Timestamp: time.Now().Unix(),
+		Metrics:   make([]string, 0, len(metrics)),
+		Values:    make([]MetricValue, 0, len(metrics)),
+		Outcome:   OutcomeSuccess,
+	}
+	for _, metric := range metrics {
+		info.Metrics = append(info.Metrics, metric.Name)
+		info.Values = append(info.Values, MetricValue{
+			Name:  metric.Key(),
+			Type:  metric.Value.Type.String(),
+			Value: metric.Value.String(),
+		})
+	}
+
+	if storeErr != nil {
+		info.Outcome = OutcomeError
+		info.Error = storeErr.Error()
+	}
+	return info
+}
@@ -3,44 +3,151 @@ package audit
 import (
 	"context"
 	"errors"
-	"io"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
 	"github.com/mailru/easyjson"
 	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/pkg/retry"
+	"github.com/htrandev/metrics/pkg/sign"
 )
 
 var _ Observer = (*URLAudit)(nil)
 
+const (
+	// DefaultURLMaxRetry количество повторных отправок пакета по умолчанию.
+	DefaultURLMaxRetry = 3
+	// DefaultURLMinBackoff начальная задержка перед повторной отправкой.
+	DefaultURLMinBackoff = 500 * time.Millisecond
+	// DefaultURLMaxBackoff максимальная задержка перед повторной отправкой.
+	DefaultURLMaxBackoff = 30 * time.Second
+)
+
 // URLAudit реализует Observer для отправки событий по HTTP на указанный URL.
+//
+// События накапливаются в пакеты по размеру и интервалу и отправляются JSON-массивом.
+// Без пакетирования каждое событие отправляется JSON-объектом.
+// Неудачные отправки повторяются с экспоненциальной задержкой,
+// события, для которых попытки исчерпаны, записываются в dead-letter файл.
 type URLAudit struct {
 	id     uuid.UUID
 	url    string
 	client *resty.Client
+	opts   urlOptions
+
+	mu    sync.Mutex
+	batch []AuditInfo
+
+	// sendMu сохраняет порядок отправки пакетов.
+	sendMu sync.Mutex
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
 
 	logger *zap.Logger
 }
 
+type urlOptions struct {
+	batchSize     int
+	flushInterval time.Duration
+
+	maxRetry   int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	deadLetter string
+
+	keyID string
+	key   sign.Signature
+}
+
+// URLOption настраивает URLAudit.
+type URLOption func(*urlOptions)
+
+// WithBatch задает максимальный размер пакета и интервал отправки неполного пакета.
+// При нулевом интервале неполный пакет отправляется только при закрытии.
+func WithBatch(size int, interval time.Duration) URLOption {
+	return func(o *urlOptions) {
+		if size > 0 {
+			o.batchSize = size
+		}
+		o.flushInterval = interval
+	}
+}
+
+// WithRetry задает количество повторных отправок и границы задержки между ними.
+func WithRetry(maxRetry int, minBackoff, maxBackoff time.Duration) URLOption {
+	return func(o *urlOptions) {
+		o.maxRetry = maxRetry
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+		if maxBackoff > 0 {
+			o.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithDeadLetter задает путь к файлу для событий, которые не удалось доставить.
+func WithDeadLetter(path string) URLOption {
+	return func(o *urlOptions) {
+		o.deadLetter = path
+	}
+}
+
+// WithSigning включает подпись отправляемых пакетов ключом key с идентификатором keyID.
+// Получатель проверяет подпись по заголовкам sign.Stamp.
+func WithSigning(keyID string, key sign.Signature) URLOption {
+	return func(o *urlOptions) {
+		o.keyID = keyID
+		o.key = key
+	}
+}
+
 // NewURL возвращает новый экземпляр URLAudit.
-func NewURL(id uuid.UUID, url string, client *resty.Client, l *zap.Logger) *URLAudit {
+func NewURL(id uuid.UUID, url string, client *resty.Client, l *zap.Logger, opts ...URLOption) *URLAudit {
 	if l == nil {
 		l = zap.NewNop()
 	}
-	l.With(zap.String("scope", "urlAudit"))
+	l = l.With(zap.String("scope", "urlAudit"))
 
 	if client == nil {
 		client = resty.New().
 			SetTimeout(30 * time.Second)
 	}
 
-	return &URLAudit{
+	u := &URLAudit{
 		id:     id,
 		url:    url,
 		client: client,
+		opts: urlOptions{
+			batchSize:  1,
+			maxRetry:   DefaultURLMaxRetry,
+			minBackoff: DefaultURLMinBackoff,
+			maxBackoff: DefaultURLMaxBackoff,
+		},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
 		logger: l,
 	}
+	for _, opt := range opts {
+		opt(&u.opts)
+	}
+
+	if u.opts.flushInterval > 0 && u.opts.batchSize > 1 {
+		go u.flushLoop()
+	} else {
+		close(u.done)
+	}
+	return u
 }
 
 // GetID возвращает уникальный идентификатор URLAudit.
@@ -48,23 +155,186 @@ func (u *URLAudit) GetID() string {
 	return u.id.String()
 }
 
-// Update сериализует полученную информацию и отправляет POST-апрос на указанный URL.
+// Update добавляет событие в пакет и отправляет пакет, если он заполнен.
 func (u *URLAudit) Update(ctx context.Context, info AuditInfo) {
-	u.logger.Debug("send info to url")
-	b, err := easyjson.Marshal(info)
+	u.mu.Lock()
+	u.batch = append(u.batch, info)
+	var batch []AuditInfo
+	if len(u.batch) >= u.opts.batchSize {
+		batch = u.take()
+	}
+	u.mu.Unlock()
+
+	if batch != nil {
+		u.send(ctx, batch)
+	}
+}
+
+// Flush отправляет накопленный неполный пакет.
+func (u *URLAudit) Flush(ctx context.Context) {
+	u.mu.Lock()
+	batch := u.take()
+	u.mu.Unlock()
+
+	if batch != nil {
+		u.send(ctx, batch)
+	}
+}
+
+// Close останавливает отправку по интервалу и отправляет накопленный пакет.
+// Прерванные повторные отправки записываются в dead-letter файл.
+func (u *URLAudit) Close() error {
+	u.closeOnce.Do(func() {
+		close(u.stop)
+	})
+	<-u.done
+	u.Flush(context.Background())
+	return nil
+}
+
+// take возвращает накопленный пакет и начинает новый. Вызывается под mu.
+func (u *URLAudit) take() []AuditInfo {
+	if len(u.batch) == 0 {
+		return nil
+	}
+	batch := u.batch
+	u.batch = nil
+	return batch
+}
+
+func (u *URLAudit) flushLoop() {
+	defer close(u.done)
+
+	ticker := time.NewTicker(u.opts.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-u.stop:
+			return
+		case <-ticker.C:
+			u.Flush(context.Background())
+		}
+	}
+}
+
+// send отправляет пакет, повторяя неудачные попытки.
+// Если попытки исчерпаны, пакет записывается в dead-letter файл.
+func (u *URLAudit) send(ctx context.Context, batch []AuditInfo) {
+	u.sendMu.Lock()
+	defer u.sendMu.Unlock()
+
+	body, err := u.marshal(batch)
 	if err != nil {
-		u.logger.Error("marshal audit info", zap.Error(err))
+		u.logger.Error("marshal audit batch", zap.Error(err))
 		return
 	}
 
+	for attempt := 0; ; attempt++ {
+		err = u.post(ctx, body)
+		if err == nil {
+			u.logger.Debug("audit batch sent", zap.Int("events", len(batch)), zap.Int("attempt", attempt+1))
+			return
+		}
+		u.logger.Warn("send audit batch", zap.Int("attempt", attempt+1), zap.Error(err))
+
+		if errors.Is(err, retry.ErrPermanent) || attempt >= u.opts.maxRetry {
+			break
+		}
+
+		if !u.wait(ctx, retry.Backoff(attempt, u.opts.minBackoff, u.opts.maxBackoff)) {
+			break
+		}
+	}
+
+	u.deadLetter(batch, err)
+}
+
+// post отправляет тело пакета и проверяет код ответа.
+func (u *URLAudit) post(ctx context.Context, body []byte) error {
 	r := u.client.R().
-		SetBody(b).
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
 		SetContext(ctx)
 
-	if _, err := r.Post(u.url); err != nil {
-		if errors.Is(err, io.EOF) {
+	if u.opts.key != nil {
+		target, err := signTarget(u.url)
+		if err != nil {
+			return fmt.Errorf("%w: %w", retry.ErrPermanent, err)
+		}
+		st, err := sign.NewStamp(u.opts.keyID, u.opts.key, target, body)
+		if err != nil {
+			return fmt.Errorf("sign body: %w", err)
+		}
+		st.SetHeader(r.Header)
+	}
+
+	resp, err := r.Post(u.url)
+	if err != nil {
+		// в том числе io.EOF: соединение закрыто до ответа, доставка не подтверждена
+		return fmt.Errorf("post: %w", err)
+	}
+
+	return retry.CheckStatus(resp.StatusCode())
+}
+
+// wait ожидает задержку d. Возвращает false, если ожидание прервано
+// отменой контекста или закрытием URLAudit.
+func (u *URLAudit) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-u.stop:
+		return false
+	}
+}
+
+// deadLetter записывает недоставленные события в dead-letter файл.
+func (u *URLAudit) deadLetter(batch []AuditInfo, cause error) {
+	if u.opts.deadLetter == "" {
+		u.logger.Error("audit batch lost", zap.Int("events", len(batch)), zap.Error(cause))
+		return
+	}
+
+	f, err := os.OpenFile(u.opts.deadLetter, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		u.logger.Error("open dead letter file", zap.Int("events", len(batch)), zap.Error(err))
+		return
+	}
+	defer f.Close()
+
+	for _, info := range batch {
+		b, err := easyjson.Marshal(info)
+		if err != nil {
+			u.logger.Error("marshal audit info", zap.Error(err))
+			continue
+		}
+		if _, err := f.Write(append(b, '\n')); err != nil {
+			u.logger.Error("write dead letter", zap.Error(err))
 			return
 		}
-		u.logger.Error("send request to url auditor", zap.Error(err))
 	}
+	u.logger.Warn("audit batch written to dead letter file", zap.Int("events", len(batch)), zap.Error(cause))
+}
+
+// marshal сериализует пакет: без пакетирования событие объектом, иначе массивом.
+func (u *URLAudit) marshal(batch []AuditInfo) ([]byte, error) {
+	if u.opts.batchSize == 1 {
+		return easyjson.Marshal(batch[0])
+	}
+	return easyjson.Marshal(AuditBatch(batch))
+}
+
+// signTarget возвращает подписываемую цель запроса на адрес rawURL.
+func signTarget(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %w", err)
+	}
+	return http.MethodPost + " " + parsed.RequestURI(), nil
 }
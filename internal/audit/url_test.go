@@ -5,16 +5,23 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/google/uuid"
 	easyjson "github.com/mailru/easyjson"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 
 	"github.com/htrandev/metrics/pkg/logger"
+	"github.com/htrandev/metrics/pkg/sign"
 )
 
 type URLAuditorSuite struct {
@@ -84,3 +91,162 @@ func (s *URLAuditorSuite) TestUpdate() {
 	s.Require().Equal(string(expectedJSON), string(gotBody))
 
 }
+
+func TestURLAuditBatch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches []AuditBatch
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch AuditBatch
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, easyjson.Unmarshal(body, &batch))
+
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	u := NewURL(uuid.New(), srv.URL, nil, nil, WithBatch(3, 20*time.Millisecond))
+
+	for i := 0; i < 4; i++ {
+		u.Update(context.Background(), AuditInfo{Timestamp: int64(i), Metrics: []string{"m"}})
+	}
+
+	// полный пакет отправляется сразу, неполный - по интервалу
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(batches) == 2
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, u.Close())
+
+	require.Len(t, batches[0], 3)
+	require.Equal(t, int64(3), batches[1][0].Timestamp)
+}
+
+func TestURLAuditRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead.log")
+	u := NewURL(uuid.New(), srv.URL, nil, nil,
+		WithRetry(3, time.Millisecond, 5*time.Millisecond),
+		WithDeadLetter(deadLetter),
+	)
+	u.Update(context.Background(), AuditInfo{Timestamp: 1})
+	require.NoError(t, u.Close())
+
+	require.Equal(t, int32(3), calls.Load())
+	require.NoFileExists(t, deadLetter)
+}
+
+func TestURLAuditRetryEOF(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// закрываем соединение без ответа
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead.log")
+	u := NewURL(uuid.New(), srv.URL, nil, nil,
+		WithRetry(3, time.Millisecond, 5*time.Millisecond),
+		WithDeadLetter(deadLetter),
+	)
+	u.Update(context.Background(), AuditInfo{Timestamp: 1})
+	require.NoError(t, u.Close())
+
+	require.Equal(t, int32(2), calls.Load())
+	require.NoFileExists(t, deadLetter)
+}
+
+func TestURLAuditDeadLetter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		code          int
+		expectedCalls int32
+	}{
+		{
+			name:          "retries exhausted",
+			code:          http.StatusInternalServerError,
+			expectedCalls: 3,
+		},
+		{
+			name:          "permanent error",
+			code:          http.StatusBadRequest,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tc.code)
+			}))
+			defer srv.Close()
+
+			deadLetter := filepath.Join(t.TempDir(), "dead.log")
+			u := NewURL(uuid.New(), srv.URL, nil, nil,
+				WithBatch(2, 0),
+				WithRetry(2, time.Millisecond, 5*time.Millisecond),
+				WithDeadLetter(deadLetter),
+			)
+			u.Update(context.Background(), AuditInfo{Timestamp: 1})
+			u.Update(context.Background(), AuditInfo{Timestamp: 2})
+			require.NoError(t, u.Close())
+
+			require.Equal(t, tc.expectedCalls, calls.Load())
+
+			b, err := os.ReadFile(deadLetter)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			require.Len(t, lines, 2)
+
+			var info AuditInfo
+			require.NoError(t, easyjson.Unmarshal([]byte(lines[1]), &info))
+			require.Equal(t, int64(2), info.Timestamp)
+		})
+	}
+}
+
+func TestURLAuditSigning(t *testing.T) {
+	key := sign.Signature("audit-secret")
+	verifier := sign.NewVerifier(sign.Keyring{"audit": key}, time.Minute)
+
+	var verifyErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		st, ok, err := sign.StampFromHeader(r.Header)
+		require.NoError(t, err)
+		require.True(t, ok)
+		verifyErr = verifier.Verify(st, r.Method+" "+r.RequestURI, body)
+	}))
+	defer srv.Close()
+
+	u := NewURL(uuid.New(), srv.URL+"/audit?source=metrics", nil, nil, WithSigning("audit", key))
+	u.Update(context.Background(), AuditInfo{Timestamp: 1})
+	require.NoError(t, u.Close())
+
+	require.NoError(t, verifyErr)
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/mailru/easyjson"
+)
+
+// maxRecordSize максимальный размер записи аудита при чтении файла.
+const maxRecordSize = 1 << 20
+
+// ChainError описывает нарушение цепочки хэшей в файле аудита.
+type ChainError struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// ChainResult результат проверки цепочки хэшей.
+type ChainResult struct {
+	// Records количество проверенных записей.
+	Records int
+	// Head хэш последней записи. Совпадение с хэшем, записанным в журнал сервера
+	// при ротации или остановке, подтверждает, что конец цепочки не был обрезан.
+	Head string
+	// Genesis сообщает, что цепочка начинается с первой записи,
+	// а не с сегмента, оставшегося после удаления старых по retention.
+	Genesis bool
+}
+
+// VerifyChain проверяет цепочку хэшей в файлах аудита paths,
+// переданных от старых к новым. Сжатые сегменты распаковываются.
+// Измененная или удаленная запись нарушает ссылку следующей записи
+// и возвращается как *ChainError.
+func VerifyChain(paths ...string) (ChainResult, error) {
+	var res ChainResult
+
+	for _, path := range paths {
+		if err := verifyFile(path, &res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func verifyFile(path string, res *ChainResult) error {
+	r, err := openSegment(path)
+	if err != nil {
+		return fmt.Errorf("audit/VerifyChain: open %s: %w", path, err)
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		b := scanner.Bytes()
+		if len(b) == 0 {
+			return &ChainError{File: path, Line: line, Reason: "empty record"}
+		}
+
+		var rec chainRecord
+		if err := easyjson.Unmarshal(b, &rec); err != nil {
+			return &ChainError{File: path, Line: line, Reason: fmt.Sprintf("malformed record: %s", err)}
+		}
+		if rec.PrevHash == "" {
+			return &ChainError{File: path, Line: line, Reason: "record without prev_hash"}
+		}
+
+		switch {
+		case res.Records == 0:
+			// первая запись служит якорем цепочки
+			res.Genesis = rec.PrevHash == GenesisHash
+		case rec.PrevHash != res.Head:
+			return &ChainError{File: path, Line: line, Reason: "prev_hash mismatch: previous record was altered or removed"}
+		}
+
+		res.Head = hashRecord(b)
+		res.Records++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("audit/VerifyChain: read %s: %w", path, err)
+	}
+	return nil
+}
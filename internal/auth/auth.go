@@ -0,0 +1,102 @@
+// Package auth реализует аутентификацию агентов по API токенам.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scope определяет право доступа токена.
+type Scope string
+
+const (
+	// ScopeRead разрешает чтение метрик.
+	ScopeRead Scope = "read"
+	// ScopeWrite разрешает запись метрик.
+	ScopeWrite Scope = "write"
+	// ScopeAdmin разрешает все операции.
+	ScopeAdmin Scope = "admin"
+)
+
+var (
+	// ErrUnknownToken возвращается для неизвестного или отозванного токена.
+	ErrUnknownToken = errors.New("unknown token")
+	// ErrInvalidScope возвращается для неизвестного права доступа.
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// ParseScope разбирает право доступа.
+func ParseScope(s string) (Scope, error) {
+	switch sc := Scope(strings.ToLower(strings.TrimSpace(s))); sc {
+	case ScopeRead, ScopeWrite, ScopeAdmin:
+		return sc, nil
+	default:
+		return "", fmt.Errorf("auth: parse scope [%s]: %w", s, ErrInvalidScope)
+	}
+}
+
+// ParseScopes разбирает список прав доступа, разделенных запятыми.
+func ParseScopes(s string) ([]Scope, error) {
+	parts := strings.Split(s, ",")
+	scopes := make([]Scope, 0, len(parts))
+	for _, p := range parts {
+		sc, err := ParseScope(p)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, sc)
+	}
+	return scopes, nil
+}
+
+// Principal агент, которому выдан токен, и его права доступа.
+type Principal struct {
+	Agent  string
+	Scopes []Scope
+}
+
+// Allows сообщает, разрешена ли агенту операция с правом scope.
+// Право admin разрешает любые операции.
+func (p Principal) Allows(scope Scope) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// Registry предоставляет интерфейс поиска агента по токену.
+type Registry interface {
+	Lookup(ctx context.Context, token string) (Principal, error)
+}
+
+// HashToken возвращает SHA-256 хэш токена в шестнадцатеричном виде.
+// Реестры хранят и сравнивают только хэши токенов.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BearerToken извлекает токен из значения заголовка Authorization.
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+type ctxKey struct{}
+
+// NewContext возвращает контекст с аутентифицированным агентом.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext возвращает аутентифицированного агента из контекста.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	content := "# token agent scopes\n" +
+		"\n" +
+		"writer-token agent-1 write\n" +
+		"reader-token dashboard read\n" +
+		hashPrefix + HashToken("admin-token") + " ops admin,read\n"
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	r, err := NewFileRegistry(path)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		token         string
+		expected      Principal
+		expectedError error
+	}{
+		{
+			name:     "plain token",
+			token:    "writer-token",
+			expected: Principal{Agent: "agent-1", Scopes: []Scope{ScopeWrite}},
+		},
+		{
+			name:     "hashed token",
+			token:    "admin-token",
+			expected: Principal{Agent: "ops", Scopes: []Scope{ScopeAdmin, ScopeRead}},
+		},
+		{
+			name:          "unknown token",
+			token:         "other",
+			expectedError: ErrUnknownToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := r.Lookup(ctx, tc.token)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestFileRegistryInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing scopes", content: "token agent\n"},
+		{name: "unknown scope", content: "token agent write,delete\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			_, err := NewFileRegistry(path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	writer := Principal{Scopes: []Scope{ScopeWrite}}
+	require.True(t, writer.Allows(ScopeWrite))
+	require.False(t, writer.Allows(ScopeRead))
+	require.False(t, writer.Allows(ScopeAdmin))
+
+	admin := Principal{Scopes: []Scope{ScopeAdmin}}
+	require.True(t, admin.Allows(ScopeWrite))
+	require.True(t, admin.Allows(ScopeRead))
+}
+
+func TestBearerToken(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected string
+		ok       bool
+	}{
+		{header: "Bearer abc", expected: "abc", ok: true},
+		{header: "bearer  abc ", expected: "abc", ok: true},
+		{header: "Basic abc", ok: false},
+		{header: "Bearer ", ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			token, ok := BearerToken(tc.header)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.expected, token)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// hashPrefix отмечает в файле токен, заданный своим SHA-256 хэшем.
+const hashPrefix = "sha256:"
+
+// FileRegistry реестр токенов, загруженный из файла.
+//
+// Каждая непустая строка файла, не начинающаяся с #, описывает один токен:
+//
+//	<token> <agent> <scope>[,<scope>...]
+//
+// Вместо самого токена можно указать его хэш в виде sha256:<hex>.
+type FileRegistry struct {
+	tokens map[string]Principal
+}
+
+// NewFileRegistry загружает реестр токенов из файла path.
+func NewFileRegistry(path string) (*FileRegistry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth/newFileRegistry: open file: %w", err)
+	}
+	defer f.Close()
+
+	r := &FileRegistry{tokens: make(map[string]Principal)}
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("auth/newFileRegistry: line %d: expected token, agent and scopes", n)
+		}
+		scopes, err := ParseScopes(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("auth/newFileRegistry: line %d: %w", n, err)
+		}
+
+		hash, ok := strings.CutPrefix(fields[0], hashPrefix)
+		if !ok {
+			hash = HashToken(fields[0])
+		}
+		r.tokens[strings.ToLower(hash)] = Principal{Agent: fields[1], Scopes: scopes}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("auth/newFileRegistry: scan: %w", err)
+	}
+	return r, nil
+}
+
+// Lookup возвращает агента по токену.
+func (r *FileRegistry) Lookup(_ context.Context, token string) (Principal, error) {
+	p, ok := r.tokens[HashToken(token)]
+	if !ok {
+		return Principal{}, ErrUnknownToken
+	}
+	return p, nil
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresRegistry реестр токенов в таблице api_tokens.
+// В таблице хранятся только хэши токенов (см. HashToken).
+type PostgresRegistry struct {
+	db    *sql.DB
+	types *pgtype.Map
+}
+
+// NewPostgresRegistry возвращает реестр токенов поверх db.
+func NewPostgresRegistry(db *sql.DB) *PostgresRegistry {
+	return &PostgresRegistry{db: db, types: pgtype.NewMap()}
+}
+
+// Lookup возвращает агента по неотозванному токену.
+func (r *PostgresRegistry) Lookup(ctx context.Context, token string) (Principal, error) {
+	query := `SELECT agent, scopes
+		FROM api_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	;`
+
+	var (
+		agent  string
+		scopes []string
+	)
+	err := r.db.QueryRowContext(ctx, query, HashToken(token)).Scan(&agent, r.types.SQLScanner(&scopes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Principal{}, ErrUnknownToken
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("auth/lookup: query: %w", err)
+	}
+
+	p := Principal{Agent: agent, Scopes: make([]Scope, 0, len(scopes))}
+	for _, s := range scopes {
+		sc, err := ParseScope(s)
+		if err != nil {
+			return Principal{}, fmt.Errorf("auth/lookup: %w", err)
+		}
+		p.Scopes = append(p.Scopes, sc)
+	}
+	return p, nil
+}
@@ -16,10 +16,22 @@ type Agent struct {
 	LogLvl         string        `mapstructure:"LOG_LEVEL"`
 	MaxRetry       int           `mapstructure:"MAX_RETRY"`
 	Signature      string        `mapstructure:"SIGNATURE"`
+	SignatureKeyID string        `mapstructure:"SIGNATURE_KEY_ID"`
+	Token          string        `mapstructure:"TOKEN"`
 	RateLimit      int           `mapstructure:"RATE_LIMIT"`
 	PublicKeyFile  string        `mapstructure:"CRYPTO_KEY"`
 	UseGRPC        bool          `mapstructure:"USE_GRPC"`
 	GRPCAddr       string        `mapstructure:"GRPC_ADDRESS"`
+	TLS            bool          `mapstructure:"TLS"`
+	TLSCertFile    string        `mapstructure:"TLS_CERT"`
+	TLSKeyFile     string        `mapstructure:"TLS_KEY"`
+	TLSCAFile      string        `mapstructure:"TLS_CA"`
+	TLSServerName  string        `mapstructure:"TLS_SERVER_NAME"`
+}
+
+// UseTLS сообщает, нужно ли подключаться к серверу по TLS.
+func (a Agent) UseTLS() bool {
+	return a.TLS || a.TLSCAFile != "" || a.TLSCertFile != ""
 }
 
 // GetAgentConfig return a server configuration.
@@ -61,25 +73,39 @@ func parseAgentFlags(v *viper.Viper) map[string]any {
 		logLvl        = pflag.String("lvl", "debug", "log level")
 		maxRetry      = pflag.Int("maxRetry", 3, "max number of retries")
 		signature     = pflag.String("k", "", "secret key")
+		signatureKey  = pflag.String("signature-key-id", "default", "id of the secret key")
+		token         = pflag.String("token", "", "api token of the agent")
 		rateLimit     = pflag.Int("l", 3, "agent rate limit")
 		publicKeyFile = pflag.String("crypto-key", "", "path to public key file")
 		useGRPC       = pflag.Bool("user-grpc", false, "send metrics using grpc")
 		grpcAddr      = pflag.String("grpc-addr", "localhost:8090", "grpc server address")
+		useTLS        = pflag.Bool("tls", false, "connect to server using tls")
+		tlsCertFile   = pflag.String("tls-cert", "", "path to agent tls certificate")
+		tlsKeyFile    = pflag.String("tls-key", "", "path to agent tls private key")
+		tlsCAFile     = pflag.String("tls-ca", "", "path to ca certificate to verify server")
+		tlsServerName = pflag.String("tls-server-name", "", "expected server name in server certificate")
 	)
 
 	pflag.Parse()
 
 	flagVals := map[string]any{
-		"ADDRESS":         *addr,
-		"REPORT_INTERVAL": *report,
-		"POLL_INTERVAL":   *poll,
-		"LOG_LEVEL":       *logLvl,
-		"MAX_RETRY":       *maxRetry,
-		"SIGNATURE":       *signature,
-		"RATE_LIMIT":      *rateLimit,
-		"CRYPTO_KEY":      *publicKeyFile,
-		"USE_GRPC":        *useGRPC,
-		"GRPC_ADDRESS":    *grpcAddr,
+		"ADDRESS":          *addr,
+		"REPORT_INTERVAL":  *report,
+		"POLL_INTERVAL":    *poll,
+		"LOG_LEVEL":        *logLvl,
+		"MAX_RETRY":        *maxRetry,
+		"SIGNATURE":        *signature,
+		"SIGNATURE_KEY_ID": *signatureKey,
+		"TOKEN":            *token,
+		"RATE_LIMIT":       *rateLimit,
+		"CRYPTO_KEY":       *publicKeyFile,
+		"USE_GRPC":         *useGRPC,
+		"GRPC_ADDRESS":     *grpcAddr,
+		"TLS":              *useTLS,
+		"TLS_CERT":         *tlsCertFile,
+		"TLS_KEY":          *tlsKeyFile,
+		"TLS_CA":           *tlsCAFile,
+		"TLS_SERVER_NAME":  *tlsServerName,
 	}
 	for key, val := range flagVals {
 		if val != nil {
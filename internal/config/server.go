@@ -25,6 +25,48 @@ type Server struct {
 	PrivateKeyFile string        `mapstructure:"CRYPTO_KEY"`
 	TrustedSubnet  string        `mapstructure:"TRUSTED_SUBNET"`
 	GRPCAddr       string        `mapstructure:"GRPC_ADDRESS"`
+	HistorySize    int           `mapstructure:"HISTORY_SIZE"`
+	HistoryRetain  time.Duration `mapstructure:"HISTORY_RETENTION"`
+	CounterSuffix  []string      `mapstructure:"REMOTE_WRITE_COUNTER_SUFFIXES"`
+	StatsdAddr     string        `mapstructure:"STATSD_ADDRESS"`
+	StatsdFlush    time.Duration `mapstructure:"STATSD_FLUSH_INTERVAL"`
+	GraphiteAddr   string        `mapstructure:"GRAPHITE_ADDRESS"`
+	InfluxAddr     string        `mapstructure:"INFLUX_ADDRESS"`
+	BatchTTL       time.Duration `mapstructure:"BATCH_TTL"`
+	TLSCertFile    string        `mapstructure:"TLS_CERT"`
+	TLSKeyFile     string        `mapstructure:"TLS_KEY"`
+	TLSCAFile      string        `mapstructure:"TLS_CA"`
+	TLSClientAuth  bool          `mapstructure:"TLS_CLIENT_AUTH"`
+	CertIdentity   bool          `mapstructure:"TLS_CERT_IDENTITY"`
+	UnsignedIngest bool          `mapstructure:"UNSIGNED_INGEST"`
+	SignatureKeys  []string      `mapstructure:"SIGNATURE_KEYS"`
+	SignWindow     time.Duration `mapstructure:"SIGNATURE_WINDOW"`
+	SignLegacy     string        `mapstructure:"SIGNATURE_LEGACY_UNTIL"`
+	TokensFile     string        `mapstructure:"TOKENS_FILE"`
+	TokensDB       bool          `mapstructure:"TOKENS_DB"`
+	TrustedProxies []string      `mapstructure:"TRUSTED_PROXIES"`
+	StrictIP       bool          `mapstructure:"TRUSTED_SUBNET_STRICT"`
+	AuditQueue     int           `mapstructure:"AUDIT_QUEUE_SIZE"`
+	AuditOverflow  string        `mapstructure:"AUDIT_OVERFLOW"`
+	AuditBatch     int           `mapstructure:"AUDIT_URL_BATCH_SIZE"`
+	AuditFlush     time.Duration `mapstructure:"AUDIT_URL_FLUSH_INTERVAL"`
+	AuditRetry     int           `mapstructure:"AUDIT_URL_MAX_RETRY"`
+	AuditDeadFile  string        `mapstructure:"AUDIT_URL_DEAD_LETTER"`
+	AuditSecret    string        `mapstructure:"AUDIT_URL_SECRET"`
+	AuditMaxSize   int64         `mapstructure:"AUDIT_FILE_MAX_SIZE"`
+	AuditMaxAge    time.Duration `mapstructure:"AUDIT_FILE_MAX_AGE"`
+	AuditCompress  bool          `mapstructure:"AUDIT_FILE_COMPRESS"`
+	AuditRetention int           `mapstructure:"AUDIT_FILE_RETENTION"`
+	AuditChain     bool          `mapstructure:"AUDIT_FILE_HASH_CHAIN"`
+	AlertRules     string        `mapstructure:"ALERT_RULES"`
+	AlertInterval  time.Duration `mapstructure:"ALERT_INTERVAL"`
+	AlertRetention time.Duration `mapstructure:"ALERT_RESOLVED_RETENTION"`
+	NotifyConfig   string        `mapstructure:"NOTIFY_CONFIG"`
+	AlertState     string        `mapstructure:"ALERT_STATE_FILE"`
+	SilencesFile   string        `mapstructure:"SILENCES_FILE"`
+	SilencesDB     bool          `mapstructure:"SILENCES_DB"`
+	RecordingRules string        `mapstructure:"RECORDING_RULES"`
+	RecordInterval time.Duration `mapstructure:"RECORDING_INTERVAL"`
 }
 
 // GetServerConfig return a server configuration.
@@ -73,27 +115,111 @@ func parseServerFlags(v *viper.Viper) map[string]any {
 		auditURL       = pflag.String("audit-url", "", "url to send audit")
 		pprofAddr      = pflag.String("pprof-addr", "localhost:6060", "pprof address")
 		privateKeyFile = pflag.String("crypto-key", "", "path to private key file")
-		trustedSubnet  = pflag.String("t", "", "trusted subnet")
+		trustedSubnet  = pflag.String("t", "", "trusted subnets, comma separated ipv4 and ipv6 cidrs")
 		grpcAddr       = pflag.String("grpc", "localhost:8090", "address to run grpc server")
+		historySize    = pflag.Int("history-size", 0, "max number of in-memory samples per series")
+		historyRetain  = pflag.Duration("history-retention", 0, "max age of in-memory samples")
+		statsdAddr     = pflag.String("statsd-addr", "", "udp address to receive statsd metrics")
+		statsdFlush    = pflag.Duration("statsd-flush-interval", 10*time.Second, "interval of storing aggregated statsd metrics")
+		graphiteAddr   = pflag.String("graphite-addr", "", "tcp address to receive graphite plaintext metrics")
+		influxAddr     = pflag.String("influx-addr", "", "tcp address to receive influxdb line protocol metrics")
+		batchTTL       = pflag.Duration("batch-ttl", time.Hour, "how long to remember ids of stored metric batches")
+		tlsCertFile    = pflag.String("tls-cert", "", "path to server tls certificate, enables https and grpc tls")
+		tlsKeyFile     = pflag.String("tls-key", "", "path to server tls private key")
+		tlsCAFile      = pflag.String("tls-ca", "", "path to ca certificate to verify agent certificates")
+		tlsClientAuth  = pflag.Bool("tls-client-auth", false, "require agent tls certificates")
+		certIdentity   = pflag.Bool("tls-cert-identity", false, "identify agents by certificate subject instead of X-Real-IP, implies tls-client-auth")
+		unsignedIngest = pflag.Bool("unsigned-ingest", false, "accept unsigned remote write, influx, graphite and otlp requests when tokens or trusted subnets are configured")
+		signatureKeys  = pflag.StringSlice("signature-keys", nil, "active signature keys in id:secret format, -k is added with id \"default\"")
+		signWindow     = pflag.Duration("signature-window", 5*time.Minute, "max allowed age of request signature")
+		signLegacy     = pflag.String("signature-legacy-until", "", "rfc3339 time until which legacy HashSHA256 signed and unsigned writes are accepted")
+		tokensFile     = pflag.String("tokens-file", "", "path to file with agent api tokens")
+		tokensDB       = pflag.Bool("tokens-db", false, "load agent api tokens from api_tokens table")
+		trustedProxies = pflag.StringSlice("trusted-proxies", nil, "cidrs of proxies trusted to set X-Forwarded-For")
+		strictIP       = pflag.Bool("trusted-subnet-strict", false, "deny requests with unresolvable client ip")
+		auditQueue     = pflag.Int("audit-queue-size", 1024, "max number of pending audit events per auditor")
+		auditOverflow  = pflag.String("audit-overflow", "drop", "audit queue overflow policy: drop or block")
+		auditBatch     = pflag.Int("audit-url-batch-size", 1, "max number of audit events sent to url in one request")
+		auditFlush     = pflag.Duration("audit-url-flush-interval", 5*time.Second, "interval of sending incomplete audit batch")
+		auditRetry     = pflag.Int("audit-url-max-retry", 3, "max number of audit batch resends")
+		auditDeadFile  = pflag.String("audit-url-dead-letter", "", "file path to save undelivered audit events")
+		auditSecret    = pflag.String("audit-url-secret", "", "secret key to sign audit requests")
+		auditMaxSize   = pflag.Int64("audit-file-max-size", 0, "rotate audit file when it exceeds size in bytes")
+		auditMaxAge    = pflag.Duration("audit-file-max-age", 0, "rotate audit file after interval")
+		auditCompress  = pflag.Bool("audit-file-compress", false, "gzip rotated audit files")
+		auditRetention = pflag.Int("audit-file-retention", 0, "number of rotated audit files to keep, 0 keeps all")
+		auditChain     = pflag.Bool("audit-file-hash-chain", false, "chain audit records with sha-256 of previous record")
+		alertRules     = pflag.String("alert-rules", "", "path to yaml file with alert rules")
+		alertInterval  = pflag.Duration("alert-interval", 15*time.Second, "interval of alert rules evaluation")
+		alertRetention = pflag.Duration("alert-resolved-retention", 15*time.Minute, "how long to list resolved alerts")
+		notifyConfig   = pflag.String("notify-config", "", "path to yaml file with alert notification receivers and routes")
+		alertState     = pflag.String("alert-state-file", "", "path to file to persist anomaly rule models")
+		silencesFile   = pflag.String("silences-file", "", "path to jsonl file to persist alert silences")
+		silencesDB     = pflag.Bool("silences-db", false, "persist alert silences in silences table")
+		recordingRules = pflag.String("recording-rules", "", "path to yaml file with recording rules")
+		recordInterval = pflag.Duration("recording-interval", 15*time.Second, "interval of recording rules evaluation")
+		counterSuffix  = pflag.StringSlice("remote-write-counter-suffix", []string{"_total"}, "name suffixes of remote write series stored as counters")
 	)
 	pflag.Parse()
 
 	// store flag values in a map for later merging
 	flagVals := map[string]any{
-		"ADDRESS":        *addr,
-		"LOG_LEVEL":      *logLvl,
-		"STORE_INTERVAL": *storeInterval,
-		"STORE_FILE":     *storeFilePath,
-		"RESTORE":        *restore,
-		"DATABASE_DSN":   *databaseDsn,
-		"MAX_RETRY":      *maxRetry,
-		"SIGNATURE":      *signature,
-		"AUDIT_FILE":     *auditFile,
-		"AUDIT_URL":      *auditURL,
-		"PPROF_ADDRESS":  *pprofAddr,
-		"CRYPTO_KEY":     *privateKeyFile,
-		"TRUSTED_SUBNET": *trustedSubnet,
-		"GRPC_ADDRESS":   *grpcAddr,
+		"ADDRESS":                       *addr,
+		"LOG_LEVEL":                     *logLvl,
+		"STORE_INTERVAL":                *storeInterval,
+		"STORE_FILE":                    *storeFilePath,
+		"RESTORE":                       *restore,
+		"DATABASE_DSN":                  *databaseDsn,
+		"MAX_RETRY":                     *maxRetry,
+		"SIGNATURE":                     *signature,
+		"AUDIT_FILE":                    *auditFile,
+		"AUDIT_URL":                     *auditURL,
+		"PPROF_ADDRESS":                 *pprofAddr,
+		"CRYPTO_KEY":                    *privateKeyFile,
+		"TRUSTED_SUBNET":                *trustedSubnet,
+		"GRPC_ADDRESS":                  *grpcAddr,
+		"HISTORY_SIZE":                  *historySize,
+		"HISTORY_RETENTION":             *historyRetain,
+		"REMOTE_WRITE_COUNTER_SUFFIXES": *counterSuffix,
+		"STATSD_ADDRESS":                *statsdAddr,
+		"STATSD_FLUSH_INTERVAL":         *statsdFlush,
+		"GRAPHITE_ADDRESS":              *graphiteAddr,
+		"INFLUX_ADDRESS":                *influxAddr,
+		"BATCH_TTL":                     *batchTTL,
+		"TLS_CERT":                      *tlsCertFile,
+		"TLS_KEY":                       *tlsKeyFile,
+		"TLS_CA":                        *tlsCAFile,
+		"TLS_CLIENT_AUTH":               *tlsClientAuth,
+		"TLS_CERT_IDENTITY":             *certIdentity,
+		"UNSIGNED_INGEST":               *unsignedIngest,
+		"SIGNATURE_KEYS":                *signatureKeys,
+		"SIGNATURE_WINDOW":              *signWindow,
+		"SIGNATURE_LEGACY_UNTIL":        *signLegacy,
+		"TOKENS_FILE":                   *tokensFile,
+		"TOKENS_DB":                     *tokensDB,
+		"TRUSTED_PROXIES":               *trustedProxies,
+		"TRUSTED_SUBNET_STRICT":         *strictIP,
+		"AUDIT_QUEUE_SIZE":              *auditQueue,
+		"AUDIT_OVERFLOW":                *auditOverflow,
+		"AUDIT_URL_BATCH_SIZE":          *auditBatch,
+		"AUDIT_URL_FLUSH_INTERVAL":      *auditFlush,
+		"AUDIT_URL_MAX_RETRY":           *auditRetry,
+		"AUDIT_URL_DEAD_LETTER":         *auditDeadFile,
+		"AUDIT_URL_SECRET":              *auditSecret,
+		"AUDIT_FILE_MAX_SIZE":           *auditMaxSize,
+		"AUDIT_FILE_MAX_AGE":            *auditMaxAge,
+		"AUDIT_FILE_COMPRESS":           *auditCompress,
+		"AUDIT_FILE_RETENTION":          *auditRetention,
+		"AUDIT_FILE_HASH_CHAIN":         *auditChain,
+		"ALERT_RULES":                   *alertRules,
+		"ALERT_INTERVAL":                *alertInterval,
+		"ALERT_RESOLVED_RETENTION":      *alertRetention,
+		"NOTIFY_CONFIG":                 *notifyConfig,
+		"ALERT_STATE_FILE":              *alertState,
+		"SILENCES_FILE":                 *silencesFile,
+		"SILENCES_DB":                   *silencesDB,
+		"RECORDING_RULES":               *recordingRules,
+		"RECORDING_INTERVAL":            *recordInterval,
 	}
 
 	for key, val := range flagVals {
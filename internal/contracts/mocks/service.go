@@ -7,6 +7,7 @@ package mock_contracts
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/htrandev/metrics/internal/model"
@@ -36,33 +37,43 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // Get mocks base method.
-func (m *MockService) Get(ctx context.Context, name string) (model.MetricDto, error) {
+func (m *MockService) Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, name)
+	varargs := []interface{}{ctx, name}
+	for _, a := range matchers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Get", varargs...)
 	ret0, _ := ret[0].(model.MetricDto)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockServiceMockRecorder) Get(ctx, name interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Get(ctx, name interface{}, matchers ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), ctx, name)
+	varargs := append([]interface{}{ctx, name}, matchers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), varargs...)
 }
 
 // GetAll mocks base method.
-func (m *MockService) GetAll(ctx context.Context) ([]model.MetricDto, error) {
+func (m *MockService) GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll", ctx)
+	varargs := []interface{}{ctx}
+	for _, a := range matchers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAll", varargs...)
 	ret0, _ := ret[0].([]model.MetricDto)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockServiceMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetAll(ctx interface{}, matchers ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockService)(nil).GetAll), ctx)
+	varargs := append([]interface{}{ctx}, matchers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockService)(nil).GetAll), varargs...)
 }
 
 // Ping mocks base method.
@@ -79,6 +90,26 @@ func (mr *MockServiceMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockService)(nil).Ping), ctx)
 }
 
+// Range mocks base method.
+func (m *MockService) Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...model.Matcher) ([]model.Series, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, name, from, to, step}
+	for _, a := range matchers {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Range", varargs...)
+	ret0, _ := ret[0].([]model.Series)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockServiceMockRecorder) Range(ctx, name, from, to, step interface{}, matchers ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, name, from, to, step}, matchers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockService)(nil).Range), varargs...)
+}
+
 // Store mocks base method.
 func (m *MockService) Store(ctx context.Context, metric *model.MetricDto) error {
 	m.ctrl.T.Helper()
@@ -93,6 +124,21 @@ func (mr *MockServiceMockRecorder) Store(ctx, metric interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockService)(nil).Store), ctx, metric)
 }
 
+// StoreBatch mocks base method.
+func (m *MockService) StoreBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreBatch", ctx, batchID, metrics)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StoreBatch indicates an expected call of StoreBatch.
+func (mr *MockServiceMockRecorder) StoreBatch(ctx, batchID, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreBatch", reflect.TypeOf((*MockService)(nil).StoreBatch), ctx, batchID, metrics)
+}
+
 // StoreMany mocks base method.
 func (m *MockService) StoreMany(ctx context.Context, metric []model.MetricDto) error {
 	m.ctrl.T.Helper()
@@ -2,18 +2,21 @@ package contracts
 
 import (
 	"context"
+	"time"
 
 	"github.com/htrandev/metrics/internal/model"
 )
 
 // Service предоставляет интерфейс взаимодействия с сервисом для работы с метриками.
 type Service interface {
-	Get(ctx context.Context, name string) (model.MetricDto, error)
-	GetAll(ctx context.Context) ([]model.MetricDto, error)
+	Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error)
+	GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error)
+	Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...model.Matcher) ([]model.Series, error)
 
 	Store(ctx context.Context, metric *model.MetricDto) error
 	StoreMany(ctx context.Context, metric []model.MetricDto) error
 	StoreManyWithRetry(ctx context.Context, metric []model.MetricDto) error
+	StoreBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error)
 
 	Ping(ctx context.Context) error
 }
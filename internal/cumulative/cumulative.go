@@ -0,0 +1,240 @@
+// Package cumulative преобразует накопленные значения счетчиков в приросты.
+//
+// Prometheus и OpenTelemetry передают счетчики накопленным значением, а хранилище
+// прибавляет переданное значение к сохраненному. Tracker запоминает последнее
+// полученное значение каждой серии и возвращает только прирост.
+// Сброс счетчика определяется по уменьшению значения.
+//
+// Для счетчиков с дельта-временностью Tracker переносит дробный остаток приростов
+// между запросами, см. Batch.Add.
+package cumulative
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// DefaultTTL время, после которого забывается серия без новых значений.
+const DefaultTTL = 24 * time.Hour
+
+// pruneInterval минимальный интервал между удалениями устаревших серий.
+const pruneInterval = time.Minute
+
+// Getter предоставляет доступ к сохраненным метрикам.
+type Getter interface {
+	Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error)
+}
+
+// series состояние одной серии.
+type series struct {
+	// last последнее полученное накопленное значение.
+	last float64
+	// owed прирост несохраненных пакетов, который вернет следующий Delta.
+	owed int64
+	// rest дробный остаток приростов дельта-счетчика, еще не учтенный в целых приростах.
+	rest float64
+	seen time.Time
+}
+
+// Tracker хранит последние накопленные значения счетчиков.
+//
+// Delta сразу запоминает полученное значение, поэтому конкурентные запросы с одной
+// серией не посчитают один и тот же прирост дважды. Прирост пакета, который
+// не удалось сохранить, возвращается вызовом Release и учитывается следующим значением серии.
+// Блокировка удерживается только на время вычисления прироста, но не на время сохранения.
+type Tracker struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	series map[string]*series
+	pruned time.Time
+}
+
+// Option настраивает Tracker.
+type Option func(*Tracker)
+
+// WithTTL задает время, после которого забывается серия без новых значений.
+func WithTTL(ttl time.Duration) Option {
+	return func(t *Tracker) {
+		if ttl > 0 {
+			t.ttl = ttl
+		}
+	}
+}
+
+// NewTracker создает пустой Tracker.
+func NewTracker(opts ...Option) *Tracker {
+	t := &Tracker{
+		ttl:    DefaultTTL,
+		now:    time.Now,
+		series: make(map[string]*series),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Batch накапливает приросты одного запроса.
+// Если пакет не был сохранен, его прирост возвращается в Tracker вызовом Release,
+// чтобы повторная отправка того же запроса не теряла прирост.
+type Batch struct {
+	t     *Tracker
+	g     Getter
+	delta map[string]int64
+	// rest изменение дробного остатка дельта-счетчиков, отменяется Release.
+	rest map[string]float64
+	done bool
+}
+
+// Begin начинает пакет.
+// Для счетчиков, которые еще не встречались, текущее значение запрашивается у g,
+// чтобы после перезапуска сервера не учитывать накопленное значение повторно.
+// g может быть nil.
+func (t *Tracker) Begin(g Getter) *Batch {
+	t.prune()
+	return &Batch{t: t, g: g, delta: make(map[string]int64), rest: make(map[string]float64)}
+}
+
+// Commit завершает сохраненный пакет.
+// После Commit или Release ничего не делает.
+func (t *Tracker) Commit(b *Batch) {
+	b.done = true
+}
+
+// Release возвращает прирост несохраненного пакета, его вернет следующий Delta серии.
+// Остатки дельта-счетчиков восстанавливаются: клиент повторит отправку тех же приростов.
+// После Commit или Release ничего не делает.
+func (t *Tracker) Release(b *Batch) {
+	if b.done {
+		return
+	}
+	b.done = true
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, d := range b.delta {
+		if s, ok := t.series[key]; ok {
+			s.owed += d
+		}
+	}
+	for key, r := range b.rest {
+		if s, ok := t.series[key]; ok {
+			s.rest -= r
+		}
+	}
+}
+
+// prune удаляет серии без новых значений дольше ttl.
+func (t *Tracker) prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if now.Sub(t.pruned) < pruneInterval {
+		return
+	}
+	t.pruned = now
+	for key, s := range t.series {
+		if now.Sub(s.seen) > t.ttl {
+			delete(t.series, key)
+		}
+	}
+}
+
+// Delta возвращает прирост счетчика metric при новом накопленном значении v.
+// Дробная часть накопленного значения переносится, прирост считается по целым частям.
+func (b *Batch) Delta(ctx context.Context, metric model.MetricDto, v float64) int64 {
+	if math.IsNaN(v) {
+		return 0
+	}
+
+	key := metric.Key()
+	t := b.t
+
+	t.mu.Lock()
+	s, ok := t.series[key]
+	t.mu.Unlock()
+	if !ok {
+		// сохраненное значение запрашивается без блокировки Tracker
+		base := b.baseline(ctx, metric, v)
+		t.mu.Lock()
+		if s, ok = t.series[key]; !ok {
+			s = &series{last: base}
+			t.series[key] = s
+		}
+		t.mu.Unlock()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	last := s.last
+	if v < last {
+		// счетчик сброшен, отсчитываем прирост от нуля
+		last = 0
+	}
+	d := int64(math.Floor(v)) - int64(math.Floor(last)) + s.owed
+	s.last = v
+	s.owed = 0
+	s.seen = t.now()
+	// серия могла быть удалена prune, пока запрашивалось сохраненное значение
+	t.series[key] = s
+
+	b.delta[key] += d
+	return d
+}
+
+// Add возвращает целую часть прироста v дельта-счетчика metric.
+// Дробный остаток запоминается и прибавляется к следующему приросту серии,
+// поэтому частые дробные приросты не теряются при округлении.
+// Остаток серии забывается вместе с серией, если она не обновлялась дольше ttl.
+func (b *Batch) Add(metric model.MetricDto, v float64) int64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0
+	}
+
+	key := metric.Key()
+	t := b.t
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.series[key]
+	if !ok {
+		s = &series{}
+		t.series[key] = s
+	}
+	sum := s.rest + v
+	d := int64(math.Trunc(sum))
+	rest := sum - float64(d)
+	b.rest[key] += rest - s.rest
+	s.rest = rest
+	s.seen = t.now()
+	return d
+}
+
+// baseline возвращает значение, от которого считается прирост впервые встреченного счетчика.
+// Если серия уже сохранена и ее значение не больше первого полученного, то прирост
+// считается от сохраненного значения. Если значение больше, то считается,
+// что счетчик был сброшен, и первое полученное значение не сохраняется.
+func (b *Batch) baseline(ctx context.Context, metric model.MetricDto, first float64) float64 {
+	if b.g == nil {
+		return 0
+	}
+
+	stored, err := b.g.Get(ctx, metric.Name, model.EqualMatchers(metric.Labels)...)
+	if err != nil || stored.Key() != metric.Key() || stored.Value.Type != model.TypeCounter {
+		return 0
+	}
+
+	if float64(stored.Value.Counter) > first {
+		return first
+	}
+	return float64(stored.Value.Counter)
+}
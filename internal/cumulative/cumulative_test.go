@@ -0,0 +1,202 @@
+package cumulative
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/internal/repository"
+)
+
+type mockGetter map[string]model.MetricDto
+
+func (g mockGetter) Get(_ context.Context, name string, _ ...model.Matcher) (model.MetricDto, error) {
+	m, ok := g[name]
+	if !ok {
+		return model.MetricDto{}, repository.ErrNotFound
+	}
+	return m, nil
+}
+
+func TestDelta(t *testing.T) {
+	ctx := context.Background()
+	metric := model.Counter("jobs", 0)
+
+	testCases := []struct {
+		name     string
+		getter   Getter
+		values   []float64
+		expected []int64
+	}{
+		{name: "new series", values: []float64{5, 7.5, 9}, expected: []int64{5, 2, 2}},
+		{name: "reset", values: []float64{5, 2}, expected: []int64{5, 2}},
+		{name: "stored value", getter: mockGetter{"jobs": model.Counter("jobs", 3)}, values: []float64{5}, expected: []int64{2}},
+		{name: "stored value after reset", getter: mockGetter{"jobs": model.Counter("jobs", 30)}, values: []float64{5, 6}, expected: []int64{0, 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := NewTracker()
+			for i, v := range tc.values {
+				b := tr.Begin(tc.getter)
+				require.Equal(t, tc.expected[i], b.Delta(ctx, metric, v))
+				tr.Commit(b)
+			}
+		})
+	}
+
+	t.Run("not committed", func(t *testing.T) {
+		tr := NewTracker()
+		for range 2 {
+			b := tr.Begin(nil)
+			require.Equal(t, int64(5), b.Delta(ctx, metric, 5))
+			tr.Release(b)
+		}
+	})
+}
+
+func TestAdd(t *testing.T) {
+	metric := model.Counter("jobs", 0)
+
+	testCases := []struct {
+		name     string
+		values   []float64
+		expected []int64
+	}{
+		{name: "integer", values: []float64{3, 2}, expected: []int64{3, 2}},
+		{name: "fractional", values: []float64{0.4, 0.4, 0.4, 0.8}, expected: []int64{0, 0, 1, 1}},
+		{name: "negative", values: []float64{1.5, -0.7, -0.9}, expected: []int64{1, 0, -1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := NewTracker()
+			for i, v := range tc.values {
+				b := tr.Begin(nil)
+				require.Equal(t, tc.expected[i], b.Add(metric, v))
+				tr.Commit(b)
+			}
+		})
+	}
+
+	t.Run("not committed", func(t *testing.T) {
+		tr := NewTracker()
+		b := tr.Begin(nil)
+		require.Equal(t, int64(0), b.Add(metric, 0.6))
+		tr.Commit(b)
+
+		// остаток несохраненного пакета отменяется, клиент повторит те же приросты
+		failed := tr.Begin(nil)
+		require.Equal(t, int64(1), failed.Add(metric, 0.6))
+		tr.Release(failed)
+
+		b = tr.Begin(nil)
+		require.Equal(t, int64(1), b.Add(metric, 0.6))
+		tr.Commit(b)
+		b = tr.Begin(nil)
+		require.Equal(t, int64(0), b.Add(metric, 0.1))
+		tr.Commit(b)
+	})
+}
+
+func TestConcurrentBatches(t *testing.T) {
+	ctx := context.Background()
+	metric := model.Counter("jobs", 0)
+	tr := NewTracker()
+
+	// каждый запрос передает то же накопленное значение,
+	// прирост должен быть учтен только один раз
+	var (
+		wg    sync.WaitGroup
+		total atomic.Int64
+	)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := tr.Begin(nil)
+			total.Add(b.Delta(ctx, metric, 5))
+			tr.Commit(b)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int64(5), total.Load())
+}
+
+func TestReleaseConcurrent(t *testing.T) {
+	ctx := context.Background()
+	metric := model.Counter("jobs", 0)
+	tr := NewTracker()
+
+	// первый пакет не сохранен, второй с тем же значением сохранен
+	failed := tr.Begin(nil)
+	require.Equal(t, int64(5), failed.Delta(ctx, metric, 5))
+	stored := tr.Begin(nil)
+	require.Equal(t, int64(0), stored.Delta(ctx, metric, 5))
+	tr.Commit(stored)
+	tr.Release(failed)
+	// повторный Release ничего не делает
+	tr.Release(failed)
+
+	// прирост несохраненного пакета учитывается следующим значением
+	b := tr.Begin(nil)
+	require.Equal(t, int64(7), b.Delta(ctx, metric, 7))
+	tr.Commit(b)
+}
+
+// blockingGetter ожидает release перед ответом.
+type blockingGetter struct {
+	release chan struct{}
+}
+
+func (g blockingGetter) Get(ctx context.Context, name string, _ ...model.Matcher) (model.MetricDto, error) {
+	<-g.release
+	return model.MetricDto{}, repository.ErrNotFound
+}
+
+func TestSlowGetter(t *testing.T) {
+	ctx := context.Background()
+	tr := NewTracker()
+	g := blockingGetter{release: make(chan struct{})}
+
+	slow := make(chan int64)
+	go func() {
+		b := tr.Begin(g)
+		slow <- b.Delta(ctx, model.Counter("slow", 0), 3)
+	}()
+
+	// запрос к хранилищу одной серии не задерживает другие
+	b := tr.Begin(nil)
+	require.Equal(t, int64(5), b.Delta(ctx, model.Counter("fast", 0), 5))
+	tr.Commit(b)
+
+	close(g.release)
+	require.Equal(t, int64(3), <-slow)
+}
+
+func TestPrune(t *testing.T) {
+	ctx := context.Background()
+	metric := model.Counter("jobs", 0)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(WithTTL(time.Hour))
+	tr.now = func() time.Time { return now }
+
+	b := tr.Begin(nil)
+	require.Equal(t, int64(5), b.Delta(ctx, metric, 5))
+	tr.Commit(b)
+
+	now = now.Add(30 * time.Minute)
+	tr.Begin(nil)
+	require.Len(t, tr.series, 1)
+
+	now = now.Add(2 * time.Hour)
+	tr.Begin(nil)
+	require.Empty(t, tr.series)
+}
@@ -0,0 +1,212 @@
+// Package exposition реализует текстовый формат экспозиции Prometheus версии 0.0.4.
+package exposition
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ContentType тип содержимого текстового формата экспозиции.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// family группа серий с одинаковым именем и типом.
+type family struct {
+	name    string
+	typ     model.MetricType
+	metrics []model.MetricDto
+}
+
+// Write записывает метрики в текстовом формате экспозиции Prometheus.
+// Имена метрик и меток приводятся к допустимому виду.
+// Если после приведения имени серии разных типов совпадают,
+// то записывается только первый в порядке имен тип, а остальные возвращаются в skipped.
+func Write(w io.Writer, metrics []model.MetricDto) (skipped []model.MetricDto, err error) {
+	families := group(metrics)
+
+	bw := bufio.NewWriter(w)
+	written := make(map[string]model.MetricType, len(families))
+	for _, f := range families {
+		if t, ok := written[f.name]; ok && t != f.typ {
+			skipped = append(skipped, f.metrics...)
+			continue
+		}
+		written[f.name] = f.typ
+
+		writeFamily(bw, f)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return skipped, fmt.Errorf("exposition/write: flush: %w", err)
+	}
+	return skipped, nil
+}
+
+// group группирует метрики по приведенному имени и типу.
+// Группы упорядочены по имени, серии внутри группы по меткам.
+func group(metrics []model.MetricDto) []family {
+	index := make(map[string]int)
+	families := make([]family, 0)
+	for _, metric := range metrics {
+		if metric.Value.Type == model.TypeUnknown {
+			continue
+		}
+		name := SanitizeName(metric.Name)
+		key := name + " " + metric.Value.Type.String()
+
+		i, ok := index[key]
+		if !ok {
+			i = len(families)
+			index[key] = i
+			families = append(families, family{name: name, typ: metric.Value.Type})
+		}
+		families[i].metrics = append(families[i].metrics, metric)
+	}
+
+	sort.SliceStable(families, func(i, j int) bool {
+		if families[i].name != families[j].name {
+			return families[i].name < families[j].name
+		}
+		return families[i].typ < families[j].typ
+	})
+	for _, f := range families {
+		sort.SliceStable(f.metrics, func(i, j int) bool {
+			return f.metrics[i].Labels.String() < f.metrics[j].Labels.String()
+		})
+	}
+	return families
+}
+
+// writeFamily записывает строку # TYPE и значения всех серий группы.
+func writeFamily(w *bufio.Writer, f family) {
+	w.WriteString("# TYPE ")
+	w.WriteString(f.name)
+	w.WriteByte(' ')
+	w.WriteString(f.typ.String())
+	w.WriteByte('\n')
+
+	for _, metric := range f.metrics {
+		switch f.typ {
+		case model.TypeGauge:
+			writeSample(w, f.name, metric.Labels, "", "", formatFloat(metric.Value.Gauge))
+		case model.TypeCounter:
+			writeSample(w, f.name, metric.Labels, "", "", strconv.FormatInt(metric.Value.Counter, 10))
+		case model.TypeHistogram:
+			writeHistogram(w, f.name, metric)
+		}
+	}
+}
+
+// writeHistogram записывает кумулятивные бакеты, сумму и количество наблюдений гистограммы.
+func writeHistogram(w *bufio.Writer, name string, metric model.MetricDto) {
+	h := metric.Value.Histogram
+	if h == nil {
+		return
+	}
+
+	var cumulative uint64
+	for i, c := range h.Counts {
+		cumulative += c
+		le := "+Inf"
+		if i < len(h.Bounds) {
+			le = formatFloat(h.Bounds[i])
+		}
+		writeSample(w, name+"_bucket", metric.Labels, "le", le, strconv.FormatUint(cumulative, 10))
+	}
+	writeSample(w, name+"_sum", metric.Labels, "", "", formatFloat(h.Sum))
+	writeSample(w, name+"_count", metric.Labels, "", "", strconv.FormatUint(h.Count, 10))
+}
+
+// writeSample записывает строку значения серии.
+// Если extraName не пустой, то метка extraName=extraValue добавляется последней.
+func writeSample(w *bufio.Writer, name string, labels model.Labels, extraName, extraValue, value string) {
+	w.WriteString(name)
+
+	if len(labels) > 0 || extraName != "" {
+		w.WriteByte('{')
+		first := true
+		for _, label := range slices.Sorted(maps.Keys(labels)) {
+			if !first {
+				w.WriteByte(',')
+			}
+			first = false
+			writeLabel(w, SanitizeLabelName(label), labels[label])
+		}
+		if extraName != "" {
+			if !first {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(value)
+	w.WriteByte('\n')
+}
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	w.WriteString(labelValueReplacer.Replace(value))
+	w.WriteByte('"')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat форматирует значение так, как его ожидает Prometheus.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// SanitizeName приводит имя метрики к виду [a-zA-Z_:][a-zA-Z0-9_:]*.
+// Недопустимые символы заменяются на подчеркивание.
+func SanitizeName(name string) string {
+	return sanitize(name, true)
+}
+
+// SanitizeLabelName приводит имя метки к виду [a-zA-Z_][a-zA-Z0-9_]*.
+// Недопустимые символы заменяются на подчеркивание.
+func SanitizeLabelName(name string) string {
+	return sanitize(name, false)
+}
+
+func sanitize(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':' && allowColon:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
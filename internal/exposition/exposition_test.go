@@ -0,0 +1,106 @@
+package exposition
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+func TestWrite(t *testing.T) {
+	labeled := model.Counter("http.requests", 3)
+	labeled.Labels = model.Labels{"host": "web-1", "path.name": `/a"b`}
+
+	hist := model.HistogramMetric("latency", &model.Histogram{
+		Bounds: []float64{0.5, 1},
+		Counts: []uint64{1, 2, 3},
+		Sum:    10.5,
+		Count:  6,
+	})
+	hist.Labels = model.Labels{"host": "web-1"}
+
+	testCases := []struct {
+		name            string
+		metrics         []model.MetricDto
+		expected        string
+		expectedSkipped int
+	}{
+		{
+			name:     "empty",
+			metrics:  nil,
+			expected: "",
+		},
+		{
+			name: "gauge and counter",
+			metrics: []model.MetricDto{
+				model.Gauge("Alloc", 0.1),
+				model.Counter("PollCount", 5),
+				model.Gauge("nan", math.NaN()),
+			},
+			expected: "# TYPE Alloc gauge\nAlloc 0.1\n" +
+				"# TYPE PollCount counter\nPollCount 5\n" +
+				"# TYPE nan gauge\nnan NaN\n",
+		},
+		{
+			name:    "sanitized names and escaped labels",
+			metrics: []model.MetricDto{labeled, model.Counter("http.requests", 1)},
+			expected: "# TYPE http_requests counter\n" +
+				"http_requests 1\n" +
+				`http_requests{host="web-1",path_name="/a\"b"} 3` + "\n",
+		},
+		{
+			name:    "histogram",
+			metrics: []model.MetricDto{hist},
+			expected: "# TYPE latency histogram\n" +
+				`latency_bucket{host="web-1",le="0.5"} 1` + "\n" +
+				`latency_bucket{host="web-1",le="1"} 3` + "\n" +
+				`latency_bucket{host="web-1",le="+Inf"} 6` + "\n" +
+				`latency_sum{host="web-1"} 10.5` + "\n" +
+				`latency_count{host="web-1"} 6` + "\n",
+		},
+		{
+			name: "conflicting types",
+			metrics: []model.MetricDto{
+				model.Counter("a.b", 1),
+				model.Gauge("a_b", 2),
+			},
+			expected:        "# TYPE a_b gauge\na_b 2\n",
+			expectedSkipped: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			skipped, err := Write(&buf, tc.metrics)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, buf.String())
+			require.Len(t, skipped, tc.expectedSkipped)
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		name          string
+		in            string
+		expectedName  string
+		expectedLabel string
+	}{
+		{name: "valid", in: "go_gc:total", expectedName: "go_gc:total", expectedLabel: "go_gc_total"},
+		{name: "dots and dashes", in: "http.req-count", expectedName: "http_req_count", expectedLabel: "http_req_count"},
+		{name: "leading digit", in: "1xx", expectedName: "_1xx", expectedLabel: "_1xx"},
+		{name: "unicode", in: "метрика", expectedName: "_______", expectedLabel: "_______"},
+		{name: "empty", in: "", expectedName: "_", expectedLabel: "_"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expectedName, SanitizeName(tc.in))
+			require.Equal(t, tc.expectedLabel, SanitizeLabelName(tc.in))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/htrandev/metrics/internal/auth"
+	"github.com/htrandev/metrics/internal/identity"
+	"github.com/htrandev/metrics/pkg/metadatautil"
+)
+
+// Auth аутентифицирует агентов по токену из метаданных authorization
+// и проверяет право доступа, требуемое методом.
+// Методы, отсутствующие в scopes, доступны только с правом admin.
+func Auth(reg auth.Registry, scopes map[string]auth.Scope) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		token, ok := auth.BearerToken(metadatautil.GetAuthorization(ctx))
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+
+		p, err := reg.Lookup(ctx, token)
+		if errors.Is(err, auth.ErrUnknownToken) {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "lookup token: %s", err.Error())
+		}
+
+		scope, ok := scopes[info.FullMethod]
+		if !ok {
+			scope = auth.ScopeAdmin
+		}
+		if !p.Allows(scope) {
+			return nil, status.Errorf(codes.PermissionDenied, "scope %s required", scope)
+		}
+
+		ctx = auth.NewContext(ctx, p)
+		ctx = identity.NewContext(ctx, p.Agent)
+		return handler(ctx, req)
+	}
+}
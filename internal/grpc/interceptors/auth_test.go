@@ -0,0 +1,92 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/htrandev/metrics/internal/auth"
+	"github.com/htrandev/metrics/internal/identity"
+	pb "github.com/htrandev/metrics/internal/proto"
+)
+
+type mockRegistry map[string]auth.Principal
+
+func (m mockRegistry) Lookup(_ context.Context, token string) (auth.Principal, error) {
+	p, ok := m[token]
+	if !ok {
+		return auth.Principal{}, auth.ErrUnknownToken
+	}
+	return p, nil
+}
+
+func TestAuth(t *testing.T) {
+	reg := mockRegistry{
+		"writer": {Agent: "agent-1", Scopes: []auth.Scope{auth.ScopeWrite}},
+		"reader": {Agent: "dashboard", Scopes: []auth.Scope{auth.ScopeRead}},
+	}
+	scopes := map[string]auth.Scope{pb.Metrics_UpdateMetrics_FullMethodName: auth.ScopeWrite}
+
+	testCases := []struct {
+		name             string
+		method           string
+		token            string
+		expectedCode     codes.Code
+		expectedIdentity string
+	}{
+		{
+			name:             "allowed",
+			method:           pb.Metrics_UpdateMetrics_FullMethodName,
+			token:            "writer",
+			expectedCode:     codes.OK,
+			expectedIdentity: "agent-1",
+		},
+		{
+			name:         "insufficient scope",
+			method:       pb.Metrics_UpdateMetrics_FullMethodName,
+			token:        "reader",
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "unknown method requires admin",
+			method:       "/metrics.Metrics/Other",
+			token:        "writer",
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "invalid token",
+			method:       pb.Metrics_UpdateMetrics_FullMethodName,
+			token:        "other",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "missing token",
+			method:       pb.Metrics_UpdateMetrics_FullMethodName,
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tc.token))
+			}
+
+			var gotIdentity string
+			handler := func(ctx context.Context, req any) (any, error) {
+				gotIdentity = identity.FromContext(ctx)
+				return nil, nil
+			}
+
+			_, err := Auth(reg, scopes)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+			require.Equal(t, tc.expectedIdentity, gotIdentity)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/htrandev/metrics/internal/identity"
+)
+
+// CertIdentity определяет агента по проверенному клиентскому сертификату
+// вместо передаваемого в метаданных IP-адреса.
+func CertIdentity() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if id, ok := identity.FromTLS(&tlsInfo.State); ok {
+				ctx = identity.NewContext(ctx, id)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
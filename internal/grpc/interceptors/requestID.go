@@ -0,0 +1,27 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/htrandev/metrics/internal/handler/middleware"
+	"github.com/htrandev/metrics/pkg/metadatautil"
+)
+
+// RequestID назначает запросу идентификатор.
+// Идентификатор клиента из метаданных x-request-id сохраняется, если он корректен,
+// иначе генерируется новый. Идентификатор возвращается в заголовке ответа.
+func RequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		id := metadatautil.GetRequestID(ctx)
+		if !middleware.ValidRequestID(id) {
+			id = uuid.NewString()
+			ctx = metadatautil.ReplaceRequestID(ctx, id)
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,85 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/htrandev/metrics/internal/model"
+	pb "github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/pkg/metadatautil"
+	"github.com/htrandev/metrics/pkg/sign"
+)
+
+func TestSignature(t *testing.T) {
+	keys := sign.Keyring{"k1": sign.Signature("secret")}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.Metrics_UpdateMetrics_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) { return &pb.UpdateMetricsResponse{}, nil }
+
+	gauge := model.Gauge("gauge", 0.1)
+	gauge.Labels = model.Labels{"host": "web-1", "dc": "eu", "env": "prod"}
+	req := pb.UpdateMetricsRequest_builder{Metrics: []*pb.Metric{model.ToProto(gauge)}}.Build()
+
+	// incoming переносит подписанные агентом метаданные во входящий контекст
+	incoming := func(t *testing.T, keyID string, method string) context.Context {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+		require.NoError(t, err)
+		st, err := sign.NewStamp(keyID, keys["k1"], method, b)
+		require.NoError(t, err)
+
+		md, _ := metadata.FromOutgoingContext(metadatautil.SetStamp(context.Background(), st))
+		return metadata.NewIncomingContext(context.Background(), md)
+	}
+
+	testCases := []struct {
+		name         string
+		ctx          func(t *testing.T) context.Context
+		expectedCode codes.Code
+	}{
+		{
+			name:         "valid",
+			ctx:          func(t *testing.T) context.Context { return incoming(t, "k1", info.FullMethod) },
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "not signed",
+			ctx:          func(t *testing.T) context.Context { return context.Background() },
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "unknown key",
+			ctx:          func(t *testing.T) context.Context { return incoming(t, "k2", info.FullMethod) },
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "other method",
+			ctx:          func(t *testing.T) context.Context { return incoming(t, "k1", "/metrics.Metrics/Other") },
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			intr := Signature(sign.NewVerifier(keys, time.Minute))
+			_, err := intr(tc.ctx(t), req, info, handler)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		intr := Signature(sign.NewVerifier(keys, time.Minute))
+		ctx := incoming(t, "k1", info.FullMethod)
+
+		_, err := intr(ctx, req, info, handler)
+		require.NoError(t, err)
+		_, err = intr(ctx, req, info, handler)
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
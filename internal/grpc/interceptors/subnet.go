@@ -4,16 +4,51 @@ import (
 	"context"
 	"net"
 
-	"github.com/htrandev/metrics/pkg/metadatautil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+
+	"github.com/htrandev/metrics/pkg/metadatautil"
+	"github.com/htrandev/metrics/pkg/netutil"
 )
 
-func Subnet(subnet *net.IPNet) grpc.UnaryServerInterceptor {
+// keyForwardedFor содержит цепочку адресов, добавленных прокси.
+const keyForwardedFor = "x-forwarded-for"
+
+// RealIP определяет IP-адрес клиента по адресу соединения и метаданным
+// x-forwarded-for, пройденным только через доверенные прокси.
+// Переданный клиентом IP-адрес в метаданных заменяется определенным адресом.
+func RealIP(proxies netutil.Subnets) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		var ip net.IP
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			ip = netutil.ClientIP(p.Addr.String(), metadata.ValueFromIncomingContext(ctx, keyForwardedFor), proxies)
+		}
+
+		var realIP string
+		if ip != nil {
+			realIP = ip.String()
+		}
+		return handler(metadatautil.ReplaceRealIP(ctx, realIP), req)
+	}
+}
+
+// Subnet пропускает только запросы из доверенных подсетей.
+// IP-адрес клиента берется из метаданных, установленных RealIP.
+// Запросы без IP-адреса пропускаются, если не включен строгий режим strict.
+func Subnet(subnets netutil.Subnets, strict bool) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 		ip := net.ParseIP(metadatautil.GetRealIP(ctx))
-		if len(ip) != 0 && !subnet.Contains(ip) {
+		if len(ip) == 0 {
+			if strict {
+				return nil, status.Error(codes.PermissionDenied, "client ip is not resolved")
+			}
+			return handler(ctx, req)
+		}
+
+		if !subnets.Contains(ip) {
 			return nil, status.Errorf(codes.PermissionDenied, "not trusted ip: %s", ip.String())
 		}
 
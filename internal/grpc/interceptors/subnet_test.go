@@ -0,0 +1,83 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/htrandev/metrics/pkg/netutil"
+)
+
+func TestSubnet(t *testing.T) {
+	proxies, err := netutil.ParseCIDRs("10.0.0.0/8")
+	require.NoError(t, err)
+	subnets, err := netutil.ParseCIDRs("192.168.1.0/24,fd00::/8")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		peer         net.Addr
+		md           metadata.MD
+		strict       bool
+		expectedCode codes.Code
+	}{
+		{
+			name:         "trusted peer",
+			peer:         &net.TCPAddr{IP: net.ParseIP("192.168.1.5"), Port: 4321},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "trusted ipv6 peer",
+			peer:         &net.TCPAddr{IP: net.ParseIP("fd00::5"), Port: 4321},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "spoofed real ip",
+			peer:         &net.TCPAddr{IP: net.ParseIP("172.16.0.5"), Port: 4321},
+			md:           metadata.Pairs("real_ip", "192.168.1.5"),
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "forwarded by trusted proxy",
+			peer:         &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 4321},
+			md:           metadata.Pairs(keyForwardedFor, "192.168.1.5"),
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "no peer",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "no peer strict",
+			strict:       true,
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.peer != nil {
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: tc.peer})
+			}
+			if tc.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tc.md)
+			}
+
+			handler := func(ctx context.Context, req any) (any, error) { return req, nil }
+			chain := func(ctx context.Context, req any) (any, error) {
+				return Subnet(subnets, tc.strict)(ctx, req, &grpc.UnaryServerInfo{}, handler)
+			}
+
+			_, err := RealIP(proxies)(ctx, nil, &grpc.UnaryServerInfo{}, chain)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
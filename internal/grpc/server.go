@@ -2,23 +2,35 @@ package grpc
 
 import (
 	"context"
-	"encoding/base64"
+	"crypto/rsa"
+	"errors"
 	"fmt"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/htrandev/metrics/internal/audit"
 	"github.com/htrandev/metrics/internal/contracts"
+	"github.com/htrandev/metrics/internal/identity"
 	"github.com/htrandev/metrics/internal/model"
 	pb "github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/pkg/crypto"
 	"github.com/htrandev/metrics/pkg/metadatautil"
-	"github.com/htrandev/metrics/pkg/sign"
 )
 
+// Publisher предоставляет интерфейс публикации событий аудита.
+type Publisher interface {
+	Update(ctx context.Context, info audit.AuditInfo)
+}
+
 type MetricServerOptions struct {
-	Service   contracts.Service
-	Signature string
+	Service contracts.Service
+	// Publisher получает события аудита сохранения метрик. Может быть nil.
+	Publisher Publisher
+	// Key закрытый ключ для расшифровки метрик.
+	// Если задан, принимаются только зашифрованные запросы.
+	Key *rsa.PrivateKey
 }
 
 type MetricsServer struct {
@@ -35,24 +47,69 @@ func (s *MetricsServer) UpdateMetrics(ctx context.Context, req *pb.UpdateMetrics
 		return &pb.UpdateMetricsResponse{}, nil
 	}
 
-	if s.opts.Signature != "" {
-		valid, err := s.checkHash(ctx, req)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "check hash: %s", err.Error())
-		}
-		if !valid {
-			return nil, status.Error(codes.DataLoss, "request not valid")
-		}
+	req, err := s.decrypt(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decrypt request: %s", err.Error())
 	}
 
 	metrics := buildMetrics(req.GetMetrics())
+	for _, metric := range metrics {
+		if err := metric.Validate(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "validate: %s", err.Error())
+		}
+	}
 
-	if err := s.opts.Service.StoreManyWithRetry(ctx, metrics); err != nil {
-		return nil, status.Errorf(codes.Internal, "store many with retry: %s", err.Error())
+	// повторно доставленный пакет подтверждается без повторного сохранения и аудита
+	applied, err := s.opts.Service.StoreBatch(ctx, metadatautil.GetBatchID(ctx), metrics)
+	if err != nil {
+		s.publish(ctx, metrics, err)
+		return nil, status.Errorf(codes.Internal, "store batch: %s", err.Error())
+	}
+	if applied {
+		s.publish(ctx, metrics, nil)
 	}
 	return &pb.UpdateMetricsResponse{}, nil
 }
 
+// publish публикует событие аудита сохранения метрик.
+func (s *MetricsServer) publish(ctx context.Context, metrics []model.MetricDto, storeErr error) {
+	if s.opts.Publisher == nil {
+		return
+	}
+
+	info := audit.NewInfo(metrics, storeErr)
+	info.Transport = audit.TransportGRPC
+	info.IP = metadatautil.GetRealIP(ctx)
+	info.Agent = identity.FromContext(ctx)
+	info.RequestID = metadatautil.GetRequestID(ctx)
+	s.opts.Publisher.Update(ctx, info)
+}
+
+// decrypt возвращает запрос с расшифрованными метриками.
+// Подпись проверяется до расшифровки, по передаваемому зашифрованному запросу.
+func (s *MetricsServer) decrypt(req *pb.UpdateMetricsRequest) (*pb.UpdateMetricsRequest, error) {
+	encrypted := req.GetEncrypted()
+	if s.opts.Key == nil {
+		if len(encrypted) > 0 {
+			return nil, errors.New("encrypted request is not supported: private key is not set")
+		}
+		return req, nil
+	}
+	if len(encrypted) == 0 {
+		return nil, errors.New("request is not encrypted")
+	}
+
+	b, err := crypto.Decrypt(s.opts.Key, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	decrypted := &pb.UpdateMetricsRequest{}
+	if err := proto.Unmarshal(b, decrypted); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return decrypted, nil
+}
+
 func buildMetrics(metrics []*pb.Metric) []model.MetricDto {
 	result := make([]model.MetricDto, 0, len(metrics))
 
@@ -62,19 +119,3 @@ func buildMetrics(metrics []*pb.Metric) []model.MetricDto {
 
 	return result
 }
-
-// checkHash check if request is valid.
-func (s *MetricsServer) checkHash(ctx context.Context, req *pb.UpdateMetricsRequest) (bool, error) {
-	b, err := proto.Marshal(req)
-	if err != nil {
-		return false, fmt.Errorf("unable to marshal req: %w", err)
-	}
-	si := sign.Signature(s.opts.Signature)
-	signature := si.Sign(b)
-	gotHash := base64.RawURLEncoding.EncodeToString(signature)
-	receivedHash := metadatautil.GetHash256(ctx)
-	if gotHash == receivedHash {
-		return true, nil
-	}
-	return false, nil
-}
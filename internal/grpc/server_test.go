@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/htrandev/metrics/internal/audit"
+	mock_contracts "github.com/htrandev/metrics/internal/contracts/mocks"
+	"github.com/htrandev/metrics/internal/identity"
+	"github.com/htrandev/metrics/internal/model"
+	pb "github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/pkg/crypto"
+)
+
+func TestUpdateMetricsEncrypted(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	metrics := make([]model.MetricDto, 0, 50)
+	for range 50 {
+		metrics = append(metrics, model.Gauge("gauge", 0.1))
+	}
+	plain := pb.UpdateMetricsRequest_builder{Metrics: buildProto(metrics)}.Build()
+
+	b, err := proto.Marshal(plain)
+	require.NoError(t, err)
+	encrypted, err := crypto.Encrypt(&key.PublicKey, b)
+	require.NoError(t, err)
+	sealed := pb.UpdateMetricsRequest_builder{Encrypted: encrypted}.Build()
+
+	testCases := []struct {
+		name         string
+		key          *rsa.PrivateKey
+		req          *pb.UpdateMetricsRequest
+		stored       bool
+		expectedCode codes.Code
+	}{
+		{
+			name:         "encrypted",
+			key:          key,
+			req:          sealed,
+			stored:       true,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "plain without key",
+			key:          nil,
+			req:          plain,
+			stored:       true,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "plain with key",
+			key:          key,
+			req:          plain,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "encrypted without key",
+			key:          nil,
+			req:          sealed,
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mock_contracts.NewMockService(ctrl)
+			if tc.stored {
+				service.EXPECT().StoreBatch(gomock.Any(), "", metrics).Return(true, nil)
+			}
+
+			s := New(&MetricServerOptions{Service: service, Key: tc.key})
+			_, err := s.UpdateMetrics(ctx, tc.req)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
+
+type mockPublisher struct {
+	infos []audit.AuditInfo
+}
+
+func (m *mockPublisher) Update(ctx context.Context, info audit.AuditInfo) {
+	m.infos = append(m.infos, info)
+}
+
+func TestUpdateMetricsAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	metrics := []model.MetricDto{model.Gauge("gauge", 0.5)}
+	req := pb.UpdateMetricsRequest_builder{Metrics: buildProto(metrics)}.Build()
+
+	testCases := []struct {
+		name            string
+		applied         bool
+		storeErr        error
+		expectedEvents  int
+		expectedOutcome string
+	}{
+		{
+			name:            "stored",
+			applied:         true,
+			expectedEvents:  1,
+			expectedOutcome: audit.OutcomeSuccess,
+		},
+		{
+			name:           "duplicate batch",
+			applied:        false,
+			expectedEvents: 0,
+		},
+		{
+			name:            "store error",
+			storeErr:        errors.New("store error"),
+			expectedEvents:  1,
+			expectedOutcome: audit.OutcomeError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"real_ip", "10.0.0.1",
+				"x-request-id", "req-1",
+				"batch_id", "batch-1",
+			))
+			ctx = identity.NewContext(ctx, "agent-1")
+
+			service := mock_contracts.NewMockService(ctrl)
+			service.EXPECT().StoreBatch(gomock.Any(), "batch-1", metrics).Return(tc.applied, tc.storeErr)
+
+			publisher := &mockPublisher{}
+			s := New(&MetricServerOptions{Service: service, Publisher: publisher})
+			_, _ = s.UpdateMetrics(ctx, req)
+
+			require.Len(t, publisher.infos, tc.expectedEvents)
+			if tc.expectedEvents == 0 {
+				return
+			}
+
+			info := publisher.infos[0]
+			require.Equal(t, audit.TransportGRPC, info.Transport)
+			require.Equal(t, "10.0.0.1", info.IP)
+			require.Equal(t, "agent-1", info.Agent)
+			require.Equal(t, "req-1", info.RequestID)
+			require.Equal(t, []audit.MetricValue{{Name: "gauge", Type: "gauge", Value: "0.5"}}, info.Values)
+			require.Equal(t, tc.expectedOutcome, info.Outcome)
+		})
+	}
+}
+
+func TestUpdateMetricsInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	testCases := []struct {
+		name   string
+		metric model.MetricDto
+	}{
+		{
+			name: "invalid histogram",
+			metric: model.MetricDto{
+				Name: "latency",
+				Value: model.MetricValue{
+					Type:      model.TypeHistogram,
+					Histogram: &model.Histogram{Bounds: []float64{1}, Counts: []uint64{1}, Count: 1},
+				},
+			},
+		},
+		{
+			name: "invalid label name",
+			metric: model.MetricDto{
+				Name:   "requests",
+				Labels: model.Labels{`host="a",dc`: "eu"},
+				Value:  model.MetricValue{Type: model.TypeCounter, Counter: 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := []model.MetricDto{model.Counter("counter", 1), tc.metric}
+			req := pb.UpdateMetricsRequest_builder{Metrics: buildProto(metrics)}.Build()
+
+			// невалидный пакет отклоняется целиком и не доходит до хранилища
+			service := mock_contracts.NewMockService(ctrl)
+			s := New(&MetricServerOptions{Service: service})
+
+			_, err := s.UpdateMetrics(context.Background(), req)
+			require.Error(t, err)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func buildProto(metrics []model.MetricDto) []*pb.Metric {
+	result := make([]*pb.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, model.ToProto(m))
+	}
+	return result
+}
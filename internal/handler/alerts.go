@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/alerting"
+)
+
+// AlertLister возвращает текущие оповещения.
+type AlertLister interface {
+	Alerts() []alerting.Alert
+}
+
+// WithAlerts задает источник оповещений для /api/v1/alerts.
+func WithAlerts(l AlertLister) Option {
+	return func(h *MetricHandler) {
+		h.alerts = l
+	}
+}
+
+// Alerts обрабатывает HTTP GET /api/v1/alerts для получения состояний оповещений в JSON.
+// Если правила оповещений не заданы, возвращается пустой список.
+func (h *MetricHandler) Alerts(rw http.ResponseWriter, r *http.Request) {
+	scope := zap.String("scope", "handler/Alerts")
+
+	alerts := alerting.Alerts{}
+	if h.alerts != nil {
+		alerts = h.alerts.Alerts()
+	}
+
+	body, err := easyjson.Marshal(alerts)
+	if err != nil {
+		h.logger.Error("marshal response", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(body)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/alerting"
+)
+
+// BaselineLister возвращает оценки нормальных значений серий правил аномалий.
+type BaselineLister interface {
+	Baselines() []alerting.Baseline
+}
+
+// WithBaselines задает источник оценок для /api/v1/baselines.
+func WithBaselines(l BaselineLister) Option {
+	return func(h *MetricHandler) {
+		h.baselines = l
+	}
+}
+
+// Baselines обрабатывает HTTP GET /api/v1/baselines для получения последних значений
+// серий вместе со средним, стандартным отклонением и границами нормы в JSON.
+// Если правила аномалий не заданы, возвращается пустой список.
+func (h *MetricHandler) Baselines(rw http.ResponseWriter, r *http.Request) {
+	scope := zap.String("scope", "handler/Baselines")
+
+	baselines := alerting.Baselines{}
+	if h.baselines != nil {
+		if b := h.baselines.Baselines(); b != nil {
+			baselines = b
+		}
+	}
+
+	body, err := easyjson.Marshal(baselines)
+	if err != nil {
+		h.logger.Error("marshal response", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(body)
+}
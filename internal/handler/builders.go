@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/mailru/easyjson"
 
 	"github.com/htrandev/metrics/internal/audit"
+	"github.com/htrandev/metrics/internal/handler/middleware"
+	"github.com/htrandev/metrics/internal/identity"
 	"github.com/htrandev/metrics/internal/model"
 )
 
@@ -44,7 +49,8 @@ func buildGetRequest(r *http.Request) (*model.MetricDto, error) {
 	}
 
 	m := &model.MetricDto{
-		Name: req.ID,
+		Name:   req.ID,
+		Labels: req.Labels,
 	}
 
 	switch req.MType {
@@ -52,6 +58,8 @@ func buildGetRequest(r *http.Request) (*model.MetricDto, error) {
 		m.Value.Type = model.TypeGauge
 	case model.TypeCounter.String():
 		m.Value.Type = model.TypeCounter
+	case model.TypeHistogram.String():
+		m.Value.Type = model.TypeHistogram
 	default:
 		return nil, fmt.Errorf("unknown metric type: %s", req.MType)
 	}
@@ -59,10 +67,13 @@ func buildGetRequest(r *http.Request) (*model.MetricDto, error) {
 	return m, nil
 }
 
+// buildResponse возвращает метрику для JSON ответа.
+// Если время обновления серии известно, в ответ добавляются время и возраст значения.
 func buildResponse(metric model.MetricDto) model.Metrics {
 	m := model.Metrics{
-		ID:    metric.Name,
-		MType: metric.Value.Type.String(),
+		ID:     metric.Name,
+		MType:  metric.Value.Type.String(),
+		Labels: metric.Labels,
 	}
 
 	switch metric.Value.Type {
@@ -70,6 +81,15 @@ func buildResponse(metric model.MetricDto) model.Metrics {
 		m.Value = &metric.Value.Gauge
 	case model.TypeCounter:
 		m.Delta = &metric.Value.Counter
+	case model.TypeHistogram:
+		m.Histogram = metric.Value.Histogram
+	}
+
+	if age, ok := metric.Age(time.Now()); ok {
+		updatedAt := metric.UpdatedAt
+		seconds := age.Truncate(time.Millisecond).Seconds()
+		m.UpdatedAt = &updatedAt
+		m.Age = &seconds
 	}
 
 	return m
@@ -115,22 +135,127 @@ func buildInternalMetric(metric model.Metrics) (model.MetricDto, error) {
 		} else {
 			return m, fmt.Errorf("value for metric is nil")
 		}
+	case model.TypeHistogram.String():
+		if metric.Histogram == nil {
+			return m, fmt.Errorf("value for metric is nil")
+		}
+		if err := metric.Histogram.Validate(); err != nil {
+			return m, fmt.Errorf("validate histogram: %w", err)
+		}
+		m = model.HistogramMetric(metric.ID, metric.Histogram)
 	default:
 		return m, fmt.Errorf("unknown metric type: %s", metric.MType)
 	}
 
+	// пустое имя проверяет обработчик, для него возвращается 404
+	if metric.ID != "" {
+		if err := model.ValidateName(metric.ID); err != nil {
+			return m, fmt.Errorf("validate name: %w", err)
+		}
+	}
+	if err := metric.Labels.Validate(); err != nil {
+		return m, fmt.Errorf("validate labels: %w", err)
+	}
+	m.Labels = metric.Labels
+
 	return m, nil
 }
 
-func buildAuditInfoMessage(metrics []model.MetricDto, ip string) audit.AuditInfo {
-	names := make([]string, 0, len(metrics))
-	for _, metric := range metrics {
-		names = append(names, metric.Name)
+// buildMatchers возвращает матчеры меток из параметров запроса match.
+// Например: /value/gauge/Alloc?match=host="web-1"&match=dc=~"eu-.*".
+func buildMatchers(r *http.Request) ([]model.Matcher, error) {
+	values := r.URL.Query()["match"]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	matchers := make([]model.Matcher, 0, len(values))
+	for _, v := range values {
+		m, err := model.ParseMatcher(v)
+		if err != nil {
+			return nil, fmt.Errorf("parse matcher: %w", err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// buildAuditInfoMessage возвращает событие аудита записи метрик по HTTP.
+// Ненулевая ошибка storeErr означает, что метрики не сохранены.
+func buildAuditInfoMessage(r *http.Request, metrics []model.MetricDto, storeErr error) audit.AuditInfo {
+	info := audit.NewInfo(metrics, storeErr)
+	info.Transport = audit.TransportHTTP
+	info.IP = getIP(r)
+	info.Agent = identity.FromContext(r.Context())
+	info.RequestID = r.Header.Get(middleware.RequestIDHeader)
+	return info
+}
+
+// defaultRangeWindow интервал истории по умолчанию для /api/v1/range.
+const defaultRangeWindow = time.Hour
+
+// rangeRequest параметры запроса истории значений метрики.
+type rangeRequest struct {
+	name     string
+	from     time.Time
+	to       time.Time
+	step     time.Duration
+	matchers []model.Matcher
+}
+
+func buildRangeRequest(r *http.Request) (rangeRequest, error) {
+	q := r.URL.Query()
+
+	req := rangeRequest{
+		name: q.Get("name"),
+		to:   time.Now(),
+	}
+	if req.name == "" {
+		return rangeRequest{}, errors.New("empty metric name")
+	}
+
+	var err error
+	if v := q.Get("to"); v != "" {
+		if req.to, err = parseTime(v); err != nil {
+			return rangeRequest{}, fmt.Errorf("parse to: %w", err)
+		}
+	}
+
+	req.from = req.to.Add(-defaultRangeWindow)
+	if v := q.Get("from"); v != "" {
+		if req.from, err = parseTime(v); err != nil {
+			return rangeRequest{}, fmt.Errorf("parse from: %w", err)
+		}
+	}
+
+	if req.from.After(req.to) {
+		return rangeRequest{}, errors.New("from is after to")
+	}
+
+	if v := q.Get("step"); v != "" {
+		if req.step, err = time.ParseDuration(v); err != nil {
+			return rangeRequest{}, fmt.Errorf("parse step: %w", err)
+		}
+		if req.step < 0 {
+			return rangeRequest{}, errors.New("negative step")
+		}
+	}
+
+	if req.matchers, err = buildMatchers(r); err != nil {
+		return rangeRequest{}, fmt.Errorf("build matchers: %w", err)
 	}
+	return req, nil
+}
 
-	return audit.AuditInfo{
-		Timestamp: time.Now().Unix(),
-		Metrics:   names,
-		IP:        ip,
+// parseTime разбирает время в формате RFC3339 или unix-времени в секундах.
+func parseTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		whole, frac := math.Modf(sec)
+		return time.Unix(int64(whole), int64(frac*float64(time.Second))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time %q: %w", s, err)
 	}
+	return t, nil
 }
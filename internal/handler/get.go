@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/mailru/easyjson"
 	"go.uber.org/zap"
@@ -29,7 +30,14 @@ func (h *MetricHandler) Get(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	metric, err := h.service.Get(ctx, metricName)
+	matchers, err := buildMatchers(r)
+	if err != nil {
+		h.logger.Error("build matchers", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	metric, err := h.service.Get(ctx, metricName, matchers...)
 	if errors.Is(err, repository.ErrNotFound) {
 		h.logger.Error("metric not found", scope)
 		rw.WriteHeader(http.StatusNotFound)
@@ -47,22 +55,39 @@ func (h *MetricHandler) Get(rw http.ResponseWriter, r *http.Request) {
 }
 
 // GetAll обрабатывает HTTP GET / для получения всех метрик.
+// Для каждой серии выводится время, прошедшее с ее последнего обновления.
+// Параметры запроса match фильтруют серии по меткам.
 func (h *MetricHandler) GetAll(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	metrics, err := h.service.GetAll(ctx)
+	matchers, err := buildMatchers(r)
+	if err != nil {
+		h.logger.Error("build matchers", zap.Error(err), zap.String("scope", "handler/GetAll"))
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.service.GetAll(ctx, matchers...)
 	if err != nil {
 		h.logger.Error("get all metrics", zap.Error(err), zap.String("scope", "handler/GetAll"))
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	now := time.Now()
+
 	var builder strings.Builder
 	for _, metric := range metrics {
-		builder.Grow(len(metric.Name) + len(metric.Value.String()) + 2)
-		builder.WriteString(metric.Name)
+		key := metric.Key()
+		builder.Grow(len(key) + len(metric.Value.String()) + 2)
+		builder.WriteString(key)
 		builder.WriteString(": ")
 		builder.WriteString(metric.Value.String())
+		if age, ok := metric.Age(now); ok {
+			builder.WriteString(" (updated ")
+			builder.WriteString(age.Round(time.Second).String())
+			builder.WriteString(" ago)")
+		}
 		builder.WriteString("\r")
 	}
 
@@ -73,6 +98,7 @@ func (h *MetricHandler) GetAll(rw http.ResponseWriter, r *http.Request) {
 
 // GetJSON обрабатывает HTTP POST /value/ для получения одной метрики в JSON.
 // Парсит тело запроса, возвращает структурированный JSON-ответ.
+// Метки из тела запроса используются как матчеры на точное совпадение.
 func (h *MetricHandler) GetJSON(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -91,7 +117,7 @@ func (h *MetricHandler) GetJSON(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	m, err := h.service.Get(ctx, req.Name)
+	m, err := h.service.Get(ctx, req.Name, model.EqualMatchers(req.Labels)...)
 	if errors.Is(err, repository.ErrNotFound) {
 		h.logger.Error("metric not found", zap.Error(err), scope)
 		rw.WriteHeader(http.StatusNotFound)
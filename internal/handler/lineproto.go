@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/lineproto"
+)
+
+// InfluxWrite обрабатывает HTTP POST /write и /api/v2/write с телом в формате InfluxDB line protocol.
+// Целочисленные поля сохраняются как counter с приростом накопленного значения,
+// дробные и логические поля как gauge.
+func (h *MetricHandler) InfluxWrite(rw http.ResponseWriter, r *http.Request) {
+	h.writeLines(rw, r, lineproto.ParseInflux, zap.String("scope", "handler/InfluxWrite"))
+}
+
+// GraphiteWrite обрабатывает HTTP POST /graphite с телом в формате Graphite plaintext.
+// Значения сохраняются как gauge.
+func (h *MetricHandler) GraphiteWrite(rw http.ResponseWriter, r *http.Request) {
+	h.writeLines(rw, r, lineproto.ParseGraphite, zap.String("scope", "handler/GraphiteWrite"))
+}
+
+// writeLines разбирает построчное тело запроса и сохраняет метрики.
+// Если хотя бы одна строка некорректна, то ничего не сохраняется.
+func (h *MetricHandler) writeLines(rw http.ResponseWriter, r *http.Request, parse lineproto.Parser, scope zap.Field) {
+	ctx := r.Context()
+
+	parsed, err := lineproto.ParseLines(r.Body, parse)
+	if err != nil {
+		h.logger.Error("parse lines", zap.Error(err), scope)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	batch := h.lines.Convert(ctx, parsed, h.service)
+	defer h.lines.Release(batch)
+
+	m := batch.Metrics
+	if len(m) == 0 {
+		h.logger.Debug("receive empty metrics batch", scope)
+		h.lines.Commit(batch)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err = h.service.StoreManyWithRetry(ctx, m)
+	h.Publisher.Update(ctx, buildAuditInfoMessage(r, m, err))
+	if err != nil {
+		h.logger.Error("store many with retry", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.lines.Commit(batch)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
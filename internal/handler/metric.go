@@ -8,6 +8,9 @@ import (
 
 	"github.com/htrandev/metrics/internal/audit"
 	"github.com/htrandev/metrics/internal/contracts"
+	"github.com/htrandev/metrics/internal/lineproto"
+	"github.com/htrandev/metrics/internal/otlp"
+	"github.com/htrandev/metrics/internal/remotewrite"
 )
 
 // Publisher предоставляет интерфейс публикации событий.
@@ -20,6 +23,37 @@ type MetricHandler struct {
 	service   contracts.Service
 	logger    *zap.Logger
 	Publisher Publisher
+
+	remoteWrite *remotewrite.Converter
+	otlp        *otlp.Converter
+	lines       *lineproto.Converter
+	alerts      AlertLister
+	baselines   BaselineLister
+	silences    SilenceManager
+}
+
+// Option определяет дополнительные параметры обработчика.
+type Option func(*MetricHandler)
+
+// WithRemoteWrite задает преобразователь серий Prometheus remote_write.
+func WithRemoteWrite(c *remotewrite.Converter) Option {
+	return func(h *MetricHandler) {
+		h.remoteWrite = c
+	}
+}
+
+// WithOTLP задает преобразователь метрик OTLP.
+func WithOTLP(c *otlp.Converter) Option {
+	return func(h *MetricHandler) {
+		h.otlp = c
+	}
+}
+
+// WithLineProtocol задает преобразователь счетчиков построчных форматов.
+func WithLineProtocol(c *lineproto.Converter) Option {
+	return func(h *MetricHandler) {
+		h.lines = c
+	}
 }
 
 // NewMetricsHandler возвращает новый экземпляр MetricsHandler.
@@ -27,12 +61,27 @@ func NewMetricsHandler(
 	l *zap.Logger,
 	s contracts.Service,
 	p Publisher,
+	opts ...Option,
 ) *MetricHandler {
-	return &MetricHandler{
+	h := &MetricHandler{
 		logger:    l,
 		service:   s,
 		Publisher: p,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.remoteWrite == nil {
+		h.remoteWrite = remotewrite.NewConverter(remotewrite.DefaultCounterSuffixes)
+	}
+	if h.otlp == nil {
+		h.otlp = otlp.NewConverter()
+	}
+	if h.lines == nil {
+		h.lines = lineproto.NewConverter()
+	}
+	return h
 }
 
 // Ping обрабатывает HTTP-запрос /ping для проверки доступности сервиса.
@@ -3,24 +3,37 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/golang/mock/gomock"
+	"github.com/golang/snappy"
 	"github.com/mailru/easyjson"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/htrandev/metrics/internal/alerting"
 	"github.com/htrandev/metrics/internal/audit"
 	"github.com/htrandev/metrics/internal/contracts"
 	mock_contracts "github.com/htrandev/metrics/internal/contracts/mocks"
+	"github.com/htrandev/metrics/internal/exposition"
+	"github.com/htrandev/metrics/internal/handler/middleware"
+	"github.com/htrandev/metrics/internal/identity"
 	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/internal/otlp"
+	pb "github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/internal/remotewrite"
 	"github.com/htrandev/metrics/internal/repository"
+	"github.com/htrandev/metrics/internal/silence"
 )
 
 var (
@@ -31,9 +44,64 @@ var (
 	errPing      = errors.New("ping error")
 )
 
-type mockPublisher struct{}
+type mockPublisher struct {
+	infos []audit.AuditInfo
+}
+
+func (m *mockPublisher) Update(ctx context.Context, info audit.AuditInfo) {
+	m.infos = append(m.infos, info)
+}
+
+func TestAuditInfo(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	testCases := []struct {
+		name            string
+		storeErr        error
+		expectedOutcome string
+		expectedError   string
+	}{
+		{
+			name:            "success",
+			expectedOutcome: audit.OutcomeSuccess,
+		},
+		{
+			name:            "store error",
+			storeErr:        errStore,
+			expectedOutcome: audit.OutcomeError,
+			expectedError:   errStore.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := mock_contracts.NewMockService(ctrl)
+			service.EXPECT().Store(gomock.Any(), gomock.Any()).Return(tc.storeErr)
+
+			publisher := &mockPublisher{}
+			h := NewMetricsHandler(log, service, publisher)
 
-func (m *mockPublisher) Update(ctx context.Context, info audit.AuditInfo) {}
+			r := httptest.NewRequest(http.MethodPost, "/update/counter/someMetric/527", nil)
+			r.Header.Set(middleware.IPHeader, "10.0.0.1")
+			r.Header.Set(middleware.RequestIDHeader, "req-1")
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/update/{metricType}/{metricName}/{metricValue}", h.Update)
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+
+			require.Len(t, publisher.infos, 1)
+			info := publisher.infos[0]
+			require.Equal(t, []string{"someMetric"}, info.Metrics)
+			require.Equal(t, []audit.MetricValue{{Name: "someMetric", Type: "counter", Value: "527"}}, info.Values)
+			require.Equal(t, audit.TransportHTTP, info.Transport)
+			require.Equal(t, "10.0.0.1", info.IP)
+			require.Equal(t, "req-1", info.RequestID)
+			require.Equal(t, tc.expectedOutcome, info.Outcome)
+			require.Equal(t, tc.expectedError, info.Error)
+		})
+	}
+}
 
 func TestUpdateHandler(t *testing.T) {
 	ctx := context.Background()
@@ -193,6 +261,35 @@ func TestGetHandler(t *testing.T) {
 			expectedCode:     http.StatusOK,
 			expectedResponse: "1",
 		},
+		{
+			name: "valid with matcher",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				m, err := model.ParseMatcher(`host="web-1"`)
+				require.NoError(t, err)
+				service.EXPECT().Get(gomock.Any(), "test", m).Return(model.MetricDto{
+					Name: "test", Labels: model.Labels{"host": "web-1"},
+					Value: model.MetricValue{Gauge: 0.5, Type: model.TypeGauge}}, nil)
+				return service
+			}(),
+			method:           http.MethodGet,
+			url:              `/value/gauge/test?match=host%3D%22web-1%22`,
+			wantErr:          false,
+			expectedCode:     http.StatusOK,
+			expectedResponse: "0.5",
+		},
+		{
+			name: "invalid matcher",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+
+				return service
+			}(),
+			method:       http.MethodGet,
+			url:          `/value/gauge/test?match=host`,
+			wantErr:      true,
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name: "unknown metric type",
 			service: func() contracts.Service {
@@ -299,6 +396,19 @@ func TestGetAll(t *testing.T) {
 			expectedCode:     http.StatusOK,
 			expectedResponse: "gauge: 0.1\rcounter: 1\r",
 		},
+		{
+			name: "with updated at",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				gauge := model.Gauge("gauge", 0.1)
+				gauge.UpdatedAt = time.Now().Add(-time.Minute)
+				service.EXPECT().GetAll(gomock.Any()).Return([]model.MetricDto{gauge}, nil)
+				return service
+			}(),
+			method:           http.MethodGet,
+			expectedCode:     http.StatusOK,
+			expectedResponse: "gauge: 0.1 (updated 1m0s ago)\r",
+		},
 		{
 			name: "get all error",
 			service: func() contracts.Service {
@@ -387,6 +497,46 @@ func TestUpdateJSON(t *testing.T) {
 				return bytes.NewBuffer([]byte(`{"id":"gauge","type":"gauge","value":0.1}`))
 			}(),
 		},
+		{
+			name: "valid histogram",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				m := &model.MetricDto{
+					Name: "histogram",
+					Value: model.MetricValue{
+						Type: model.TypeHistogram,
+						Histogram: &model.Histogram{
+							Bounds: []float64{0.1, 1},
+							Counts: []uint64{1, 2, 0},
+							Sum:    1.05,
+							Count:  3,
+						},
+					},
+				}
+				service.EXPECT().Store(gomock.Any(), m).Return(nil)
+				return service
+			}(),
+			method:       http.MethodPost,
+			expectedCode: http.StatusOK,
+			body: func() io.Reader {
+				return bytes.NewBuffer([]byte(`{"id":"histogram","type":"histogram",` +
+					`"histogram":{"bounds":[0.1,1],"counts":[1,2,0],"sum":1.05,"count":3}}`))
+			}(),
+		},
+		{
+			name: "invalid histogram",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+
+				return service
+			}(),
+			method:       http.MethodPost,
+			expectedCode: http.StatusBadRequest,
+			body: func() io.Reader {
+				return bytes.NewBuffer([]byte(`{"id":"histogram","type":"histogram",` +
+					`"histogram":{"bounds":[0.1,1],"counts":[1,2],"sum":1.05,"count":3}}`))
+			}(),
+		},
 		{
 			name: "empty counter",
 			service: func() contracts.Service {
@@ -510,6 +660,7 @@ func TestUpdateManyJSON(t *testing.T) {
 		name         string
 		service      contracts.Service
 		method       string
+		batchID      string
 		expectedCode int
 		body         io.Reader
 	}{
@@ -521,10 +672,48 @@ func TestUpdateManyJSON(t *testing.T) {
 					{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.1}},
 					{Name: "counter", Value: model.MetricValue{Type: model.TypeCounter, Counter: 1}},
 				}
-				service.EXPECT().StoreManyWithRetry(gomock.Any(), m).Return(nil)
+				service.EXPECT().StoreBatch(gomock.Any(), "", m).Return(true, nil)
+				return service
+			}(),
+			method:       http.MethodPost,
+			expectedCode: http.StatusOK,
+			body: func() io.Reader {
+				b, err := easyjson.Marshal(metrics)
+				require.NoError(t, err)
+
+				return bytes.NewBuffer(b)
+			}(),
+		},
+		{
+			name: "valid with batch id",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				m := []model.MetricDto{
+					{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.1}},
+					{Name: "counter", Value: model.MetricValue{Type: model.TypeCounter, Counter: 1}},
+				}
+				service.EXPECT().StoreBatch(gomock.Any(), "batch-1", m).Return(true, nil)
+				return service
+			}(),
+			method:       http.MethodPost,
+			batchID:      "batch-1",
+			expectedCode: http.StatusOK,
+			body: func() io.Reader {
+				b, err := easyjson.Marshal(metrics)
+				require.NoError(t, err)
+
+				return bytes.NewBuffer(b)
+			}(),
+		},
+		{
+			name: "duplicate batch",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().StoreBatch(gomock.Any(), "batch-1", gomock.Any()).Return(false, nil)
 				return service
 			}(),
 			method:       http.MethodPost,
+			batchID:      "batch-1",
 			expectedCode: http.StatusOK,
 			body: func() io.Reader {
 				b, err := easyjson.Marshal(metrics)
@@ -567,7 +756,7 @@ func TestUpdateManyJSON(t *testing.T) {
 					{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.1}},
 					{Name: "counter", Value: model.MetricValue{Type: model.TypeCounter, Counter: 1}},
 				}
-				service.EXPECT().StoreManyWithRetry(gomock.Any(), m).Return(errStoreMany)
+				service.EXPECT().StoreBatch(gomock.Any(), "", m).Return(false, errStoreMany)
 				return service
 			}(),
 			method:       http.MethodPost,
@@ -596,6 +785,9 @@ func TestUpdateManyJSON(t *testing.T) {
 			req.Method = http.MethodPost
 			req.URL = srv.URL
 			req.Body = tc.body
+			if tc.batchID != "" {
+				req.SetHeader(middleware.BatchIDHeader, tc.batchID)
+			}
 
 			resp, err := req.Send()
 			assert.NoError(t, err, "error making HTTP request")
@@ -758,6 +950,34 @@ func TestGetJSON(t *testing.T) {
 	}
 }
 
+func TestGetJSONAge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	updatedAt := time.Now().Add(-time.Minute).UTC()
+	gauge := model.Gauge("test", 0.1)
+	gauge.UpdatedAt = updatedAt
+
+	service := mock_contracts.NewMockService(ctrl)
+	service.EXPECT().Get(gomock.Any(), "test").Return(gauge, nil)
+
+	h := NewMetricsHandler(zap.NewNop(), service, &mockPublisher{})
+	srv := httptest.NewServer(http.HandlerFunc(h.GetJSON))
+	defer srv.Close()
+
+	resp, err := resty.New().R().
+		SetBody(`{"id":"test","type":"gauge"}`).
+		Post(srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var got model.Metrics
+	require.NoError(t, easyjson.Unmarshal(resp.Body(), &got))
+	require.NotNil(t, got.UpdatedAt)
+	require.True(t, updatedAt.Equal(*got.UpdatedAt))
+	require.NotNil(t, got.Age)
+	require.GreaterOrEqual(t, *got.Age, 60.0)
+}
+
 func TestPing(t *testing.T) {
 	log := zap.NewNop()
 	ctrl := gomock.NewController(t)
@@ -810,3 +1030,725 @@ func TestPing(t *testing.T) {
 	}
 
 }
+
+func TestRange(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	from := time.Unix(1700000000, 0)
+	to := time.Unix(1700000060, 0)
+
+	testCases := []struct {
+		name         string
+		service      contracts.Service
+		query        string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "valid",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().Range(gomock.Any(), "gauge", from, to, 30*time.Second).Return([]model.Series{
+					{Name: "gauge", Type: "gauge", Points: []model.Point{{Timestamp: from.UTC(), Value: 0.1}}},
+				}, nil)
+				return service
+			}(),
+			query:        "?name=gauge&from=1700000000&to=1700000060&step=30s",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"name":"gauge","type":"gauge","points":[{"ts":"2023-11-14T22:13:20Z","value":0.1}]}]`,
+		},
+		{
+			name: "empty name",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			query:        "?from=1700000000",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "from after to",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			query:        "?name=gauge&from=1700000060&to=1700000000",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid step",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			query:        "?name=gauge&step=abc",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "not supported",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().Range(gomock.Any(), "gauge", gomock.Any(), gomock.Any(), time.Duration(0)).
+					Return(nil, repository.ErrNotSupported)
+				return service
+			}(),
+			query:        "?name=gauge",
+			expectedCode: http.StatusNotImplemented,
+		},
+		{
+			name: "range error",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().Range(gomock.Any(), "gauge", gomock.Any(), gomock.Any(), time.Duration(0)).
+					Return(nil, errGet)
+				return service
+			}(),
+			query:        "?name=gauge",
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				tc.service,
+				&mockPublisher{},
+			)
+			handler := http.HandlerFunc(h.Range)
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodGet
+			req.URL = srv.URL + tc.query
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.EqualValues(t, tc.expectedCode, resp.StatusCode())
+			if tc.expectedBody != "" {
+				require.JSONEq(t, tc.expectedBody, string(resp.Body()))
+			}
+		})
+	}
+}
+
+func TestPrometheus(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	testCases := []struct {
+		name                string
+		service             contracts.Service
+		expectedCode        int
+		expectedContentType string
+		expectedResponse    string
+	}{
+		{
+			name: "valid filled storage",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().GetAll(gomock.Any()).Return([]model.MetricDto{
+					{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.1}},
+					{Name: "poll.count", Value: model.MetricValue{Type: model.TypeCounter, Counter: 1}},
+				}, nil)
+				return service
+			}(),
+			expectedCode:        http.StatusOK,
+			expectedContentType: exposition.ContentType,
+			expectedResponse:    "# TYPE gauge gauge\ngauge 0.1\n# TYPE poll_count counter\npoll_count 1\n",
+		},
+		{
+			name: "get all error",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().GetAll(gomock.Any()).Return(nil, errGetAll)
+				return service
+			}(),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				tc.service,
+				&mockPublisher{},
+			)
+			handler := http.HandlerFunc(h.Prometheus)
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodGet
+			req.URL = srv.URL
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.Equal(t, tc.expectedCode, resp.StatusCode())
+			require.EqualValues(t, tc.expectedResponse, string(resp.Body()))
+			if tc.expectedContentType != "" {
+				require.Equal(t, tc.expectedContentType, resp.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	body := func() io.Reader {
+		req := pb.WriteRequest_builder{
+			Timeseries: []*pb.TimeSeries{
+				pb.TimeSeries_builder{
+					Labels: []*pb.Label{
+						pb.Label_builder{Name: "__name__", Value: "node_load1"}.Build(),
+						pb.Label_builder{Name: "instance", Value: "a"}.Build(),
+					},
+					Samples: []*pb.Sample{pb.Sample_builder{Value: 0.5, Timestamp: 1}.Build()},
+				}.Build(),
+			},
+		}.Build()
+		data, err := proto.Marshal(req)
+		require.NoError(t, err)
+		return bytes.NewReader(snappy.Encode(nil, data))
+	}
+
+	expected := []model.MetricDto{
+		{Name: "node_load1", Labels: model.Labels{"instance": "a"}, Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.5}},
+	}
+
+	testCases := []struct {
+		name         string
+		service      contracts.Service
+		body         io.Reader
+		expectedCode int
+	}{
+		{
+			name: "valid",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), expected).Return(nil)
+				return service
+			}(),
+			body:         body(),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "invalid body",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			body:         bytes.NewBufferString("not snappy"),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "too large",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			body:         bytes.NewReader(binary.AppendUvarint(nil, remotewrite.MaxDecodedSize+1)),
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "store error",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), expected).Return(errStoreMany)
+				return service
+			}(),
+			body:         body(),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				tc.service,
+				&mockPublisher{},
+			)
+			handler := http.HandlerFunc(h.RemoteWrite)
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodPost
+			req.URL = srv.URL
+			req.Body = tc.body
+			req.Header.Set("Content-Encoding", "snappy")
+			req.Header.Set("Content-Type", "application/x-protobuf")
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.EqualValues(t, tc.expectedCode, resp.StatusCode())
+		})
+	}
+}
+
+func TestLineProtocolWrite(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	testCases := []struct {
+		name         string
+		service      contracts.Service
+		influx       bool
+		body         string
+		expectedCode int
+	}{
+		{
+			name: "valid influx",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				m := []model.MetricDto{
+					{Name: "http_requests", Labels: model.Labels{"host": "web-1"}, Value: model.MetricValue{Type: model.TypeCounter, Counter: 3}},
+					{Name: "http_latency", Labels: model.Labels{"host": "web-1"}, Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.5}},
+				}
+				// накопленное значение счетчика ранее не сохранялось
+				service.EXPECT().Get(gomock.Any(), "http_requests", gomock.Any()).Return(model.MetricDto{}, repository.ErrNotFound)
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), m).Return(nil)
+				return service
+			}(),
+			influx:       true,
+			body:         "http,host=web-1 requests=3i,latency=0.5\n",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "valid graphite",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				m := []model.MetricDto{
+					{Name: "servers.web-1.load", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 1.5}},
+				}
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), m).Return(nil)
+				return service
+			}(),
+			body:         "servers.web-1.load 1.5 1700000000\n",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "invalid line",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			influx:       true,
+			body:         "http requests=3i\nbroken\n",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "store error",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), gomock.Any()).Return(errStoreMany)
+				return service
+			}(),
+			body:         "load 1\n",
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				tc.service,
+				&mockPublisher{},
+			)
+			handler := http.HandlerFunc(h.GraphiteWrite)
+			if tc.influx {
+				handler = h.InfluxWrite
+			}
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodPost
+			req.URL = srv.URL
+			req.Body = tc.body
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.EqualValues(t, tc.expectedCode, resp.StatusCode())
+		})
+	}
+}
+
+func TestOTLP(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	body := `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+		"scopeMetrics":[{"metrics":[{"name":"requests","sum":{"aggregationTemporality":1,"isMonotonic":true,"dataPoints":[{"asInt":"3"}]}}]}]}]}`
+
+	expected := []model.MetricDto{
+		{Name: "requests", Labels: model.Labels{"service_name": "api"}, Value: model.MetricValue{Type: model.TypeCounter, Counter: 3}},
+	}
+
+	testCases := []struct {
+		name         string
+		service      contracts.Service
+		contentType  string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "valid json",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), expected).Return(nil)
+				return service
+			}(),
+			contentType:  "application/json",
+			body:         body,
+			expectedCode: http.StatusOK,
+			expectedBody: `{}`,
+		},
+		{
+			name: "unsupported content type",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			contentType:  "text/plain",
+			body:         body,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "invalid body",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			contentType:  "application/x-protobuf",
+			body:         "not protobuf",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "too large",
+			service: func() contracts.Service {
+				return mock_contracts.NewMockService(ctrl)
+			}(),
+			contentType:  "application/x-protobuf",
+			body:         strings.Repeat("x", otlp.MaxBodySize+1),
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "store error",
+			service: func() contracts.Service {
+				service := mock_contracts.NewMockService(ctrl)
+				service.EXPECT().StoreManyWithRetry(gomock.Any(), expected).Return(errStoreMany)
+				return service
+			}(),
+			contentType:  "application/json",
+			body:         body,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				tc.service,
+				&mockPublisher{},
+			)
+			handler := http.HandlerFunc(h.OTLP)
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodPost
+			req.URL = srv.URL
+			req.Body = tc.body
+			req.Header.Set("Content-Type", tc.contentType)
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.EqualValues(t, tc.expectedCode, resp.StatusCode())
+			if tc.expectedBody != "" {
+				require.JSONEq(t, tc.expectedBody, string(resp.Body()))
+			}
+		})
+	}
+}
+
+type mockAlertLister struct {
+	alerts []alerting.Alert
+}
+
+func (m *mockAlertLister) Alerts() []alerting.Alert {
+	return m.alerts
+}
+
+func TestAlerts(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	activeAt := time.Unix(1700000000, 0).UTC()
+
+	testCases := []struct {
+		name         string
+		opts         []Option
+		expectedBody string
+	}{
+		{
+			name:         "disabled",
+			expectedBody: `[]`,
+		},
+		{
+			name: "firing",
+			opts: []Option{WithAlerts(&mockAlertLister{alerts: []alerting.Alert{
+				{
+					Rule:     "high_load",
+					Metric:   "load",
+					Severity: "critical",
+					Labels:   model.Labels{"alertname": "high_load", "severity": "critical"},
+					State:    alerting.StateFiring,
+					Value:    0.95,
+					ActiveAt: activeAt,
+					FiredAt:  &activeAt,
+				},
+			}})},
+			expectedBody: `[{"rule":"high_load","metric":"load","severity":"critical","labels":{"alertname":"high_load","severity":"critical"},` +
+				`"state":"firing","value":0.95,"active_at":"2023-11-14T22:13:20Z","fired_at":"2023-11-14T22:13:20Z"}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				mock_contracts.NewMockService(ctrl),
+				&mockPublisher{},
+				tc.opts...,
+			)
+			handler := http.HandlerFunc(h.Alerts)
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodGet
+			req.URL = srv.URL
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.EqualValues(t, http.StatusOK, resp.StatusCode())
+			require.JSONEq(t, tc.expectedBody, string(resp.Body()))
+		})
+	}
+}
+
+type mockBaselineLister struct {
+	baselines []alerting.Baseline
+}
+
+func (m *mockBaselineLister) Baselines() []alerting.Baseline {
+	return m.baselines
+}
+
+func TestBaselines(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	updatedAt := time.Unix(1700000000, 0).UTC()
+
+	testCases := []struct {
+		name         string
+		opts         []Option
+		expectedBody string
+	}{
+		{
+			name:         "disabled",
+			expectedBody: `[]`,
+		},
+		{
+			name:         "no series",
+			opts:         []Option{WithBaselines(&mockBaselineLister{})},
+			expectedBody: `[]`,
+		},
+		{
+			name: "anomalous",
+			opts: []Option{WithBaselines(&mockBaselineLister{baselines: []alerting.Baseline{
+				{
+					Rule:      "load_anomaly",
+					Metric:    "load",
+					Labels:    model.Labels{"host": "a"},
+					Value:     9,
+					Mean:      1,
+					StdDev:    2,
+					Lower:     -5,
+					Upper:     7,
+					ZScore:    4,
+					Samples:   30,
+					Bucket:    3,
+					Anomalous: true,
+					UpdatedAt: updatedAt,
+				},
+			}})},
+			expectedBody: `[{"rule":"load_anomaly","metric":"load","labels":{"host":"a"},"value":9,"mean":1,"stddev":2,` +
+				`"lower":-5,"upper":7,"z_score":4,"samples":30,"bucket":3,"anomalous":true,"updated_at":"2023-11-14T22:13:20Z"}]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMetricsHandler(
+				log,
+				mock_contracts.NewMockService(ctrl),
+				&mockPublisher{},
+				tc.opts...,
+			)
+			handler := http.HandlerFunc(h.Baselines)
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			req := resty.New().R()
+			req.Method = http.MethodGet
+			req.URL = srv.URL
+
+			resp, err := req.Send()
+			assert.NoError(t, err, "error making HTTP request")
+
+			require.EqualValues(t, http.StatusOK, resp.StatusCode())
+			require.JSONEq(t, tc.expectedBody, string(resp.Body()))
+		})
+	}
+}
+
+func TestSilences(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	manager, err := silence.NewManager(context.Background(), nil)
+	require.NoError(t, err)
+
+	h := NewMetricsHandler(log, mock_contracts.NewMockService(ctrl), &mockPublisher{}, WithSilences(manager))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/silences", h.ListSilences)
+	mux.HandleFunc("POST /api/v1/silences", h.CreateSilence)
+	mux.HandleFunc("GET /api/v1/silences/{id}", h.GetSilence)
+	mux.HandleFunc("PUT /api/v1/silences/{id}", h.UpdateSilence)
+	mux.HandleFunc("DELETE /api/v1/silences/{id}", h.DeleteSilence)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := resty.New().SetBaseURL(srv.URL)
+	endsAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	resp, err := client.R().
+		SetBody(`{"matchers":["host=\"web-1\""],"created_by":"ops"}`).
+		Post("/api/v1/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), "silence without ends_at")
+
+	resp, err = client.R().SetBody(`{"matchers":`).Post("/api/v1/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), "malformed body")
+
+	resp, err = client.R().
+		SetBody(`{"matchers":["host=\"web-1\""],"ends_at":"` + endsAt + `","created_by":"ops","comment":"upgrade"}`).
+		Post("/api/v1/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	var created silence.Silence
+	require.NoError(t, easyjson.Unmarshal(resp.Body(), &created))
+	require.NotEmpty(t, created.ID)
+	require.Equal(t, silence.StatusActive, created.Status)
+	require.Equal(t, "upgrade", created.Comment)
+
+	resp, err = client.R().Get("/api/v1/silences/" + created.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().
+		SetBody(`{"matchers":["host=\"web-2\""],"ends_at":"` + endsAt + `"}`).
+		Put("/api/v1/silences/" + created.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().Get("/api/v1/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	var list silence.Silences
+	require.NoError(t, easyjson.Unmarshal(resp.Body(), &list))
+	require.Len(t, list, 1)
+	require.Equal(t, []string{`host="web-2"`}, list[0].Matchers)
+	require.Equal(t, "ops", list[0].CreatedBy)
+
+	resp, err = client.R().Delete("/api/v1/silences/" + created.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = client.R().Get("/api/v1/silences/" + created.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	resp, err = client.R().Delete("/api/v1/silences/" + created.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+}
+
+func TestSilencesCreatedBy(t *testing.T) {
+	log := zap.NewNop()
+	ctrl := gomock.NewController(t)
+
+	manager, err := silence.NewManager(context.Background(), nil)
+	require.NoError(t, err)
+
+	h := NewMetricsHandler(log, mock_contracts.NewMockService(ctrl), &mockPublisher{}, WithSilences(manager))
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/silences", h.CreateSilence)
+	mux.HandleFunc("PUT /api/v1/silences/{id}", h.UpdateSilence)
+	// агент передается тестовым заголовком вместо аутентификации
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(identity.NewContext(r.Context(), r.Header.Get("X-Agent"))))
+	}))
+	defer srv.Close()
+
+	client := resty.New().SetBaseURL(srv.URL)
+	endsAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	// автор из тела запроса заменяется аутентифицированным агентом
+	resp, err := client.R().
+		SetHeader("X-Agent", "ops").
+		SetBody(`{"matchers":["host=\"web-1\""],"ends_at":"` + endsAt + `","created_by":"admin"}`).
+		Post("/api/v1/silences")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode())
+
+	var created silence.Silence
+	require.NoError(t, easyjson.Unmarshal(resp.Body(), &created))
+	require.Equal(t, "ops", created.CreatedBy)
+
+	resp, err = client.R().
+		SetHeader("X-Agent", "dev").
+		SetBody(`{"matchers":["host=\"web-2\""],"ends_at":"` + endsAt + `"}`).
+		Put("/api/v1/silences/" + created.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	var updated silence.Silence
+	require.NoError(t, easyjson.Unmarshal(resp.Body(), &updated))
+	require.Equal(t, "dev", updated.CreatedBy)
+}
+
+func TestSilencesDisabled(t *testing.T) {
+	h := NewMetricsHandler(zap.NewNop(), mock_contracts.NewMockService(gomock.NewController(t)), &mockPublisher{})
+
+	rw := httptest.NewRecorder()
+	h.ListSilences(rw, httptest.NewRequest(http.MethodGet, "/api/v1/silences", nil))
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.JSONEq(t, `[]`, rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	h.CreateSilence(rw, httptest.NewRequest(http.MethodPost, "/api/v1/silences", bytes.NewBufferString(`{}`)))
+	require.Equal(t, http.StatusNotFound, rw.Code)
+}
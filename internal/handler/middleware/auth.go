@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/auth"
+	"github.com/htrandev/metrics/internal/identity"
+)
+
+// Auth возвращает HTTP middleware для аутентификации агентов по токену
+// из заголовка Authorization: Bearer <token>.
+// Агенту без права scope возвращается 403. Если реестр не задан, запрос
+// передается дальше без проверки.
+func Auth(reg auth.Registry, scope auth.Scope, logger *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if reg == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := auth.BearerToken(r.Header.Get("Authorization"))
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			p, err := reg.Lookup(r.Context(), token)
+			if errors.Is(err, auth.ErrUnknownToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				logger.Error("lookup token",
+					zap.Error(err),
+					zap.String("scope", "middleware"),
+					zap.String("method", "auth"),
+				)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			if !p.Allows(scope) {
+				logger.Debug("insufficient scope",
+					zap.String("agent", p.Agent),
+					zap.String("required", string(scope)),
+					zap.String("scope", "middleware"),
+					zap.String("method", "auth"),
+				)
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			ctx := auth.NewContext(r.Context(), p)
+			ctx = identity.NewContext(ctx, p.Agent)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
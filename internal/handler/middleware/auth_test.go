@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/auth"
+	"github.com/htrandev/metrics/internal/identity"
+)
+
+type mockRegistry map[string]auth.Principal
+
+func (m mockRegistry) Lookup(_ context.Context, token string) (auth.Principal, error) {
+	p, ok := m[token]
+	if !ok {
+		return auth.Principal{}, auth.ErrUnknownToken
+	}
+	return p, nil
+}
+
+func TestAuth(t *testing.T) {
+	reg := mockRegistry{
+		"writer": {Agent: "agent-1", Scopes: []auth.Scope{auth.ScopeWrite}},
+		"reader": {Agent: "dashboard", Scopes: []auth.Scope{auth.ScopeRead}},
+		"admin":  {Agent: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}},
+	}
+
+	testCases := []struct {
+		name             string
+		registry         auth.Registry
+		header           string
+		expectedCode     int
+		expectedIdentity string
+	}{
+		{
+			name:             "write scope",
+			registry:         reg,
+			header:           "Bearer writer",
+			expectedCode:     http.StatusOK,
+			expectedIdentity: "agent-1",
+		},
+		{
+			name:             "admin scope",
+			registry:         reg,
+			header:           "Bearer admin",
+			expectedCode:     http.StatusOK,
+			expectedIdentity: "ops",
+		},
+		{
+			name:         "insufficient scope",
+			registry:     reg,
+			header:       "Bearer reader",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "unknown token",
+			registry:     reg,
+			header:       "Bearer other",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "missing token",
+			registry:     reg,
+			header:       "",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "auth disabled",
+			registry:     nil,
+			header:       "",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotIdentity string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIdentity = identity.FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/updates/", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			Auth(tc.registry, auth.ScopeWrite, zap.NewNop())(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedCode, rec.Code)
+			require.Equal(t, tc.expectedIdentity, gotIdentity)
+		})
+	}
+}
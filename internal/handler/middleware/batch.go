@@ -0,0 +1,4 @@
+package middleware
+
+// BatchIDHeader содержит идентификатор пакета метрик для дедупликации повторных отправок.
+const BatchIDHeader = "X-Batch-ID"
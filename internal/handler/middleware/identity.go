@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/htrandev/metrics/internal/identity"
+)
+
+// CertIdentity возвращает HTTP middleware, определяющий агента по проверенному
+// клиентскому сертификату вместо заголовка X-Real-IP.
+func CertIdentity() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id, ok := identity.FromTLS(r.TLS); ok {
+				r = r.WithContext(identity.NewContext(r.Context(), id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
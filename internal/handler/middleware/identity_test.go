@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/identity"
+)
+
+func TestCertIdentity(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "agent-1"}}
+
+	testCases := []struct {
+		name             string
+		tls              *tls.ConnectionState
+		expectedIdentity string
+	}{
+		{
+			name:             "verified certificate",
+			tls:              &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			expectedIdentity: "agent-1",
+		},
+		{
+			name:             "unverified certificate",
+			tls:              &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+			expectedIdentity: "",
+		},
+		{
+			name:             "plain connection",
+			tls:              nil,
+			expectedIdentity: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotIdentity string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIdentity = identity.FromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.TLS = tc.tls
+
+			CertIdentity()(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Equal(t, tc.expectedIdentity, gotIdentity)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader содержит идентификатор запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen максимальная длина идентификатора запроса, переданного клиентом.
+const maxRequestIDLen = 128
+
+// RequestID возвращает HTTP middleware, назначающий запросу идентификатор.
+// Идентификатор клиента из заголовка X-Request-ID сохраняется, если он корректен,
+// иначе генерируется новый. Идентификатор возвращается в заголовке ответа.
+func RequestID() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !ValidRequestID(id) {
+				id = uuid.NewString()
+				r.Header.Set(RequestIDHeader, id)
+			}
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ValidRequestID сообщает, можно ли использовать переданный клиентом идентификатор запроса.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		id         string
+		expectSame bool
+	}{
+		{
+			name:       "client id",
+			id:         "req-1",
+			expectSame: true,
+		},
+		{
+			name: "missing id",
+			id:   "",
+		},
+		{
+			name: "too long id",
+			id:   strings.Repeat("a", maxRequestIDLen+1),
+		},
+		{
+			name: "id with spaces",
+			id:   "req 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotID string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotID = r.Header.Get(RequestIDHeader)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.id != "" {
+				req.Header.Set(RequestIDHeader, tc.id)
+			}
+			rec := httptest.NewRecorder()
+
+			RequestID()(next).ServeHTTP(rec, req)
+
+			require.NotEmpty(t, gotID)
+			require.Equal(t, gotID, rec.Header().Get(RequestIDHeader))
+			if tc.expectSame {
+				require.Equal(t, tc.id, gotID)
+			} else {
+				require.NotEqual(t, tc.id, gotID)
+			}
+		})
+	}
+}
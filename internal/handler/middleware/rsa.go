@@ -11,6 +11,10 @@ import (
 	"github.com/htrandev/metrics/pkg/crypto"
 )
 
+// RSA возвращает HTTP middleware для расшифровки тела запроса.
+// Тело передается в формате конверта crypto.Encrypt: ключ данных AES-256-GCM,
+// зашифрованный RSA-OAEP, и данные, зашифрованные этим ключом.
+// Если ключ не задан, запрос передается дальше без изменений.
 func RSA(key *rsa.PrivateKey, logger *zap.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -35,6 +39,11 @@ func RSA(key *rsa.PrivateKey, logger *zap.Logger) func(next http.Handler) http.H
 
 			body, err := crypto.Decrypt(key, buf.Bytes())
 			if err != nil {
+				logger.Debug("decrypt body",
+					zap.Error(err),
+					zap.String("scope", "middleware"),
+					zap.String("method", "rsa"),
+				)
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/pkg/crypto"
+)
+
+func TestRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// батч заметно больше предела RSA-OAEP для 2048-битного ключа
+	payload := bytes.Repeat([]byte(`{"id":"gauge","type":"gauge","value":0.1},`), 100)
+
+	encrypted, err := crypto.Encrypt(&key.PublicKey, payload)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		key          *rsa.PrivateKey
+		body         []byte
+		expectedCode int
+		expectedBody []byte
+	}{
+		{
+			name:         "valid",
+			key:          key,
+			body:         encrypted,
+			expectedCode: http.StatusOK,
+			expectedBody: payload,
+		},
+		{
+			name:         "without key",
+			key:          nil,
+			body:         payload,
+			expectedCode: http.StatusOK,
+			expectedBody: payload,
+		},
+		{
+			name:         "not encrypted",
+			key:          key,
+			body:         payload,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []byte
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body))
+
+			RSA(tc.key, zap.NewNop())(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedCode, rec.Code)
+			require.Equal(t, tc.expectedBody, got)
+		})
+	}
+}
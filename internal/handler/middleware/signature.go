@@ -2,30 +2,88 @@ package middleware
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/htrandev/metrics/pkg/sign"
 	"go.uber.org/zap"
 )
 
+// LegacyHashHeader заголовок подписи прежней схемы: HMAC-SHA256 тела запроса
+// в base64 без дополнения.
+const LegacyHashHeader = "HashSHA256"
+
+// signOptions параметры проверки подписи.
+type signOptions struct {
+	allowUnsigned bool
+
+	legacyKey   sign.Signature
+	legacyUntil time.Time
+	now         func() time.Time
+}
+
+// SignOption настраивает проверку подписи.
+type SignOption func(*signOptions)
+
+// AllowUnsigned пропускает неподписанные запросы на запись.
+// Подписанные запросы по-прежнему проверяются.
+func AllowUnsigned() SignOption {
+	return func(o *signOptions) {
+		o.allowUnsigned = true
+	}
+}
+
+// WithLegacy задает переходный период до until, в течение которого запросы без подписи
+// X-Signature-* принимаются по прежней схеме: подпись в заголовке HashSHA256 проверяется
+// ключом key, запросы без подписи пропускаются. Такие запросы записываются в лог как устаревшие.
+func WithLegacy(key sign.Signature, until time.Time) SignOption {
+	return func(o *signOptions) {
+		o.legacyKey = key
+		o.legacyUntil = until
+	}
+}
+
 // Sign возвращает HTTP middleware для проверки подписи запросов.
-func Sign(key string, logger *zap.Logger) func(next http.Handler) http.Handler {
+// Подпись передается в заголовках X-Signature-* и проверяется набором активных
+// ключей верификатора с защитой от повторов.
+// Неподписанные запросы на чтение пропускаются, на запись - отклоняются, если не задан AllowUnsigned
+// и не идет переходный период WithLegacy.
+func Sign(v *sign.Verifier, logger *zap.Logger, opts ...SignOption) func(next http.Handler) http.Handler {
+	o := signOptions{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			rw := &responseWriter{
 				ResponseWriter: w,
 			}
 
-			if len(key) == 0 {
+			if !v.Enabled() {
 				next.ServeHTTP(rw, r)
 				return
 			}
 
-			receivedHash := r.Header.Get("HashSHA256")
-			if receivedHash == "" {
-				next.ServeHTTP(rw, r)
+			st, signed, err := sign.StampFromHeader(r.Header)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if !signed {
+				if o.allowUnsigned || r.Method == http.MethodGet || r.Method == http.MethodHead {
+					next.ServeHTTP(rw, r)
+					return
+				}
+				if o.now().Before(o.legacyUntil) {
+					verifyLegacy(o.legacyKey, o.legacyUntil, logger, next).ServeHTTP(rw, r)
+					return
+				}
+				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
 
@@ -42,15 +100,63 @@ func Sign(key string, logger *zap.Logger) func(next http.Handler) http.Handler {
 
 			r.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
 
-			s := sign.Signature(key)
-			signature := s.Sign(buf.Bytes())
-			gotHash := base64.RawURLEncoding.EncodeToString(signature)
-
-			if receivedHash != gotHash {
-				w.WriteHeader(http.StatusBadRequest)
+			if err := v.Verify(st, SignTarget(r.Method, r.URL.RequestURI()), buf.Bytes()); err != nil {
+				logger.Debug("verify signature",
+					zap.Error(err),
+					zap.String("key_id", st.KeyID),
+					zap.String("scope", "middleware"),
+					zap.String("method", "sign"),
+				)
+				if errors.Is(err, sign.ErrMalformed) {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
 			next.ServeHTTP(rw, r)
 		})
 	}
 }
+
+// verifyLegacy проверяет запрос по прежней схеме подписи HashSHA256.
+// Запросы без подписи пропускаются, как до перехода на X-Signature-*.
+func verifyLegacy(key sign.Signature, until time.Time, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields := []zap.Field{
+			zap.String("uri", r.URL.RequestURI()),
+			zap.String("remote_addr", r.RemoteAddr),
+			zap.Time("legacy_until", until),
+			zap.String("scope", "middleware"),
+			zap.String("method", "sign"),
+		}
+
+		received := r.Header.Get(LegacyHashHeader)
+		if received == "" || len(key) == 0 {
+			logger.Warn("deprecated unsigned request accepted during signature transition", fields...)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(r.Body); err != nil {
+			logger.Error("read body", append(fields, zap.Error(err))...)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(buf.Bytes()))
+
+		expected := base64.RawURLEncoding.EncodeToString(key.Sign(buf.Bytes()))
+		if subtle.ConstantTimeCompare([]byte(received), []byte(expected)) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		logger.Warn("deprecated "+LegacyHashHeader+" signature accepted during signature transition", fields...)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SignTarget возвращает адресат HTTP запроса, покрываемый подписью.
+func SignTarget(method, requestURI string) string {
+	return method + " " + requestURI
+}
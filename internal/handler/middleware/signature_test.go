@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/pkg/sign"
+)
+
+func TestSign(t *testing.T) {
+	keys := sign.Keyring{"k1": sign.Signature("secret")}
+	body := []byte(`[{"id":"gauge","type":"gauge","value":0.1}]`)
+
+	signed := func(t *testing.T, keyID string, key sign.Signature) http.Header {
+		st, err := sign.NewStamp(keyID, key, SignTarget(http.MethodPost, "/updates/"), body)
+		require.NoError(t, err)
+		h := http.Header{}
+		st.SetHeader(h)
+		return h
+	}
+
+	testCases := []struct {
+		name         string
+		verifier     *sign.Verifier
+		opts         []SignOption
+		method       string
+		header       func(t *testing.T) http.Header
+		expectedCode int
+	}{
+		{
+			name:         "signature disabled",
+			verifier:     sign.NewVerifier(nil, time.Minute),
+			method:       http.MethodPost,
+			header:       func(t *testing.T) http.Header { return http.Header{} },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "valid",
+			verifier:     sign.NewVerifier(keys, time.Minute),
+			method:       http.MethodPost,
+			header:       func(t *testing.T) http.Header { return signed(t, "k1", keys["k1"]) },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unsigned write",
+			verifier:     sign.NewVerifier(keys, time.Minute),
+			method:       http.MethodPost,
+			header:       func(t *testing.T) http.Header { return http.Header{} },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unsigned write allowed",
+			verifier:     sign.NewVerifier(keys, time.Minute),
+			opts:         []SignOption{AllowUnsigned()},
+			method:       http.MethodPost,
+			header:       func(t *testing.T) http.Header { return http.Header{} },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid signature with unsigned allowed",
+			verifier:     sign.NewVerifier(keys, time.Minute),
+			opts:         []SignOption{AllowUnsigned()},
+			method:       http.MethodPost,
+			header:       func(t *testing.T) http.Header { return signed(t, "k1", sign.Signature("wrong")) },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unsigned read",
+			verifier:     sign.NewVerifier(keys, time.Minute),
+			method:       http.MethodGet,
+			header:       func(t *testing.T) http.Header { return http.Header{} },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unknown key",
+			verifier:     sign.NewVerifier(keys, time.Minute),
+			method:       http.MethodPost,
+			header:       func(t *testing.T) http.Header { return signed(t, "k2", keys["k1"]) },
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "malformed timestamp",
+			verifier: sign.NewVerifier(keys, time.Minute),
+			method:   http.MethodPost,
+			header: func(t *testing.T) http.Header {
+				h := signed(t, "k1", keys["k1"])
+				h.Set(sign.HeaderTimestamp, "yesterday")
+				return h
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, "/updates/", bytes.NewReader(body))
+			for k, v := range tc.header(t) {
+				req.Header[k] = v
+			}
+
+			Sign(tc.verifier, zap.NewNop(), tc.opts...)(dummyHandler()).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		v := sign.NewVerifier(keys, time.Minute)
+		h := signed(t, "k1", keys["k1"])
+
+		codes := make([]int, 0, 2)
+		for range 2 {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(body))
+			for k, v := range h {
+				req.Header[k] = v
+			}
+			Sign(v, zap.NewNop())(dummyHandler()).ServeHTTP(rec, req)
+			codes = append(codes, rec.Code)
+		}
+		require.Equal(t, []int{http.StatusOK, http.StatusUnauthorized}, codes)
+	})
+}
+
+func TestSignLegacy(t *testing.T) {
+	keys := sign.Keyring{sign.DefaultKeyID: sign.Signature("secret")}
+	body := []byte(`[{"id":"gauge","type":"gauge","value":0.1}]`)
+	legacyHash := base64.RawURLEncoding.EncodeToString(keys[sign.DefaultKeyID].Sign(body))
+
+	testCases := []struct {
+		name         string
+		until        time.Time
+		hash         string
+		expectedCode int
+	}{
+		{name: "legacy signature", until: time.Now().Add(time.Hour), hash: legacyHash, expectedCode: http.StatusOK},
+		{name: "unsigned", until: time.Now().Add(time.Hour), expectedCode: http.StatusOK},
+		{name: "invalid legacy signature", until: time.Now().Add(time.Hour), hash: "invalid", expectedCode: http.StatusBadRequest},
+		{name: "transition over", until: time.Now().Add(-time.Hour), hash: legacyHash, expectedCode: http.StatusUnauthorized},
+		{name: "transition disabled", hash: legacyHash, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/updates/", bytes.NewReader(body))
+			if tc.hash != "" {
+				req.Header.Set(LegacyHashHeader, tc.hash)
+			}
+
+			v := sign.NewVerifier(keys, time.Minute)
+			Sign(v, zap.NewNop(), WithLegacy(keys[sign.DefaultKeyID], tc.until))(dummyHandler()).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
@@ -3,26 +3,56 @@ package middleware
 import (
 	"net"
 	"net/http"
+
+	"github.com/htrandev/metrics/pkg/netutil"
 )
 
 const (
 	IPHeader = "X-Real-IP"
+	// ForwardedForHeader содержит цепочку адресов, добавленных прокси.
+	ForwardedForHeader = "X-Forwarded-For"
 )
 
-func Subnet(subnet *net.IPNet) func(next http.Handler) http.Handler {
+// RealIP возвращает HTTP middleware, определяющий IP-адрес клиента по адресу
+// соединения и заголовку X-Forwarded-For, пройденному только через доверенные прокси.
+// Переданный клиентом заголовок X-Real-IP заменяется определенным адресом
+// или удаляется, если адрес определить не удалось.
+func RealIP(proxies netutil.Subnets) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			rw := &responseWriter{
-				ResponseWriter: w,
+			ip := netutil.ClientIP(r.RemoteAddr, r.Header.Values(ForwardedForHeader), proxies)
+			if ip == nil {
+				r.Header.Del(IPHeader)
+			} else {
+				r.Header.Set(IPHeader, ip.String())
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
+// Subnet возвращает HTTP middleware, пропускающий только запросы из доверенных подсетей.
+// IP-адрес клиента берется из заголовка X-Real-IP, установленного RealIP.
+// Запросы без IP-адреса пропускаются, если не включен строгий режим strict.
+func Subnet(subnets netutil.Subnets, strict bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ip := net.ParseIP(r.Header.Get(IPHeader))
-			if len(ip) != 0 && !subnet.Contains(ip) {
+			if len(ip) == 0 {
+				if strict {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !subnets.Contains(ip) {
 				w.WriteHeader(http.StatusForbidden)
 				return
 			}
 
-			next.ServeHTTP(rw, r)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
@@ -1,21 +1,25 @@
 package middleware
 
 import (
-	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/pkg/netutil"
 )
 
 func TestSubnet(t *testing.T) {
 	dummyHandler := dummyHandler()
-	cidr := "192.168.1.0/24"
+
+	subnets, err := netutil.ParseCIDRs("192.168.1.0/24,fd00::/8")
+	require.NoError(t, err)
 
 	testCases := []struct {
 		name         string
 		ip           string
+		strict       bool
 		expectedCode int
 	}{
 		{
@@ -23,26 +27,101 @@ func TestSubnet(t *testing.T) {
 			ip:           "192.168.1.101",
 			expectedCode: http.StatusOK,
 		},
+		{
+			name:         "valid ipv6",
+			ip:           "fd00::1",
+			expectedCode: http.StatusOK,
+		},
 		{
 			name:         "not trusted",
 			ip:           "10.0.0.1",
 			expectedCode: http.StatusForbidden,
 		},
+		{
+			name:         "no ip",
+			ip:           "",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "no ip strict",
+			ip:           "",
+			strict:       true,
+			expectedCode: http.StatusForbidden,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			rec := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
-			req.Header.Set(IPHeader, tc.ip)
-
-			_, subnet, err := net.ParseCIDR(cidr)
-			require.NoError(t, err)
+			if tc.ip != "" {
+				req.Header.Set(IPHeader, tc.ip)
+			}
 
-			wrapper := Subnet(subnet)
+			wrapper := Subnet(subnets, tc.strict)
 			wrapper(dummyHandler).ServeHTTP(rec, req)
 
 			require.Equal(t, tc.expectedCode, rec.Code)
 		})
 	}
 }
+
+func TestRealIP(t *testing.T) {
+	proxies, err := netutil.ParseCIDRs("10.0.0.0/8")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		realIP       string
+		forwardedFor string
+		expectedIP   string
+	}{
+		{
+			name:       "spoofed real ip",
+			remoteAddr: "172.16.0.5:4321",
+			realIP:     "192.168.1.1",
+			expectedIP: "172.16.0.5",
+		},
+		{
+			name:         "trusted proxy",
+			remoteAddr:   "10.0.0.2:4321",
+			forwardedFor: "192.168.1.1",
+			expectedIP:   "192.168.1.1",
+		},
+		{
+			name:         "untrusted proxy",
+			remoteAddr:   "172.16.0.5:4321",
+			forwardedFor: "192.168.1.1",
+			expectedIP:   "172.16.0.5",
+		},
+		{
+			name:       "unresolvable",
+			remoteAddr: "@",
+			realIP:     "192.168.1.1",
+			expectedIP: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotIP string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIP = r.Header.Get(IPHeader)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.realIP != "" {
+				req.Header.Set(IPHeader, tc.realIP)
+			}
+			if tc.forwardedFor != "" {
+				req.Header.Set(ForwardedForHeader, tc.forwardedFor)
+			}
+
+			RealIP(proxies)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Equal(t, tc.expectedIP, gotIP)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/otlp"
+)
+
+// OTLP обрабатывает HTTP POST /v1/metrics с телом ExportMetricsServiceRequest
+// в формате protobuf или JSON в зависимости от Content-Type.
+// Ответ ExportMetricsServiceResponse кодируется в том же формате,
+// неподдерживаемые точки перечисляются в partial_success.
+func (h *MetricHandler) OTLP(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	scope := zap.String("scope", "handler/OTLP")
+
+	mediaType, err := otlp.MediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		h.logger.Error("parse content type", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, otlp.MaxBodySize))
+	if err != nil {
+		h.logger.Error("read body", zap.Error(err), scope)
+		rw.WriteHeader(bodyErrorStatus(err))
+		return
+	}
+
+	req, err := otlp.Decode(body, mediaType)
+	if err != nil {
+		h.logger.Error("decode export request", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.otlp.Convert(ctx, req, h.service)
+	if err != nil {
+		h.logger.Error("convert export request", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer h.otlp.Release(batch)
+
+	m := batch.Metrics
+	if len(m) > 0 {
+		err := h.service.StoreManyWithRetry(ctx, m)
+		h.Publisher.Update(ctx, buildAuditInfoMessage(r, m, err))
+		if err != nil {
+			h.logger.Error("store many with retry", zap.Error(err), scope)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}
+	h.otlp.Commit(batch)
+
+	resp := &colmetricspb.ExportMetricsServiceResponse{}
+	if batch.Rejected > 0 {
+		resp.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: batch.Rejected,
+			ErrorMessage:       fmt.Sprintf("%d data points of unsupported types", batch.Rejected),
+		}
+	}
+
+	data, err := otlp.Encode(resp, mediaType)
+	if err != nil {
+		h.logger.Error("encode export response", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", mediaType)
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(data)
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/exposition"
+)
+
+// Prometheus обрабатывает HTTP GET /metrics для выгрузки всех метрик
+// в текстовом формате экспозиции Prometheus.
+// Параметры запроса match фильтруют серии по меткам.
+func (h *MetricHandler) Prometheus(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	scope := zap.String("scope", "handler/Prometheus")
+
+	matchers, err := buildMatchers(r)
+	if err != nil {
+		h.logger.Error("build matchers", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := h.service.GetAll(ctx, matchers...)
+	if err != nil {
+		h.logger.Error("get all metrics", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", exposition.ContentType)
+	rw.WriteHeader(http.StatusOK)
+
+	skipped, err := exposition.Write(rw, metrics)
+	if err != nil {
+		h.logger.Error("write exposition", zap.Error(err), scope)
+		return
+	}
+	for _, metric := range skipped {
+		h.logger.Warn("skip metric with conflicting type", zap.String("name", metric.Key()), scope)
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/internal/repository"
+)
+
+// Range обрабатывает HTTP GET /api/v1/range для получения истории значений метрики в JSON.
+//
+// Параметры запроса:
+//   - name - имя метрики;
+//   - from, to - границы интервала в формате RFC3339 или unix-времени в секундах,
+//     по умолчанию последний час;
+//   - step - шаг прореживания, например 30s, по умолчанию возвращаются все значения;
+//   - match - матчеры меток.
+func (h *MetricHandler) Range(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	scope := zap.String("scope", "handler/Range")
+
+	req, err := buildRangeRequest(r)
+	if err != nil {
+		h.logger.Error("build range request", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.service.Range(ctx, req.name, req.from, req.to, req.step, req.matchers...)
+	if errors.Is(err, repository.ErrNotSupported) {
+		h.logger.Error("range is not supported", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		h.logger.Error("range from storage", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	body, err := easyjson.Marshal(model.SeriesSlice(series))
+	if err != nil {
+		h.logger.Error("marshal response", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(body)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/remotewrite"
+)
+
+// RemoteWrite обрабатывает HTTP POST /api/v1/write с телом Prometheus remote_write:
+// WriteRequest в формате protobuf, сжатый snappy.
+// Серии сохраняются как gauge или как counter в зависимости от имени и метаданных.
+func (h *MetricHandler) RemoteWrite(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	scope := zap.String("scope", "handler/RemoteWrite")
+
+	req, err := remotewrite.Decode(http.MaxBytesReader(rw, r.Body, remotewrite.MaxBodySize))
+	if err != nil {
+		h.logger.Error("decode write request", zap.Error(err), scope)
+		rw.WriteHeader(bodyErrorStatus(err, remotewrite.ErrTooLarge))
+		return
+	}
+
+	batch, err := h.remoteWrite.Convert(ctx, req, h.service)
+	if err != nil {
+		h.logger.Error("convert write request", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer h.remoteWrite.Release(batch)
+
+	m := batch.Metrics
+	if len(m) == 0 {
+		h.logger.Debug("receive empty metrics batch", scope)
+		h.remoteWrite.Commit(batch)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err = h.service.StoreManyWithRetry(ctx, m)
+	h.Publisher.Update(ctx, buildAuditInfoMessage(r, m, err))
+	if err != nil {
+		h.logger.Error("store many with retry", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.remoteWrite.Commit(batch)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// bodyErrorStatus возвращает статус ответа на ошибку чтения тела запроса:
+// 413, если тело превышает допустимый размер или err соответствует одной из tooLarge, иначе 400.
+func bodyErrorStatus(err error, tooLarge ...error) int {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return http.StatusRequestEntityTooLarge
+	}
+	for _, target := range tooLarge {
+		if errors.Is(err, target) {
+			return http.StatusRequestEntityTooLarge
+		}
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/identity"
+	"github.com/htrandev/metrics/internal/silence"
+)
+
+// SilenceManager управляет заглушениями оповещений.
+type SilenceManager interface {
+	List() []silence.Silence
+	Get(id string) (silence.Silence, error)
+	Create(ctx context.Context, s silence.Silence) (silence.Silence, error)
+	Update(ctx context.Context, id string, s silence.Silence) (silence.Silence, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// WithSilences задает менеджер заглушений для /api/v1/silences.
+func WithSilences(m SilenceManager) Option {
+	return func(h *MetricHandler) {
+		h.silences = m
+	}
+}
+
+// ListSilences обрабатывает HTTP GET /api/v1/silences для получения всех заглушений в JSON.
+// Если заглушения не настроены, возвращается пустой список.
+func (h *MetricHandler) ListSilences(rw http.ResponseWriter, r *http.Request) {
+	silences := silence.Silences{}
+	if h.silences != nil {
+		silences = h.silences.List()
+	}
+	h.writeJSON(rw, http.StatusOK, silences, zap.String("scope", "handler/ListSilences"))
+}
+
+// GetSilence обрабатывает HTTP GET /api/v1/silences/{id} для получения заглушения в JSON.
+func (h *MetricHandler) GetSilence(rw http.ResponseWriter, r *http.Request) {
+	scope := zap.String("scope", "handler/GetSilence")
+
+	if h.silences == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s, err := h.silences.Get(r.PathValue("id"))
+	if err != nil {
+		h.logger.Error("get silence", zap.Error(err), scope)
+		rw.WriteHeader(silenceErrorStatus(err))
+		return
+	}
+	h.writeJSON(rw, http.StatusOK, s, scope)
+}
+
+// CreateSilence обрабатывает HTTP POST /api/v1/silences с JSON телом для создания заглушения.
+// Возвращает созданное заглушение с идентификатором.
+func (h *MetricHandler) CreateSilence(rw http.ResponseWriter, r *http.Request) {
+	scope := zap.String("scope", "handler/CreateSilence")
+
+	if h.silences == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	req, err := buildSilenceRequest(r)
+	if err != nil {
+		h.logger.Error("build silence request", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.silences.Create(r.Context(), req)
+	if err != nil {
+		h.logger.Error("create silence", zap.Error(err), scope)
+		rw.WriteHeader(silenceErrorStatus(err))
+		return
+	}
+	h.logger.Info("silence created", zap.String("id", s.ID), zap.Strings("matchers", s.Matchers), zap.String("created_by", s.CreatedBy), scope)
+	h.writeJSON(rw, http.StatusCreated, s, scope)
+}
+
+// UpdateSilence обрабатывает HTTP PUT /api/v1/silences/{id} с JSON телом для изменения заглушения.
+func (h *MetricHandler) UpdateSilence(rw http.ResponseWriter, r *http.Request) {
+	scope := zap.String("scope", "handler/UpdateSilence")
+
+	if h.silences == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	req, err := buildSilenceRequest(r)
+	if err != nil {
+		h.logger.Error("build silence request", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.silences.Update(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		h.logger.Error("update silence", zap.Error(err), scope)
+		rw.WriteHeader(silenceErrorStatus(err))
+		return
+	}
+	h.logger.Info("silence updated", zap.String("id", s.ID), scope)
+	h.writeJSON(rw, http.StatusOK, s, scope)
+}
+
+// DeleteSilence обрабатывает HTTP DELETE /api/v1/silences/{id} для удаления заглушения.
+func (h *MetricHandler) DeleteSilence(rw http.ResponseWriter, r *http.Request) {
+	scope := zap.String("scope", "handler/DeleteSilence")
+
+	if h.silences == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := h.silences.Delete(r.Context(), id); err != nil {
+		h.logger.Error("delete silence", zap.Error(err), scope)
+		rw.WriteHeader(silenceErrorStatus(err))
+		return
+	}
+	h.logger.Info("silence deleted", zap.String("id", id), scope)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func buildSilenceRequest(r *http.Request) (silence.Silence, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return silence.Silence{}, err
+	}
+	defer r.Body.Close()
+
+	var s silence.Silence
+	if err := easyjson.Unmarshal(body, &s); err != nil {
+		return silence.Silence{}, err
+	}
+	// автор определяется по аутентифицированному агенту, а не по телу запроса
+	if agent := identity.FromContext(r.Context()); agent != "" {
+		s.CreatedBy = agent
+	}
+	return s, nil
+}
+
+// silenceErrorStatus возвращает код ответа для ошибки менеджера заглушений.
+func silenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, silence.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, silence.ErrInvalidSilence):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON сериализует v и записывает ответ с кодом status.
+func (h *MetricHandler) writeJSON(rw http.ResponseWriter, status int, v easyjson.Marshaler, scope zap.Field) {
+	body, err := easyjson.Marshal(v)
+	if err != nil {
+		h.logger.Error("marshal response", zap.Error(err), scope)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	rw.Write(body)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mailru/easyjson"
 	"go.uber.org/zap"
 
+	"github.com/htrandev/metrics/internal/handler/middleware"
 	"github.com/htrandev/metrics/internal/model"
 )
 
@@ -50,7 +51,9 @@ func (h *MetricHandler) Update(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Store(ctx, metric); err != nil {
+	err := h.service.Store(ctx, metric)
+	h.Publisher.Update(ctx, buildAuditInfoMessage(r, []model.MetricDto{*metric}, err))
+	if err != nil {
 		h.logger.Error("store error", zap.Error(err), scope)
 		rw.WriteHeader(http.StatusBadRequest)
 		return
@@ -85,7 +88,9 @@ func (h *MetricHandler) UpdateJSON(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Store(ctx, m); err != nil {
+	err = h.service.Store(ctx, m)
+	h.Publisher.Update(ctx, buildAuditInfoMessage(r, []model.MetricDto{*m}, err))
+	if err != nil {
 		h.logger.Error("store error", zap.Error(err), scope)
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
@@ -108,7 +113,9 @@ func (h *MetricHandler) UpdateJSON(rw http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateManyJSON обрабатывает HTTP POST /updates/ с JSON массивом метрик.
-// Сохраняет батч метрик с повторными попытками и публикует событие.
+// Сохраняет батч метрик с повторными попытками и публикует событие с результатом.
+// Батч с уже обработанным идентификатором из заголовка X-Batch-ID
+// подтверждается без повторного сохранения и аудита.
 func (h *MetricHandler) UpdateManyJSON(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -127,25 +134,31 @@ func (h *MetricHandler) UpdateManyJSON(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.service.StoreManyWithRetry(ctx, m); err != nil {
-		h.logger.Error("store many with retry", zap.Error(err), scope)
+	batchID := r.Header.Get(middleware.BatchIDHeader)
+	applied, err := h.service.StoreBatch(ctx, batchID, m)
+	if err != nil {
+		h.logger.Error("store batch", zap.Error(err), scope)
+		h.Publisher.Update(ctx, buildAuditInfoMessage(r, m, err))
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	info := buildAuditInfoMessage(m, getIP(r))
-	h.Publisher.Update(ctx, info)
+	if !applied {
+		h.logger.Debug("skip duplicate batch", zap.String("batch_id", batchID), scope)
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	h.Publisher.Update(ctx, buildAuditInfoMessage(r, m, nil))
 
 	rw.Header().Set("Content-Type", "application/json")
 	rw.WriteHeader(http.StatusOK)
 }
 
+// getIP возвращает IP-адрес клиента, определенный middleware.RealIP.
 func getIP(r *http.Request) string {
-	ip := r.Header.Get("X-Real-Ip")
-	if ip == "" {
-		ip = r.Header.Get("X-Forwarded-For")
-	}
-
+	ip := r.Header.Get(middleware.IPHeader)
 	if ip == "" {
 		ip = r.RemoteAddr
 	}
@@ -0,0 +1,40 @@
+// Package identity определяет идентичность агента, отправившего запрос.
+package identity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+)
+
+type ctxKey struct{}
+
+// NewContext возвращает контекст с идентичностью агента.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext возвращает идентичность агента из контекста
+// или пустую строку, если она не установлена.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// FromCertificate возвращает идентичность по субъекту сертификата:
+// Common Name, а если он пуст, то субъект целиком.
+func FromCertificate(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+// FromTLS возвращает идентичность по проверенному клиентскому сертификату соединения.
+// Непроверенные сертификаты не учитываются.
+func FromTLS(state *tls.ConnectionState) (string, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return FromCertificate(state.VerifiedChains[0][0]), true
+}
@@ -0,0 +1,59 @@
+package lineproto
+
+import (
+	"context"
+
+	"github.com/htrandev/metrics/internal/cumulative"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// Batch метрики, подготовленные к сохранению.
+type Batch struct {
+	Metrics []model.MetricDto
+
+	counters *cumulative.Batch
+}
+
+// Converter преобразует накопленные значения счетчиков, например целочисленных полей
+// InfluxDB line protocol, в приросты, см. пакет cumulative. Остальные метрики не меняются.
+// Сохраненный пакет завершается вызовом Commit,
+// прирост несохраненного пакета возвращается вызовом Release.
+type Converter struct {
+	counters *cumulative.Tracker
+}
+
+// NewConverter создает Converter.
+func NewConverter() *Converter {
+	return &Converter{counters: cumulative.NewTracker()}
+}
+
+// Convert возвращает пакет, в котором значения счетчиков заменены приростами.
+// Счетчики без прироста не сохраняются. Текущие значения впервые встреченных
+// счетчиков запрашиваются у g, g может быть nil.
+func (c *Converter) Convert(ctx context.Context, metrics []model.MetricDto, g cumulative.Getter) *Batch {
+	batch := &Batch{
+		Metrics:  make([]model.MetricDto, 0, len(metrics)),
+		counters: c.counters.Begin(g),
+	}
+	for _, m := range metrics {
+		if m.Value.Type == model.TypeCounter {
+			m.Value.Counter = batch.counters.Delta(ctx, m, float64(m.Value.Counter))
+			if m.Value.Counter == 0 {
+				continue
+			}
+		}
+		batch.Metrics = append(batch.Metrics, m)
+	}
+	return batch
+}
+
+// Commit завершает сохраненный пакет.
+func (c *Converter) Commit(b *Batch) {
+	c.counters.Commit(b.counters)
+}
+
+// Release возвращает прирост счетчиков несохраненного пакета,
+// он будет учтен следующим значением серии. После Commit ничего не делает.
+func (c *Converter) Release(b *Batch) {
+	c.counters.Release(b.counters)
+}
@@ -0,0 +1,53 @@
+package lineproto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ParseGraphite разбирает строку Graphite plaintext вида path value [timestamp].
+// Поддерживается синтаксис тегов path;tag=value. Значение сохраняется как gauge,
+// время игнорируется: хранилище записывает время получения.
+func ParseGraphite(line string) ([]model.MetricDto, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("graphite %q: expected path value [timestamp]: %w", line, ErrInvalidLine)
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("graphite %q: value: %w", line, ErrInvalidLine)
+	}
+	if len(fields) == 3 {
+		if _, err := strconv.ParseFloat(fields[2], 64); err != nil {
+			return nil, fmt.Errorf("graphite %q: timestamp: %w", line, ErrInvalidLine)
+		}
+	}
+
+	path, tags, _ := strings.Cut(fields[0], ";")
+	if path == "" {
+		return nil, fmt.Errorf("graphite %q: empty path: %w", line, ErrInvalidLine)
+	}
+	if err := model.ValidateName(path); err != nil {
+		return nil, fmt.Errorf("graphite %q: path: %w", line, ErrInvalidLine)
+	}
+
+	metric := model.Gauge(path, value)
+	if tags != "" {
+		metric.Labels = make(model.Labels)
+		for _, tag := range strings.Split(tags, ";") {
+			name, v, ok := strings.Cut(tag, "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("graphite %q: tag %q: %w", line, tag, ErrInvalidLine)
+			}
+			metric.Labels[name] = v
+		}
+		if err := metric.Labels.Validate(); err != nil {
+			return nil, fmt.Errorf("graphite %q: tags: %w", line, ErrInvalidLine)
+		}
+	}
+	return []model.MetricDto{metric}, nil
+}
@@ -0,0 +1,137 @@
+package lineproto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ParseInflux разбирает строку InfluxDB line protocol вида
+// measurement[,tag=value...] field=value[,field=value...] [timestamp].
+//
+// Каждое поле становится отдельной метрикой с именем measurement_field, теги становятся метками.
+// Целочисленные поля становятся counter с накопленным значением, которое перед сохранением
+// преобразуется в прирост, см. Converter. Дробные и логические поля становятся gauge.
+// Строковые поля пропускаются. Время игнорируется.
+func ParseInflux(line string) ([]model.MetricDto, error) {
+	sections := split(line, ' ', true)
+	if len(sections) < 2 || len(sections) > 3 {
+		return nil, fmt.Errorf("influx %q: expected measurement fields [timestamp]: %w", line, ErrInvalidLine)
+	}
+	if len(sections) == 3 {
+		if _, err := strconv.ParseInt(sections[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("influx %q: timestamp: %w", line, ErrInvalidLine)
+		}
+	}
+
+	key := split(sections[0], ',', false)
+	measurement := unescape(key[0])
+	if measurement == "" {
+		return nil, fmt.Errorf("influx %q: empty measurement: %w", line, ErrInvalidLine)
+	}
+
+	var labels model.Labels
+	for _, tag := range key[1:] {
+		kv := split(tag, '=', false)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("influx %q: tag %q: %w", line, tag, ErrInvalidLine)
+		}
+		if labels == nil {
+			labels = make(model.Labels, len(key)-1)
+		}
+		labels[unescape(kv[0])] = unescape(kv[1])
+	}
+	if err := labels.Validate(); err != nil {
+		return nil, fmt.Errorf("influx %q: tags: %w", line, ErrInvalidLine)
+	}
+
+	fields := split(sections[1], ',', true)
+	metrics := make([]model.MetricDto, 0, len(fields))
+	for _, field := range fields {
+		kv := split(field, '=', true)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("influx %q: field %q: %w", line, field, ErrInvalidLine)
+		}
+
+		value, ok, err := fieldValue(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("influx %q: field %q: %w", line, field, err)
+		}
+		if !ok {
+			continue
+		}
+
+		value.Name = measurement + "_" + unescape(kv[0])
+		if err := model.ValidateName(value.Name); err != nil {
+			return nil, fmt.Errorf("influx %q: field %q: %w", line, field, ErrInvalidLine)
+		}
+		value.Labels = labels.Clone()
+		metrics = append(metrics, value)
+	}
+	return metrics, nil
+}
+
+// fieldValue преобразует значение поля в метрику без имени.
+// Возвращает false для строковых полей.
+func fieldValue(raw string) (model.MetricDto, bool, error) {
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return model.Gauge("", 1), true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return model.Gauge("", 0), true, nil
+	}
+
+	switch last := raw[len(raw)-1]; {
+	case raw[0] == '"':
+		if len(raw) < 2 || last != '"' {
+			return model.MetricDto{}, false, fmt.Errorf("unterminated string: %w", ErrInvalidLine)
+		}
+		return model.MetricDto{}, false, nil
+	case last == 'i' || last == 'u':
+		v, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return model.MetricDto{}, false, fmt.Errorf("integer: %w", ErrInvalidLine)
+		}
+		return model.Counter("", v), true, nil
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return model.MetricDto{}, false, fmt.Errorf("float: %w", ErrInvalidLine)
+	}
+	return model.Gauge("", v), true, nil
+}
+
+// split делит строку по разделителю sep, пропуская экранированные обратной косой чертой
+// символы и, если quoted, символы внутри двойных кавычек.
+func split(s string, sep byte, quoted bool) []string {
+	var (
+		parts    []string
+		start    int
+		inQuotes bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"' && quoted:
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape убирает экранирование запятых, пробелов, знаков равенства и кавычек.
+func unescape(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+	return unescaper.Replace(s)
+}
+
+var unescaper = strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=", `\"`, `"`, `\\`, `\`)
@@ -0,0 +1,300 @@
+package lineproto
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/pkg/netutil"
+)
+
+type mockStorer struct {
+	mu      sync.Mutex
+	metrics []model.MetricDto
+}
+
+func (m *mockStorer) StoreMany(_ context.Context, metrics []model.MetricDto) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = append(m.metrics, metrics...)
+	return nil
+}
+
+func (m *mockStorer) stored() []model.MetricDto {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.MetricDto(nil), m.metrics...)
+}
+
+func withLabels(m model.MetricDto, labels model.Labels) model.MetricDto {
+	m.Labels = labels
+	return m
+}
+
+func TestParseGraphite(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expected []model.MetricDto
+		wantErr  bool
+	}{
+		{
+			name:     "with timestamp",
+			line:     "servers.web-1.cpu 12.5 1700000000",
+			expected: []model.MetricDto{model.Gauge("servers.web-1.cpu", 12.5)},
+		},
+		{
+			name:     "without timestamp",
+			line:     "servers.web-1.cpu 3",
+			expected: []model.MetricDto{model.Gauge("servers.web-1.cpu", 3)},
+		},
+		{
+			name:     "tagged",
+			line:     "cpu;host=web-1;dc=eu 1 1700000000",
+			expected: []model.MetricDto{withLabels(model.Gauge("cpu", 1), model.Labels{"host": "web-1", "dc": "eu"})},
+		},
+		{name: "missing value", line: "cpu", wantErr: true},
+		{name: "invalid value", line: "cpu abc", wantErr: true},
+		{name: "invalid timestamp", line: "cpu 1 abc", wantErr: true},
+		{name: "invalid tag", line: "cpu;host 1", wantErr: true},
+		{name: "invalid tag name", line: "cpu;host.name=web-1 1", wantErr: true},
+		{name: "invalid path", line: `cpu{host="a"} 1`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := ParseGraphite(tc.line)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidLine)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, m)
+		})
+	}
+}
+
+func TestParseInflux(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expected []model.MetricDto
+		wantErr  bool
+	}{
+		{
+			name: "integer and float fields",
+			line: "http,host=web-1,method=GET requests=10i,latency=0.25 1700000000000000000",
+			expected: []model.MetricDto{
+				withLabels(model.Counter("http_requests", 10), model.Labels{"host": "web-1", "method": "GET"}),
+				withLabels(model.Gauge("http_latency", 0.25), model.Labels{"host": "web-1", "method": "GET"}),
+			},
+		},
+		{
+			name: "without tags and timestamp",
+			line: "mem used=1024u,free=2e3",
+			expected: []model.MetricDto{
+				model.Counter("mem_used", 1024),
+				model.Gauge("mem_free", 2000),
+			},
+		},
+		{
+			name: "escaped and string fields",
+			line: `disk\ io,path=/var\,log reads=1i,status="ok, fine",healthy=t`,
+			expected: []model.MetricDto{
+				withLabels(model.Counter("disk io_reads", 1), model.Labels{"path": "/var,log"}),
+				withLabels(model.Gauge("disk io_healthy", 1), model.Labels{"path": "/var,log"}),
+			},
+		},
+		{name: "missing fields", line: "cpu", wantErr: true},
+		{name: "invalid field", line: "cpu usage", wantErr: true},
+		{name: "invalid integer", line: "cpu usage=1.5i", wantErr: true},
+		{name: "invalid tag", line: "cpu,host usage=1", wantErr: true},
+		{name: "invalid tag name", line: "cpu,host-name=web-1 usage=1", wantErr: true},
+		{name: "invalid name", line: `cpu usage{host="a"}=1`, wantErr: true},
+		{name: "invalid timestamp", line: "cpu usage=1 now", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := ParseInflux(tc.line)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidLine)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, m)
+		})
+	}
+}
+
+func TestConverter(t *testing.T) {
+	ctx := context.Background()
+	c := NewConverter()
+
+	testCases := []struct {
+		name     string
+		line     string
+		expected []model.MetricDto
+	}{
+		{
+			name:     "first value",
+			line:     "jobs done=5i,load=0.5",
+			expected: []model.MetricDto{model.Counter("jobs_done", 5), model.Gauge("jobs_load", 0.5)},
+		},
+		{
+			name:     "increment",
+			line:     "jobs done=8i,load=0.7",
+			expected: []model.MetricDto{model.Counter("jobs_done", 3), model.Gauge("jobs_load", 0.7)},
+		},
+		{
+			name:     "unchanged",
+			line:     "jobs done=8i,load=0.7",
+			expected: []model.MetricDto{model.Gauge("jobs_load", 0.7)},
+		},
+		{
+			name:     "reset",
+			line:     "jobs done=2i,load=0.7",
+			expected: []model.MetricDto{model.Counter("jobs_done", 2), model.Gauge("jobs_load", 0.7)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := ParseInflux(tc.line)
+			require.NoError(t, err)
+			b := c.Convert(ctx, m, nil)
+			require.Equal(t, tc.expected, b.Metrics)
+			c.Commit(b)
+		})
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	body := "# comment\ncpu 1\n\nmem 2\n"
+	m, err := ParseLines(strings.NewReader(body), ParseGraphite)
+	require.NoError(t, err)
+	require.Equal(t, []model.MetricDto{model.Gauge("cpu", 1), model.Gauge("mem", 2)}, m)
+
+	_, err = ParseLines(strings.NewReader("cpu 1\nmem\n"), ParseGraphite)
+	require.ErrorIs(t, err, ErrInvalidLine)
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	storer := &mockStorer{}
+	s := NewServer(&ServerOptions{
+		Parser: ParseInflux,
+		Storer: storer,
+		Logger: zap.NewNop(),
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, ln)
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("jobs,queue=mail done=2i\nbroken\n"))
+	require.NoError(t, err)
+
+	expected := []model.MetricDto{withLabels(model.Counter("jobs_done", 2), model.Labels{"queue": "mail"})}
+	require.Eventually(t, func() bool {
+		return len(storer.stored()) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, expected, storer.stored())
+
+	// соединение остается открытым и принимает новые строки,
+	// для накопленного значения счетчика сохраняется прирост
+	_, err = conn.Write([]byte("jobs,queue=mail done=5i\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(storer.stored()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, withLabels(model.Counter("jobs_done", 3), model.Labels{"queue": "mail"}), storer.stored()[1])
+
+	_, err = conn.Write([]byte("load avg=0.5"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		return len(storer.stored()) == 3
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, model.Gauge("load_avg", 0.5), storer.stored()[2])
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestServeRejects(t *testing.T) {
+	testCases := []struct {
+		name    string
+		subnets string
+		data    string
+	}{
+		{
+			name:    "untrusted subnet",
+			subnets: "10.0.0.0/8",
+			data:    "load avg=0.5\n",
+		},
+		{
+			name: "line too long",
+			data: "load avg=0.5" + strings.Repeat(" ", MaxLineSize) + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			subnets, err := netutil.ParseCIDRs(tc.subnets)
+			require.NoError(t, err)
+
+			storer := &mockStorer{}
+			s := NewServer(&ServerOptions{
+				Parser:  ParseInflux,
+				Storer:  storer,
+				Logger:  zap.NewNop(),
+				Subnets: subnets,
+			})
+
+			done := make(chan error, 1)
+			go func() {
+				done <- s.Serve(ctx, ln)
+			}()
+
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			// сервер закрывает соединение, ничего не сохранив
+			_, _ = conn.Write([]byte(tc.data))
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+			_, err = conn.Read(make([]byte, 1))
+			require.Error(t, err)
+			require.NotErrorIs(t, err, os.ErrDeadlineExceeded)
+			require.Empty(t, storer.stored())
+
+			cancel()
+			require.NoError(t, <-done)
+		})
+	}
+}
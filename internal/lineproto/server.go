@@ -0,0 +1,201 @@
+// Package lineproto реализует прием метрик в построчных форматах Graphite plaintext
+// и InfluxDB line protocol через TCP и HTTP.
+package lineproto
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/cumulative"
+	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/pkg/netutil"
+)
+
+// ErrInvalidLine возвращается при некорректной строке.
+var ErrInvalidLine = errors.New("invalid line")
+
+// Parser разбирает одну строку в набор метрик.
+type Parser func(line string) ([]model.MetricDto, error)
+
+// Storer сохраняет пакет метрик.
+type Storer interface {
+	StoreMany(ctx context.Context, metrics []model.MetricDto) error
+}
+
+// ParseLines разбирает все строки r. Пустые строки и комментарии, начинающиеся с #, пропускаются.
+// При первой некорректной строке возвращает ошибку с ее номером.
+func ParseLines(r io.Reader, parse Parser) ([]model.MetricDto, error) {
+	metrics := make([]model.MetricDto, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, MaxLineSize)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("lineproto/parseLines: line %d: %w", n, err)
+		}
+		metrics = append(metrics, m...)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("lineproto/parseLines: scan: %w", err)
+	}
+	return metrics, nil
+}
+
+// MaxLineSize максимальная длина строки. TCP соединение со строкой длиннее закрывается.
+const MaxLineSize = 1 << 20
+
+// ServerOptions параметры TCP сервера.
+type ServerOptions struct {
+	Parser Parser
+	Storer Storer
+	Logger *zap.Logger
+
+	// Converter преобразует накопленные значения счетчиков в приросты.
+	// Если не задан, создается собственный.
+	Converter *Converter
+	// Getter возвращает сохраненные значения впервые встреченных счетчиков, может быть nil.
+	Getter cumulative.Getter
+
+	// Subnets доверенные подсети. Если заданы, соединения с других адресов закрываются.
+	Subnets netutil.Subnets
+}
+
+// Server принимает строки через TCP соединения.
+// Строки, прочитанные за одно чтение из соединения, сохраняются одним пакетом.
+// Некорректные строки пропускаются.
+type Server struct {
+	opts *ServerOptions
+
+	wg sync.WaitGroup
+}
+
+// NewServer создает TCP сервер.
+func NewServer(opts *ServerOptions) *Server {
+	if opts.Converter == nil {
+		opts.Converter = NewConverter()
+	}
+	return &Server{opts: opts}
+}
+
+// Serve принимает соединения из ln, пока не будет отменен ctx.
+// После отмены закрывает ln и открытые соединения и дожидается их обработки.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				cancel()
+				s.wg.Wait()
+				return nil
+			}
+			return fmt.Errorf("lineproto/serve: accept: %w", err)
+		}
+
+		if !s.trusted(conn.RemoteAddr()) {
+			s.opts.Logger.Warn("reject connection from untrusted address",
+				zap.String("addr", conn.RemoteAddr().String()),
+				zap.String("scope", "lineproto/serve"),
+			)
+			conn.Close()
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+// trusted сообщает, принадлежит ли адрес соединения доверенным подсетям.
+func (s *Server) trusted(addr net.Addr) bool {
+	if len(s.opts.Subnets) == 0 {
+		return true
+	}
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && s.opts.Subnets.Contains(tcp.IP)
+}
+
+// handle читает строки из соединения до его закрытия или отмены ctx.
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	scope := zap.String("scope", "lineproto/handle")
+
+	var batch []model.MetricDto
+	store := func() {
+		if len(batch) == 0 {
+			return
+		}
+		storeCtx := context.WithoutCancel(ctx)
+		b := s.opts.Converter.Convert(storeCtx, batch, s.opts.Getter)
+		batch = nil
+		if len(b.Metrics) == 0 {
+			s.opts.Converter.Commit(b)
+			return
+		}
+		if err := s.opts.Storer.StoreMany(storeCtx, b.Metrics); err != nil {
+			s.opts.Logger.Error("store many", zap.Error(err), zap.Int("count", len(b.Metrics)), scope)
+			s.opts.Converter.Release(b)
+			return
+		}
+		s.opts.Converter.Commit(b)
+	}
+
+	// пакет сохраняется перед каждым новым чтением из соединения,
+	// то есть когда разобраны все полученные строки
+	scanner := bufio.NewScanner(readerFunc(func(p []byte) (int, error) {
+		store()
+		return conn.Read(p)
+	}))
+	scanner.Buffer(nil, MaxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := s.opts.Parser(line)
+		if err != nil {
+			s.opts.Logger.Debug("parse line", zap.Error(err), scope)
+		}
+		batch = append(batch, m...)
+	}
+	store()
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		s.opts.Logger.Error("read line", zap.Error(err), scope)
+	}
+}
+
+// readerFunc адаптирует функцию к интерфейсу io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,215 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidMatcher возвращается при некорректном выражении матчера.
+	ErrInvalidMatcher = errors.New("invalid label matcher")
+	// ErrInvalidLabel возвращается при некорректном имени метки.
+	ErrInvalidLabel = errors.New("invalid label")
+	// ErrInvalidName возвращается при некорректном имени метрики.
+	ErrInvalidName = errors.New("invalid metric name")
+)
+
+// reservedNameChars символы, которые не могут входить в имя метрики:
+// они используются в представлении меток, и имя с ними сделало бы ключ серии неоднозначным.
+const reservedNameChars = `{}",`
+
+// labelNameRe допустимое имя метки. Ограничение исключает коллизии ключей серий
+// и совпадает с требованиями формата экспозиции.
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Labels набор меток (измерений) метрики.
+type Labels map[string]string
+
+// Clone возвращает копию набора меток.
+func (l Labels) Clone() Labels {
+	if len(l) == 0 {
+		return nil
+	}
+	return maps.Clone(l)
+}
+
+// Validate проверяет, что имена меток соответствуют [a-zA-Z_][a-zA-Z0-9_]*.
+func (l Labels) Validate() error {
+	for name := range l {
+		if name == "" {
+			return fmt.Errorf("empty label name: %w", ErrInvalidLabel)
+		}
+		if !labelNameRe.MatchString(name) {
+			return fmt.Errorf("label name %q: %w", name, ErrInvalidLabel)
+		}
+	}
+	return nil
+}
+
+// String возвращает каноническое представление меток в виде {a="1",b="2"}.
+// Метки упорядочены по имени, значения экранированы.
+// Для пустого набора возвращает пустую строку.
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	names := slices.Sorted(maps.Keys(l))
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// ValidateName проверяет, что имя метрики не пустое и не содержит символов {, }, " и ,.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty name: %w", ErrInvalidName)
+	}
+	if strings.ContainsAny(name, reservedNameChars) {
+		return fmt.Errorf("name %q: %w", name, ErrInvalidName)
+	}
+	return nil
+}
+
+// Key возвращает идентификатор серии: имя метрики и канонический набор меток.
+// Имя не содержит символов представления меток (см. ValidateName), поэтому ключ однозначен.
+func (m MetricDto) Key() string {
+	return m.Name + m.Labels.String()
+}
+
+// MatchType тип сравнения матчера меток.
+type MatchType uint8
+
+const (
+	MatchEqual     MatchType = iota // =
+	MatchNotEqual                   // !=
+	MatchRegexp                     // =~
+	MatchNotRegexp                  // !~
+)
+
+var matchTypeString = []string{"=", "!=", "=~", "!~"}
+
+// String возвращает оператор матчера.
+func (t MatchType) String() string {
+	return matchTypeString[t]
+}
+
+// Matcher описывает условие на значение метки.
+// Отсутствующая метка считается меткой с пустым значением.
+type Matcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+
+	re *regexp.Regexp
+}
+
+// NewMatcher создает матчер меток.
+// Регулярные выражения привязываются к началу и концу значения.
+func NewMatcher(t MatchType, name, value string) (Matcher, error) {
+	if name == "" {
+		return Matcher{}, fmt.Errorf("empty label name: %w", ErrInvalidMatcher)
+	}
+
+	m := Matcher{Type: t, Name: name, Value: value}
+	if t == MatchRegexp || t == MatchNotRegexp {
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return Matcher{}, fmt.Errorf("compile regexp %q: %w", value, ErrInvalidMatcher)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+// ParseMatcher разбирает выражение вида name="value", name!="value",
+// name=~"regexp" или name!~"regexp". Кавычки вокруг значения необязательны.
+func ParseMatcher(s string) (Matcher, error) {
+	idx := strings.IndexAny(s, "=!")
+	if idx <= 0 {
+		return Matcher{}, fmt.Errorf("parse %q: %w", s, ErrInvalidMatcher)
+	}
+
+	name := strings.TrimSpace(s[:idx])
+	rest := s[idx:]
+
+	var t MatchType
+	switch {
+	case strings.HasPrefix(rest, "=~"):
+		t = MatchRegexp
+	case strings.HasPrefix(rest, "!~"):
+		t = MatchNotRegexp
+	case strings.HasPrefix(rest, "!="):
+		t = MatchNotEqual
+	case strings.HasPrefix(rest, "="):
+		t = MatchEqual
+	default:
+		return Matcher{}, fmt.Errorf("parse %q: unknown operator: %w", s, ErrInvalidMatcher)
+	}
+
+	value := strings.TrimSpace(rest[len(t.String()):])
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("parse %q: unquote value: %w", s, ErrInvalidMatcher)
+		}
+		value = unquoted
+	}
+
+	return NewMatcher(t, name, value)
+}
+
+// Matches сообщает, удовлетворяет ли набор меток матчеру.
+func (m Matcher) Matches(labels Labels) bool {
+	v := labels[m.Name]
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(v)
+	case MatchNotRegexp:
+		return !m.re.MatchString(v)
+	}
+	return false
+}
+
+// String возвращает выражение матчера.
+func (m Matcher) String() string {
+	return m.Name + m.Type.String() + strconv.Quote(m.Value)
+}
+
+// MatchLabels сообщает, удовлетворяет ли набор меток всем матчерам.
+func MatchLabels(labels Labels, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualMatchers возвращает матчеры на точное совпадение всех переданных меток.
+func EqualMatchers(labels Labels) []Matcher {
+	matchers := make([]Matcher, 0, len(labels))
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		matchers = append(matchers, Matcher{Type: MatchEqual, Name: name, Value: labels[name]})
+	}
+	return matchers
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelsString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		labels   Labels
+		expected string
+	}{
+		{
+			name:     "empty",
+			labels:   nil,
+			expected: "",
+		},
+		{
+			name:     "sorted",
+			labels:   Labels{"host": "web-1", "dc": "eu"},
+			expected: `{dc="eu",host="web-1"}`,
+		},
+		{
+			name:     "escaped",
+			labels:   Labels{"path": `a"b`},
+			expected: `{path="a\"b"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.labels.String())
+		})
+	}
+}
+
+func TestLabelsValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		labels  Labels
+		wantErr bool
+	}{
+		{name: "nil", labels: nil},
+		{name: "valid", labels: Labels{"host": "web-1", "_dc": "eu", "code2": "200"}},
+		{name: "empty name", labels: Labels{"": "web-1"}, wantErr: true},
+		{name: "leading digit", labels: Labels{"2xx": "1"}, wantErr: true},
+		{name: "dot", labels: Labels{"service.name": "api"}, wantErr: true},
+		{name: "key injection", labels: Labels{`a="1",b`: "2"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.labels.Validate()
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidLabel)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	testCases := []struct {
+		name    string
+		metric  string
+		wantErr bool
+	}{
+		{name: "valid", metric: "http_requests_total"},
+		{name: "graphite path", metric: "servers.web-1.cpu:load"},
+		{name: "empty", metric: "", wantErr: true},
+		{name: "brace", metric: `cpu{host="a"}`, wantErr: true},
+		{name: "closing brace", metric: "cpu}", wantErr: true},
+		{name: "quote", metric: `cpu"`, wantErr: true},
+		{name: "comma", metric: "cpu,host", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateName(tc.metric)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidName)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseMatcher(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		labels   Labels
+		wantErr  bool
+		expected bool
+	}{
+		{
+			name:     "equal",
+			expr:     `host="web-1"`,
+			labels:   Labels{"host": "web-1"},
+			expected: true,
+		},
+		{
+			name:     "equal without quotes",
+			expr:     `host=web-1`,
+			labels:   Labels{"host": "web-2"},
+			expected: false,
+		},
+		{
+			name:     "not equal",
+			expr:     `host!="web-1"`,
+			labels:   Labels{"host": "web-2"},
+			expected: true,
+		},
+		{
+			name:     "regexp",
+			expr:     `host=~"web-.*"`,
+			labels:   Labels{"host": "web-2"},
+			expected: true,
+		},
+		{
+			name:     "regexp is anchored",
+			expr:     `host=~"web"`,
+			labels:   Labels{"host": "web-2"},
+			expected: false,
+		},
+		{
+			name:     "not regexp with missing label",
+			expr:     `host!~".+"`,
+			labels:   Labels{},
+			expected: true,
+		},
+		{
+			name:    "empty name",
+			expr:    `="a"`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid regexp",
+			expr:    `host=~"("`,
+			wantErr: true,
+		},
+		{
+			name:    "no operator",
+			expr:    `host`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := ParseMatcher(tc.expr)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidMatcher)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, m.Matches(tc.labels))
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	m := Gauge("Alloc", 1)
+	require.Equal(t, "Alloc", m.Key())
+
+	m.Labels = Labels{"host": "web-1"}
+	require.Equal(t, `Alloc{host="web-1"}`, m.Key())
+}
@@ -2,20 +2,26 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 
 	pb "github.com/htrandev/metrics/internal/proto"
 )
 
 // Storager определяет интерфейс хранилища метрик.
 type Storager interface {
-	Get(ctx context.Context, name string) (MetricDto, error)
-	GetAll(ctx context.Context) ([]MetricDto, error)
+	Get(ctx context.Context, name string, matchers ...Matcher) (MetricDto, error)
+	GetAll(ctx context.Context, matchers ...Matcher) ([]MetricDto, error)
+	Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...Matcher) ([]Series, error)
 
 	Store(ctx context.Context, metric *MetricDto) error
 	StoreMany(ctx context.Context, metrics []MetricDto) error
 	StoreManyWithRetry(ctx context.Context, metrics []MetricDto) error
+	StoreBatch(ctx context.Context, batchID string, metrics []MetricDto) (bool, error)
 
 	Set(ctx context.Context, metric *MetricDto) error
 
@@ -32,16 +38,54 @@ type MetricsSlice []Metrics
 //
 //easyjson:json
 type Metrics struct {
-	ID    string   `json:"id"`              // имя метрики.
-	MType string   `json:"type"`            // параметр, принимающий значение gauge или counter.
-	Delta *int64   `json:"delta,omitempty"` // значение метрики в случае передачи counter.
-	Value *float64 `json:"value,omitempty"` // значение метрики в случае передачи gauge.
+	ID        string     `json:"id"`                    // имя метрики.
+	MType     string     `json:"type"`                  // параметр, принимающий значение gauge, counter или histogram.
+	Delta     *int64     `json:"delta,omitempty"`       // значение метрики в случае передачи counter.
+	Value     *float64   `json:"value,omitempty"`       // значение метрики в случае передачи gauge.
+	Histogram *Histogram `json:"histogram,omitempty"`   // значение метрики в случае передачи histogram.
+	Labels    Labels     `json:"labels,omitempty"`      // метки (измерения) метрики.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`  // время последнего обновления, только в ответах.
+	Age       *float64   `json:"age_seconds,omitempty"` // секунды с последнего обновления, только в ответах.
 }
 
 // MetricDto внутренняя структура метрики с типизированным значением.
 type MetricDto struct {
-	Name  string `json:"name"`
-	Value MetricValue
+	Name   string `json:"name"`
+	Labels Labels `json:"labels,omitempty"`
+	Value  MetricValue
+	// UpdatedAt время последнего обновления серии, заполняется хранилищем.
+	// Нулевое значение означает, что время неизвестно.
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Age возвращает время, прошедшее с последнего обновления серии на момент now.
+// Если время обновления неизвестно, возвращает false.
+func (m MetricDto) Age(now time.Time) (time.Duration, bool) {
+	if m.UpdatedAt.IsZero() {
+		return 0, false
+	}
+	return now.Sub(m.UpdatedAt), true
+}
+
+// ErrInvalidMetric возвращается, если метрика не может быть сохранена.
+// Такие ошибки не имеет смысла повторять.
+var ErrInvalidMetric = errors.New("invalid metric")
+
+// Validate проверяет, что метрика может быть сохранена:
+// имя метрики, имена меток и бакеты гистограммы корректны.
+func (m *MetricDto) Validate() error {
+	if err := ValidateName(m.Name); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMetric, err)
+	}
+	if err := m.Labels.Validate(); err != nil {
+		return fmt.Errorf("%w %s: labels: %w", ErrInvalidMetric, m.Name, err)
+	}
+	if m.Value.Type == TypeHistogram {
+		if err := m.Value.Histogram.Validate(); err != nil {
+			return fmt.Errorf("%w %s: histogram: %w", ErrInvalidMetric, m.Name, err)
+		}
+	}
+	return nil
 }
 
 // MetricType тип метрики.
@@ -50,24 +94,30 @@ type MetricType uint8
 const (
 	TypeUnknown MetricType = iota
 
-	TypeGauge   // Метрика типа gauge.
-	TypeCounter // Метрика типа counter.
+	TypeGauge     // Метрика типа gauge.
+	TypeCounter   // Метрика типа counter.
+	TypeHistogram // Метрика типа histogram.
 )
 
 var metricsTypeValues = map[string]MetricType{
-	"unknown": TypeUnknown,
-	"gauge":   TypeGauge,
-	"counter": TypeCounter,
+	"unknown":   TypeUnknown,
+	"gauge":     TypeGauge,
+	"counter":   TypeCounter,
+	"histogram": TypeHistogram,
 }
 
 var metricTypeString = []string{
 	"unknown",
 	"gauge",
 	"counter",
+	"histogram",
 }
 
 // String возвращает строковое представление типа метрики.
 func (m MetricType) String() string {
+	if int(m) >= len(metricTypeString) {
+		return metricTypeString[TypeUnknown]
+	}
 	return metricTypeString[m]
 }
 
@@ -82,9 +132,143 @@ func ParseMetricType(s string) MetricType {
 
 // MetricValue хранит значение и тип метрики.
 type MetricValue struct {
-	Type    MetricType `json:"type"`
-	Gauge   float64    `json:"gauge,omitempty"`
-	Counter int64      `json:"counter,omitempty"`
+	Type      MetricType `json:"type"`
+	Gauge     float64    `json:"gauge,omitempty"`
+	Counter   int64      `json:"counter,omitempty"`
+	Histogram *Histogram `json:"histogram,omitempty"`
+}
+
+var (
+	// ErrInvalidHistogram возвращается при некорректном наборе бакетов гистограммы.
+	ErrInvalidHistogram = errors.New("invalid histogram")
+)
+
+// Histogram хранит распределение значений по бакетам.
+//
+// Bounds содержит верхние границы бакетов в порядке возрастания,
+// Counts - количество наблюдений в каждом бакете (не накопительно).
+// Последний элемент Counts соответствует бакету +Inf,
+// поэтому len(Counts) == len(Bounds)+1.
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+// NewHistogram создает пустую гистограмму с переданными границами бакетов.
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		Bounds: append([]float64(nil), bounds...),
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Validate проверяет согласованность границ и счетчиков бакетов.
+func (h *Histogram) Validate() error {
+	if h == nil {
+		return fmt.Errorf("histogram is nil: %w", ErrInvalidHistogram)
+	}
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return fmt.Errorf("got %d counts for %d bounds: %w", len(h.Counts), len(h.Bounds), ErrInvalidHistogram)
+	}
+	for i := range h.Bounds {
+		if math.IsNaN(h.Bounds[i]) || math.IsInf(h.Bounds[i], 0) {
+			return fmt.Errorf("bound %d is not finite: %w", i, ErrInvalidHistogram)
+		}
+		if i > 0 && h.Bounds[i] <= h.Bounds[i-1] {
+			return fmt.Errorf("bounds are not strictly increasing: %w", ErrInvalidHistogram)
+		}
+	}
+
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total != h.Count {
+		return fmt.Errorf("count %d doesn't match sum of buckets %d: %w", h.Count, total, ErrInvalidHistogram)
+	}
+	return nil
+}
+
+// Observe добавляет наблюдение в гистограмму.
+func (h *Histogram) Observe(v float64) {
+	h.ObserveN(v, 1)
+}
+
+// ObserveN добавляет n одинаковых наблюдений v в гистограмму.
+func (h *Histogram) ObserveN(v float64, n uint64) {
+	i := 0
+	for i < len(h.Bounds) && v > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i] += n
+	h.Sum += v * float64(n)
+	h.Count += n
+}
+
+// SameBounds сообщает, совпадают ли границы бакетов двух гистограмм.
+func (h *Histogram) SameBounds(other *Histogram) bool {
+	if len(h.Bounds) != len(other.Bounds) {
+		return false
+	}
+	for i := range h.Bounds {
+		if h.Bounds[i] != other.Bounds[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge добавляет к гистограмме значения другой гистограммы.
+// Если границы бакетов отличаются, то гистограмма заменяется переданной,
+// так как клиент изменил раскладку бакетов.
+func (h *Histogram) Merge(other *Histogram) {
+	if !h.SameBounds(other) {
+		*h = *other.Clone()
+		return
+	}
+	for i := range h.Counts {
+		h.Counts[i] += other.Counts[i]
+	}
+	h.Sum += other.Sum
+	h.Count += other.Count
+}
+
+// Clone возвращает глубокую копию гистограммы.
+func (h *Histogram) Clone() *Histogram {
+	if h == nil {
+		return nil
+	}
+	return &Histogram{
+		Bounds: append([]float64(nil), h.Bounds...),
+		Counts: append([]uint64(nil), h.Counts...),
+		Sum:    h.Sum,
+		Count:  h.Count,
+	}
+}
+
+// String возвращает строковое представление гистограммы
+// в виде накопительных бакетов, суммы и количества наблюдений.
+func (h *Histogram) String() string {
+	var b strings.Builder
+	var cumulative uint64
+	for i, c := range h.Counts {
+		cumulative += c
+		if i < len(h.Bounds) {
+			b.WriteString(strconv.FormatFloat(h.Bounds[i], 'f', -1, 64))
+		} else {
+			b.WriteString("+Inf")
+		}
+		b.WriteString(":")
+		b.WriteString(strconv.FormatUint(cumulative, 10))
+		b.WriteString(" ")
+	}
+	b.WriteString("sum:")
+	b.WriteString(strconv.FormatFloat(h.Sum, 'f', -1, 64))
+	b.WriteString(" count:")
+	b.WriteString(strconv.FormatUint(h.Count, 10))
+	return b.String()
 }
 
 // String возвращает строковое представление значения в зависимости от типа.
@@ -94,6 +278,11 @@ func (mv MetricValue) String() string {
 		return strconv.FormatFloat(mv.Gauge, 'f', -1, 64)
 	case TypeCounter:
 		return strconv.FormatInt(mv.Counter, 10)
+	case TypeHistogram:
+		if mv.Histogram == nil {
+			return ""
+		}
+		return mv.Histogram.String()
 	default:
 		return ""
 	}
@@ -114,6 +303,8 @@ func (m *MetricDto) SetValue(s string) error {
 			return fmt.Errorf("convert value to int64: %w", err)
 		}
 		m.Value.Counter = val
+	case TypeHistogram:
+		return fmt.Errorf("histogram can't be set from plain value: %w", ErrInvalidHistogram)
 	}
 	return nil
 }
@@ -134,6 +325,15 @@ func Counter(name string, value int64) MetricDto {
 	}
 }
 
+// HistogramMetric создает новую histogram метрику с переданным значением.
+func HistogramMetric(name string, value *Histogram) MetricDto {
+	return MetricDto{
+		Name:  name,
+		Value: MetricValue{Type: TypeHistogram, Histogram: value},
+	}
+}
+
+// FromProto преобразует protobuf-сообщение во внутреннюю метрику.
 func FromProto(value *pb.Metric) MetricDto {
 	var m MetricDto
 	switch value.GetType() {
@@ -141,10 +341,20 @@ func FromProto(value *pb.Metric) MetricDto {
 		m = Gauge(value.GetId(), value.GetValue())
 	case pb.Metric_COUNTER:
 		m = Counter(value.GetId(), value.GetDelta())
+	case pb.Metric_HISTOGRAM:
+		h := value.GetHistogram()
+		m = HistogramMetric(value.GetId(), &Histogram{
+			Bounds: h.GetBounds(),
+			Counts: h.GetCounts(),
+			Sum:    h.GetSum(),
+			Count:  h.GetCount(),
+		})
 	}
+	m.Labels = Labels(value.GetLabels()).Clone()
 	return m
 }
 
+// ToProto преобразует внутреннюю метрику в protobuf-сообщение.
 func ToProto(m MetricDto) *pb.Metric {
 	var pm pb.Metric_builder
 
@@ -157,7 +367,19 @@ func ToProto(m MetricDto) *pb.Metric {
 		pm.Id = m.Name
 		pm.Type = pb.Metric_COUNTER
 		pm.Delta = m.Value.Counter
+	case TypeHistogram:
+		pm.Id = m.Name
+		pm.Type = pb.Metric_HISTOGRAM
+		if h := m.Value.Histogram; h != nil {
+			pm.Histogram = pb.Histogram_builder{
+				Bounds: h.Bounds,
+				Counts: h.Counts,
+				Sum:    h.Sum,
+				Count:  h.Count,
+			}.Build()
+		}
 	}
+	pm.Labels = m.Labels
 
 	return pm.Build()
 }
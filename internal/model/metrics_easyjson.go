@@ -7,6 +7,7 @@ import (
 	easyjson "github.com/mailru/easyjson"
 	jlexer "github.com/mailru/easyjson/jlexer"
 	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
 )
 
 // suppress unused package warning
@@ -26,7 +27,7 @@ func easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel(in *jlexer.Lexe
 		in.Delim('[')
 		if *out == nil {
 			if !in.IsDelim(']') {
-				*out = make(MetricsSlice, 0, 1)
+				*out = make(MetricsSlice, 0, 0)
 			} else {
 				*out = MetricsSlice{}
 			}
@@ -141,6 +142,74 @@ func easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel1(in *jlexer.Lex
 					*out.Value = float64(in.Float64())
 				}
 			}
+		case "histogram":
+			if in.IsNull() {
+				in.Skip()
+				out.Histogram = nil
+			} else {
+				if out.Histogram == nil {
+					out.Histogram = new(Histogram)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					(*out.Histogram).UnmarshalEasyJSON(in)
+				}
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(Labels)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v4 = string(in.String())
+					}
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "updated_at":
+			if in.IsNull() {
+				in.Skip()
+				out.UpdatedAt = nil
+			} else {
+				if out.UpdatedAt == nil {
+					out.UpdatedAt = new(time.Time)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					if data := in.Raw(); in.Ok() {
+						in.AddError((*out.UpdatedAt).UnmarshalJSON(data))
+					}
+				}
+			}
+		case "age_seconds":
+			if in.IsNull() {
+				in.Skip()
+				out.Age = nil
+			} else {
+				if out.Age == nil {
+					out.Age = new(float64)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					*out.Age = float64(in.Float64())
+				}
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -175,6 +244,40 @@ func easyjson2220f231EncodeGithubComHtrandevMetricsInternalModel1(out *jwriter.W
 		out.RawString(prefix)
 		out.Float64(float64(*in.Value))
 	}
+	if in.Histogram != nil {
+		const prefix string = ",\"histogram\":"
+		out.RawString(prefix)
+		(*in.Histogram).MarshalEasyJSON(out)
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Labels {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				out.String(string(v5Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.UpdatedAt != nil {
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((*in.UpdatedAt).MarshalJSON())
+	}
+	if in.Age != nil {
+		const prefix string = ",\"age_seconds\":"
+		out.RawString(prefix)
+		out.Float64(float64(*in.Age))
+	}
 	out.RawByte('}')
 }
 
@@ -233,6 +336,20 @@ func easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel2(in *jlexer.Lex
 			} else {
 				out.Counter = int64(in.Int64())
 			}
+		case "histogram":
+			if in.IsNull() {
+				in.Skip()
+				out.Histogram = nil
+			} else {
+				if out.Histogram == nil {
+					out.Histogram = new(Histogram)
+				}
+				if in.IsNull() {
+					in.Skip()
+				} else {
+					(*out.Histogram).UnmarshalEasyJSON(in)
+				}
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -262,6 +379,11 @@ func easyjson2220f231EncodeGithubComHtrandevMetricsInternalModel2(out *jwriter.W
 		out.RawString(prefix)
 		out.Int64(int64(in.Counter))
 	}
+	if in.Histogram != nil {
+		const prefix string = ",\"histogram\":"
+		out.RawString(prefix)
+		(*in.Histogram).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
@@ -308,12 +430,44 @@ func easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel3(in *jlexer.Lex
 			} else {
 				out.Name = string(in.String())
 			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(Labels)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v6 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v6 = string(in.String())
+					}
+					(out.Labels)[key] = v6
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
 		case "Value":
 			if in.IsNull() {
 				in.Skip()
 			} else {
 				(out.Value).UnmarshalEasyJSON(in)
 			}
+		case "updated_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+				}
+			}
 		default:
 			in.SkipRecursive()
 		}
@@ -333,11 +487,35 @@ func easyjson2220f231EncodeGithubComHtrandevMetricsInternalModel3(out *jwriter.W
 		out.RawString(prefix[1:])
 		out.String(string(in.Name))
 	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v7First := true
+			for v7Name, v7Value := range in.Labels {
+				if v7First {
+					v7First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v7Name))
+				out.RawByte(':')
+				out.String(string(v7Value))
+			}
+			out.RawByte('}')
+		}
+	}
 	{
 		const prefix string = ",\"Value\":"
 		out.RawString(prefix)
 		(in.Value).MarshalEasyJSON(out)
 	}
+	if true {
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
 	out.RawByte('}')
 }
 
@@ -364,3 +542,165 @@ func (v *MetricDto) UnmarshalJSON(data []byte) error {
 func (v *MetricDto) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel3(l, v)
 }
+func easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel4(in *jlexer.Lexer, out *Histogram) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "bounds":
+			if in.IsNull() {
+				in.Skip()
+				out.Bounds = nil
+			} else {
+				in.Delim('[')
+				if out.Bounds == nil {
+					if !in.IsDelim(']') {
+						out.Bounds = make([]float64, 0, 8)
+					} else {
+						out.Bounds = []float64{}
+					}
+				} else {
+					out.Bounds = (out.Bounds)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v8 float64
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v8 = float64(in.Float64())
+					}
+					out.Bounds = append(out.Bounds, v8)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "counts":
+			if in.IsNull() {
+				in.Skip()
+				out.Counts = nil
+			} else {
+				in.Delim('[')
+				if out.Counts == nil {
+					if !in.IsDelim(']') {
+						out.Counts = make([]uint64, 0, 8)
+					} else {
+						out.Counts = []uint64{}
+					}
+				} else {
+					out.Counts = (out.Counts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v9 uint64
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v9 = uint64(in.Uint64())
+					}
+					out.Counts = append(out.Counts, v9)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "sum":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Sum = float64(in.Float64())
+			}
+		case "count":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Count = uint64(in.Uint64())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2220f231EncodeGithubComHtrandevMetricsInternalModel4(out *jwriter.Writer, in Histogram) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"bounds\":"
+		out.RawString(prefix[1:])
+		if in.Bounds == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v10, v11 := range in.Bounds {
+				if v10 > 0 {
+					out.RawByte(',')
+				}
+				out.Float64(float64(v11))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"counts\":"
+		out.RawString(prefix)
+		if in.Counts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v12, v13 := range in.Counts {
+				if v12 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint64(uint64(v13))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Histogram) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2220f231EncodeGithubComHtrandevMetricsInternalModel4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Histogram) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2220f231EncodeGithubComHtrandevMetricsInternalModel4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Histogram) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Histogram) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2220f231DecodeGithubComHtrandevMetricsInternalModel4(l, v)
+}
@@ -54,3 +54,86 @@ func TestSetValue(t *testing.T) {
 		})
 	}
 }
+
+func TestHistogramValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		histogram *Histogram
+		wantErr   bool
+	}{
+		{
+			name:      "valid",
+			histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 0, 2}, Sum: 5.1, Count: 3},
+		},
+		{
+			name:      "valid without bounds",
+			histogram: &Histogram{Bounds: []float64{}, Counts: []uint64{2}, Sum: 1, Count: 2},
+		},
+		{
+			name:      "nil",
+			histogram: nil,
+			wantErr:   true,
+		},
+		{
+			name:      "counts length mismatch",
+			histogram: &Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{1, 0}, Count: 1},
+			wantErr:   true,
+		},
+		{
+			name:      "unsorted bounds",
+			histogram: &Histogram{Bounds: []float64{1, 0.1}, Counts: []uint64{0, 0, 0}},
+			wantErr:   true,
+		},
+		{
+			name:      "count mismatch",
+			histogram: &Histogram{Bounds: []float64{1}, Counts: []uint64{1, 1}, Count: 3},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.histogram.Validate()
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidHistogram)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	t.Run("same bounds", func(t *testing.T) {
+		h := NewHistogram([]float64{0.5, 1})
+		h.Observe(0.25)
+		h.Observe(2)
+
+		other := NewHistogram([]float64{0.5, 1})
+		other.Observe(0.75)
+
+		h.Merge(other)
+		require.Equal(t, &Histogram{
+			Bounds: []float64{0.5, 1},
+			Counts: []uint64{1, 1, 1},
+			Sum:    3,
+			Count:  3,
+		}, h)
+	})
+
+	t.Run("different bounds", func(t *testing.T) {
+		h := NewHistogram([]float64{0.5, 1})
+		h.Observe(0.2)
+
+		other := NewHistogram([]float64{10})
+		other.Observe(3)
+
+		h.Merge(other)
+		require.Equal(t, other, h)
+	})
+}
+
+func TestHistogramString(t *testing.T) {
+	h := &Histogram{Bounds: []float64{0.5, 1}, Counts: []uint64{1, 2, 3}, Sum: 10.5, Count: 6}
+	require.Equal(t, "0.5:1 1:3 +Inf:6 sum:10.5 count:6", h.String())
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+)
+
+// Point значение серии в момент времени.
+type Point struct {
+	Timestamp time.Time `json:"ts"`
+	Value     float64   `json:"value"`
+}
+
+// Series история значений одной серии метрики.
+type Series struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Labels Labels  `json:"labels,omitempty"`
+	Points []Point `json:"points"`
+}
+
+// SeriesSlice тип для массива серий.
+//
+//easyjson:json
+type SeriesSlice []Series
+
+// Downsample прореживает упорядоченные по времени точки с шагом step, начиная с from.
+// Для каждого интервала [from+i*step, from+(i+1)*step) берется последнее значение,
+// а временем точки считается начало интервала.
+// При step <= 0 точки возвращаются без изменений.
+func Downsample(points []Point, from time.Time, step time.Duration) []Point {
+	if step <= 0 || len(points) == 0 {
+		return points
+	}
+
+	result := make([]Point, 0, len(points))
+	for _, p := range points {
+		if p.Timestamp.Before(from) {
+			continue
+		}
+		bucket := from.Add(p.Timestamp.Sub(from) / step * step)
+
+		n := len(result)
+		if n > 0 && result[n-1].Timestamp.Equal(bucket) {
+			result[n-1].Value = p.Value
+			continue
+		}
+		result = append(result, Point{Timestamp: bucket, Value: p.Value})
+	}
+	return result
+}
+
+// SampleValue возвращает значение метрики, которое записывается в историю.
+// Для гистограмм в историю записывается количество наблюдений.
+func (mv MetricValue) SampleValue() float64 {
+	switch mv.Type {
+	case TypeGauge:
+		return mv.Gauge
+	case TypeCounter:
+		return float64(mv.Counter)
+	case TypeHistogram:
+		if mv.Histogram != nil {
+			return float64(mv.Histogram.Count)
+		}
+	}
+	return 0
+}
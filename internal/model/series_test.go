@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsample(t *testing.T) {
+	from := time.Unix(1000, 0)
+	at := func(sec int) time.Time { return from.Add(time.Duration(sec) * time.Second) }
+
+	points := []Point{
+		{Timestamp: at(-5), Value: 0},
+		{Timestamp: at(1), Value: 1},
+		{Timestamp: at(9), Value: 2},
+		{Timestamp: at(10), Value: 3},
+		{Timestamp: at(35), Value: 4},
+	}
+
+	t.Run("without step", func(t *testing.T) {
+		require.Equal(t, points, Downsample(points, from, 0))
+	})
+
+	t.Run("with step", func(t *testing.T) {
+		expected := []Point{
+			{Timestamp: at(0), Value: 2},
+			{Timestamp: at(10), Value: 3},
+			{Timestamp: at(30), Value: 4},
+		}
+		require.Equal(t, expected, Downsample(points, from, 10*time.Second))
+	})
+}
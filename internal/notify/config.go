@@ -0,0 +1,235 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ErrInvalidConfig возвращается при некорректном описании уведомлений.
+var ErrInvalidConfig = errors.New("invalid notify config")
+
+// Config описание уведомлений в YAML файле.
+type Config struct {
+	Receivers       []ReceiverConfig `yaml:"receivers"`
+	Routes          []RouteConfig    `yaml:"routes"`
+	DefaultReceiver string           `yaml:"default_receiver"`
+
+	GroupBy        []string      `yaml:"group_by"`
+	GroupWait      time.Duration `yaml:"group_wait"`
+	RepeatInterval time.Duration `yaml:"repeat_interval"`
+}
+
+// ReceiverConfig описание получателя. Уведомление отправляется
+// во все заданные каналы получателя.
+type ReceiverConfig struct {
+	Name    string         `yaml:"name"`
+	Webhook *WebhookConfig `yaml:"webhook"`
+	Email   *EmailConfig   `yaml:"email"`
+	File    *FileConfig    `yaml:"file"`
+}
+
+// WebhookConfig описание канала Webhook.
+type WebhookConfig struct {
+	URL      string        `yaml:"url"`
+	MaxRetry *int          `yaml:"max_retry"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// EmailConfig описание канала Email.
+type EmailConfig struct {
+	Addr     string   `yaml:"addr"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	// Timeout ограничивает подключение и отправку письма.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// FileConfig описание канала File.
+type FileConfig struct {
+	Path string `yaml:"path"`
+}
+
+// RouteConfig описание маршрута.
+type RouteConfig struct {
+	Receiver string   `yaml:"receiver"`
+	Severity []string `yaml:"severity"`
+	// Matchers матчеры меток в формате name="value", name=~"regexp".
+	Matchers []string `yaml:"matchers"`
+	Continue bool     `yaml:"continue"`
+}
+
+// LoadConfig загружает описание уведомлений из YAML файла.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("notify/LoadConfig: read file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("notify/LoadConfig: unmarshal: %w", err)
+	}
+	return &cfg, nil
+}
+
+// NewFromConfig создает рассылку уведомлений по описанию cfg.
+// Открытые файлы каналов закрываются Dispatcher.Close.
+func NewFromConfig(cfg *Config, source Source, l *zap.Logger) (*Dispatcher, error) {
+	opts := &DispatcherOptions{
+		Source:          source,
+		Receivers:       make(map[string]Notifier, len(cfg.Receivers)),
+		DefaultReceiver: cfg.DefaultReceiver,
+		GroupBy:         cfg.GroupBy,
+		GroupWait:       cfg.GroupWait,
+		RepeatInterval:  cfg.RepeatInterval,
+		Logger:          l,
+	}
+	d := NewDispatcher(opts)
+
+	for _, rc := range cfg.Receivers {
+		if rc.Name == "" {
+			d.Close()
+			return nil, fmt.Errorf("notify/NewFromConfig: receiver without name: %w", ErrInvalidConfig)
+		}
+		if _, ok := opts.Receivers[rc.Name]; ok {
+			d.Close()
+			return nil, fmt.Errorf("notify/NewFromConfig: duplicate receiver %q: %w", rc.Name, ErrInvalidConfig)
+		}
+
+		n, err := newReceiver(rc, l)
+		if err != nil {
+			d.Close()
+			return nil, fmt.Errorf("notify/NewFromConfig: receiver %q: %w", rc.Name, err)
+		}
+		opts.Receivers[rc.Name] = n
+	}
+
+	if cfg.DefaultReceiver != "" {
+		if _, ok := opts.Receivers[cfg.DefaultReceiver]; !ok {
+			d.Close()
+			return nil, fmt.Errorf("notify/NewFromConfig: unknown default receiver %q: %w", cfg.DefaultReceiver, ErrInvalidConfig)
+		}
+	}
+
+	for i, rc := range cfg.Routes {
+		r, err := newRoute(rc, opts.Receivers)
+		if err != nil {
+			d.Close()
+			return nil, fmt.Errorf("notify/NewFromConfig: route %d: %w", i, err)
+		}
+		opts.Routes = append(opts.Routes, r)
+	}
+	return d, nil
+}
+
+func newReceiver(rc ReceiverConfig, l *zap.Logger) (Notifier, error) {
+	var m multi
+	if rc.Webhook != nil {
+		if rc.Webhook.URL == "" {
+			return nil, fmt.Errorf("webhook: empty url: %w", ErrInvalidConfig)
+		}
+		client := resty.New().
+			SetTimeout(30 * time.Second)
+		if rc.Webhook.Timeout > 0 {
+			client.SetTimeout(rc.Webhook.Timeout)
+		}
+
+		var opts []WebhookOption
+		if rc.Webhook.MaxRetry != nil {
+			opts = append(opts, WithRetry(*rc.Webhook.MaxRetry, 0, 0))
+		}
+		m = append(m, NewWebhook(rc.Webhook.URL, client, l, opts...))
+	}
+
+	if rc.Email != nil {
+		if rc.Email.Addr == "" || rc.Email.From == "" || len(rc.Email.To) == 0 {
+			return nil, fmt.Errorf("email: addr, from and to required: %w", ErrInvalidConfig)
+		}
+		m = append(m, NewEmail(EmailOptions{
+			Addr:     rc.Email.Addr,
+			From:     rc.Email.From,
+			To:       rc.Email.To,
+			Username: rc.Email.Username,
+			Password: rc.Email.Password,
+			Timeout:  rc.Email.Timeout,
+		}))
+	}
+
+	if rc.File != nil {
+		if rc.File.Path == "" {
+			return nil, fmt.Errorf("file: empty path: %w", ErrInvalidConfig)
+		}
+		f, err := NewFile(rc.File.Path)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m = append(m, f)
+	}
+
+	switch len(m) {
+	case 0:
+		return nil, fmt.Errorf("no channels: %w", ErrInvalidConfig)
+	case 1:
+		return m[0], nil
+	default:
+		return m, nil
+	}
+}
+
+func newRoute(rc RouteConfig, receivers map[string]Notifier) (Route, error) {
+	if _, ok := receivers[rc.Receiver]; !ok {
+		return Route{}, fmt.Errorf("unknown receiver %q: %w", rc.Receiver, ErrInvalidConfig)
+	}
+
+	r := Route{
+		Receiver:   rc.Receiver,
+		Severities: rc.Severity,
+		Continue:   rc.Continue,
+	}
+	for _, expr := range rc.Matchers {
+		m, err := model.ParseMatcher(expr)
+		if err != nil {
+			return Route{}, fmt.Errorf("%w: matcher: %w", ErrInvalidConfig, err)
+		}
+		r.Matchers = append(r.Matchers, m)
+	}
+	return r, nil
+}
+
+// multi отправляет уведомление во все каналы получателя.
+type multi []Notifier
+
+// Notify отправляет уведомление во все каналы, даже если часть из них недоступна.
+func (m multi) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close закрывает каналы, которые требуют закрытия.
+func (m multi) Close() error {
+	var errs []error
+	for _, n := range m {
+		if c, ok := n.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,291 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/alerting"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+const (
+	// DefaultInterval интервал проверки оповещений по умолчанию.
+	DefaultInterval = 5 * time.Second
+	// DefaultGroupWait время ожидания других оповещений группы перед уведомлением.
+	DefaultGroupWait = 30 * time.Second
+	// DefaultRepeatInterval интервал повторного уведомления о сработавших оповещениях.
+	DefaultRepeatInterval = 4 * time.Hour
+)
+
+// Source возвращает текущие оповещения.
+type Source interface {
+	Alerts() []alerting.Alert
+}
+
+// DispatcherOptions параметры рассылки уведомлений.
+type DispatcherOptions struct {
+	Source Source
+
+	// Receivers каналы уведомлений по имени получателя.
+	Receivers map[string]Notifier
+	// Routes маршруты, проверяемые по порядку.
+	Routes []Route
+	// DefaultReceiver получатель оповещений, не подошедших ни под один маршрут.
+	// Если пусто, такие оповещения не отправляются.
+	DefaultReceiver string
+
+	// GroupBy метки, по значениям которых оповещения объединяются в одно уведомление.
+	// Если пусто, все оповещения получателя попадают в одну группу.
+	GroupBy []string
+	// GroupWait время от первого изменения в группе до уведомления,
+	// за которое накапливаются оповещения, сработавшие рядом. По умолчанию DefaultGroupWait.
+	GroupWait time.Duration
+	// RepeatInterval интервал повторного уведомления о группе,
+	// в которой остаются сработавшие оповещения. По умолчанию DefaultRepeatInterval.
+	RepeatInterval time.Duration
+	// Interval интервал проверки оповещений. По умолчанию DefaultInterval.
+	Interval time.Duration
+
+	Logger *zap.Logger
+}
+
+// Dispatcher периодически получает оповещения, распределяет их
+// по получателям и группам и отправляет уведомления об изменениях:
+// новых сработавших и разрешенных оповещениях. Оповещения в состоянии pending,
+// заглушенные и подавленные оповещения не отправляются, разрешенные
+// отправляются только если о них уже сообщалось.
+//
+// Уведомление каждой группы отправляется в отдельной горутине, поэтому медленный
+// получатель не задерживает остальных. Пока отправка группы не завершена,
+// новые уведомления этой группы не отправляются.
+type Dispatcher struct {
+	opts *DispatcherOptions
+
+	mu     sync.Mutex
+	groups map[string]*group
+	// sending отправки, которые еще не завершены.
+	sending sync.WaitGroup
+}
+
+// group состояние уведомлений группы оповещений одного получателя.
+type group struct {
+	receiver string
+	labels   model.Labels
+
+	// sent последнее отправленное состояние оповещений группы.
+	sent map[string]alerting.State
+	// changedAt время первого неотправленного изменения.
+	changedAt time.Time
+	// sentAt время последнего уведомления.
+	sentAt time.Time
+	// inflight сообщает, что уведомление группы отправляется.
+	inflight bool
+}
+
+// NewDispatcher создает рассылку уведомлений.
+func NewDispatcher(opts *DispatcherOptions) *Dispatcher {
+	if opts.GroupWait <= 0 {
+		opts.GroupWait = DefaultGroupWait
+	}
+	if opts.RepeatInterval <= 0 {
+		opts.RepeatInterval = DefaultRepeatInterval
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	return &Dispatcher{
+		opts:   opts,
+		groups: make(map[string]*group),
+	}
+}
+
+// Run проверяет оповещения с интервалом до отмены контекста.
+// После отмены ожидает завершения начатых отправок.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.sending.Wait()
+			return nil
+		case <-ticker.C:
+		}
+
+		d.Dispatch(ctx, time.Now())
+	}
+}
+
+// Dispatch распределяет текущие оповещения по группам на момент now
+// и начинает отправку уведомлений группам, для которых они назрели.
+// Не ожидает завершения отправок.
+func (d *Dispatcher) Dispatch(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := make(map[string]map[string]alerting.Alert)
+	for _, a := range d.opts.Source.Alerts() {
+		if a.State == alerting.StatePending || a.Muted() {
+			continue
+		}
+		for _, receiver := range route(d.opts.Routes, d.opts.DefaultReceiver, a) {
+			labels := groupLabels(a.Labels, d.opts.GroupBy)
+			key := receiver + labels.String()
+
+			if _, ok := d.groups[key]; !ok {
+				d.groups[key] = &group{
+					receiver: receiver,
+					labels:   labels,
+					sent:     make(map[string]alerting.State),
+				}
+			}
+			if current[key] == nil {
+				current[key] = make(map[string]alerting.Alert)
+			}
+			current[key][a.Key()] = a
+		}
+	}
+
+	for key, g := range d.groups {
+		alerts, ok := current[key]
+		if !ok {
+			delete(d.groups, key)
+			continue
+		}
+
+		if g.inflight {
+			continue
+		}
+		n, ok := d.due(g, alerts, now)
+		if !ok {
+			continue
+		}
+
+		g.inflight = true
+		d.sending.Add(1)
+		go d.send(ctx, g, n, now)
+	}
+}
+
+// send отправляет уведомление группы g и сохраняет результат отправки.
+func (d *Dispatcher) send(ctx context.Context, g *group, n Notification, now time.Time) {
+	defer d.sending.Done()
+
+	err := d.notify(ctx, n)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	g.inflight = false
+	if err != nil {
+		d.opts.Logger.Error("send notification", zap.String("receiver", g.receiver), zap.Error(err), zap.String("scope", "notify/Dispatch"))
+		// повтор после ожидания группы
+		g.changedAt = now
+		return
+	}
+
+	for _, a := range n.Alerts {
+		g.sent[a.Key()] = a.State
+	}
+	g.sentAt = now
+	g.changedAt = time.Time{}
+}
+
+// due обновляет состояние группы по ее текущим оповещениям
+// и возвращает уведомление, если его пора отправить.
+func (d *Dispatcher) due(g *group, alerts map[string]alerting.Alert, now time.Time) (Notification, bool) {
+	for key := range g.sent {
+		if _, ok := alerts[key]; !ok {
+			delete(g.sent, key)
+		}
+	}
+
+	n := Notification{
+		Receiver:    g.receiver,
+		Status:      alerting.StateResolved,
+		GroupLabels: g.labels,
+	}
+	changed := false
+	for key, a := range alerts {
+		switch sent := g.sent[key]; {
+		case a.State == alerting.StateFiring:
+			n.Status = alerting.StateFiring
+			changed = changed || sent != alerting.StateFiring
+		case sent == alerting.StateFiring:
+			changed = true
+		default:
+			// о разрешенном оповещении уже сообщалось
+			// или не сообщалось как о сработавшем
+			continue
+		}
+		n.Alerts = append(n.Alerts, a)
+	}
+
+	if changed && g.changedAt.IsZero() {
+		g.changedAt = now
+	}
+
+	ready := !g.changedAt.IsZero() && now.Sub(g.changedAt) >= d.opts.GroupWait
+	repeat := n.Status == alerting.StateFiring && !g.sentAt.IsZero() && now.Sub(g.sentAt) >= d.opts.RepeatInterval
+	if !ready && !repeat {
+		return Notification{}, false
+	}
+
+	if len(n.Alerts) == 0 {
+		g.changedAt = time.Time{}
+		return Notification{}, false
+	}
+
+	sort.Slice(n.Alerts, func(i, j int) bool {
+		return n.Alerts[i].Key() < n.Alerts[j].Key()
+	})
+	return n, true
+}
+
+// notify отправляет уведомление получателю.
+func (d *Dispatcher) notify(ctx context.Context, n Notification) error {
+	notifier, ok := d.opts.Receivers[n.Receiver]
+	if !ok {
+		return fmt.Errorf("notify/Dispatch: unknown receiver %q", n.Receiver)
+	}
+	d.opts.Logger.Info("send notification",
+		zap.String("receiver", n.Receiver),
+		zap.String("group", n.GroupLabels.String()),
+		zap.Int("alerts", len(n.Alerts)),
+		zap.String("scope", "notify/Dispatch"),
+	)
+	return notifier.Notify(ctx, n)
+}
+
+// Close закрывает каналы уведомлений, которые требуют закрытия.
+func (d *Dispatcher) Close() error {
+	var errs []error
+	for _, n := range d.opts.Receivers {
+		if c, ok := n.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// groupLabels возвращает значения меток groupBy оповещения.
+func groupLabels(labels model.Labels, groupBy []string) model.Labels {
+	g := make(model.Labels, len(groupBy))
+	for _, name := range groupBy {
+		if v, ok := labels[name]; ok {
+			g[name] = v
+		}
+	}
+	return g
+}
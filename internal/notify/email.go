@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ Notifier = (*Email)(nil)
+
+// DefaultEmailTimeout время на подключение и отправку письма по умолчанию.
+const DefaultEmailTimeout = 30 * time.Second
+
+// EmailOptions параметры отправки уведомлений по электронной почте.
+type EmailOptions struct {
+	// Addr адрес SMTP сервера в формате host:port.
+	Addr string
+	From string
+	To   []string
+
+	// Username и Password включают аутентификацию PLAIN.
+	// Без TLS пароль передается только на localhost.
+	Username string
+	Password string
+
+	// Timeout ограничивает подключение и отправку письма, по умолчанию DefaultEmailTimeout.
+	Timeout time.Duration
+}
+
+// Email отправляет уведомления письмами через SMTP сервер.
+type Email struct {
+	opts EmailOptions
+	auth smtp.Auth
+}
+
+// NewEmail возвращает новый экземпляр Email.
+func NewEmail(opts EmailOptions) *Email {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultEmailTimeout
+	}
+	e := &Email{opts: opts}
+	if opts.Username != "" {
+		host, _, _ := net.SplitHostPort(opts.Addr)
+		e.auth = smtp.PlainAuth("", opts.Username, opts.Password, host)
+	}
+	return e
+}
+
+// Notify отправляет письмо с уведомлением.
+// Отправка ограничена Timeout и прерывается отменой контекста.
+func (e *Email) Notify(ctx context.Context, n Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	defer cancel()
+
+	msg := e.message(n, time.Now())
+	if err := e.send(ctx, msg); err != nil {
+		return fmt.Errorf("notify/Email: send mail: %w", err)
+	}
+	return nil
+}
+
+// send повторяет smtp.SendMail на соединении, срок которого ограничен контекстом.
+func (e *Email) send(ctx context.Context, msg []byte) error {
+	d := net.Dialer{Timeout: e.opts.Timeout}
+	conn, err := d.DialContext(ctx, "tcp", e.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// отмена контекста прерывает ожидание ответа сервера
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	host, _, _ := net.SplitHostPort(e.opts.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("new client: %w", err)
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return fmt.Errorf("hello: %w", err)
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if e.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("server doesn't support AUTH")
+		}
+		if err := c.Auth(e.auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.opts.From); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	for _, to := range e.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("rcpt %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close data: %w", err)
+	}
+	return c.Quit()
+}
+
+// message формирует письмо: заголовки и текстовый список оповещений.
+func (e *Email) message(n Notification, now time.Time) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", e.opts.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.opts.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject(n))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	for _, a := range n.Alerts {
+		fmt.Fprintf(&b, "[%s] %s %s%s = %g\r\n", strings.ToUpper(string(a.State)), a.Rule, a.Metric, a.Labels.String(), a.Value)
+		fmt.Fprintf(&b, "  active since %s\r\n", a.ActiveAt.Format(time.RFC3339))
+		if a.ResolvedAt != nil {
+			fmt.Fprintf(&b, "  resolved at %s\r\n", a.ResolvedAt.Format(time.RFC3339))
+		}
+	}
+	return b.Bytes()
+}
+
+// subject возвращает тему письма, например [FIRING:2] alertname=HighCPU.
+func subject(n Notification) string {
+	keys := make([]string, 0, len(n.GroupLabels))
+	for k := range n.GroupLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+n.GroupLabels[k])
+	}
+
+	s := fmt.Sprintf("[%s:%d]", strings.ToUpper(string(n.Status)), len(n.Alerts))
+	if len(parts) > 0 {
+		s += " " + strings.Join(parts, " ")
+	}
+	// перевод строки в значении метки нарушил бы заголовки письма
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
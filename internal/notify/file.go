@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/mailru/easyjson"
+)
+
+var _ Notifier = (*File)(nil)
+
+// File дописывает уведомления в файл, по одному JSON-объекту на строку.
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFile открывает файл path на дозапись.
+func NewFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0664)
+	if err != nil {
+		return nil, fmt.Errorf("notify/NewFile: open file: %w", err)
+	}
+	return &File{file: f}, nil
+}
+
+// Notify записывает уведомление в файл.
+func (f *File) Notify(_ context.Context, n Notification) error {
+	b, err := easyjson.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("notify/File: marshal notification: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("notify/File: write: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает файл.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
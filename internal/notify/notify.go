@@ -0,0 +1,41 @@
+// Package notify доставляет оповещения по каналам уведомлений:
+// webhook, электронной почте и в файл.
+package notify
+
+import (
+	"context"
+
+	"github.com/htrandev/metrics/internal/alerting"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// Notifier канал доставки уведомлений.
+type Notifier interface {
+	// Notify доставляет уведомление о группе оповещений.
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notification уведомление о группе оповещений.
+//
+//easyjson:json
+type Notification struct {
+	// Receiver имя получателя, выбранного маршрутизацией.
+	Receiver string `json:"receiver"`
+	// Status firing, если в группе есть сработавшие оповещения, иначе resolved.
+	Status alerting.State `json:"status"`
+	// GroupLabels метки, по которым сгруппированы оповещения.
+	GroupLabels model.Labels `json:"group_labels"`
+	// Alerts сработавшие и разрешенные с прошлого уведомления оповещения группы.
+	Alerts []alerting.Alert `json:"alerts"`
+}
+
+// Firing возвращает количество сработавших оповещений уведомления.
+func (n Notification) Firing() int {
+	count := 0
+	for _, a := range n.Alerts {
+		if a.State == alerting.StateFiring {
+			count++
+		}
+	}
+	return count
+}
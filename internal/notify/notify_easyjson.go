@@ -0,0 +1,181 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package notify
+
+import (
+	json "encoding/json"
+	alerting "github.com/htrandev/metrics/internal/alerting"
+	model "github.com/htrandev/metrics/internal/model"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonAba0bf1bDecodeGithubComHtrandevMetricsInternalNotify(in *jlexer.Lexer, out *Notification) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "receiver":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Receiver = string(in.String())
+			}
+		case "status":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Status = alerting.State(in.String())
+			}
+		case "group_labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.GroupLabels = make(model.Labels)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v1 = string(in.String())
+					}
+					(out.GroupLabels)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "alerts":
+			if in.IsNull() {
+				in.Skip()
+				out.Alerts = nil
+			} else {
+				in.Delim('[')
+				if out.Alerts == nil {
+					if !in.IsDelim(']') {
+						out.Alerts = make([]alerting.Alert, 0, 0)
+					} else {
+						out.Alerts = []alerting.Alert{}
+					}
+				} else {
+					out.Alerts = (out.Alerts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 alerting.Alert
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v2).UnmarshalEasyJSON(in)
+					}
+					out.Alerts = append(out.Alerts, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAba0bf1bEncodeGithubComHtrandevMetricsInternalNotify(out *jwriter.Writer, in Notification) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"receiver\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Receiver))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"group_labels\":"
+		out.RawString(prefix)
+		if in.GroupLabels == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.GroupLabels {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v3Name))
+				out.RawByte(':')
+				out.String(string(v3Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"alerts\":"
+		out.RawString(prefix)
+		if in.Alerts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v4, v5 := range in.Alerts {
+				if v4 > 0 {
+					out.RawByte(',')
+				}
+				(v5).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Notification) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAba0bf1bEncodeGithubComHtrandevMetricsInternalNotify(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Notification) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAba0bf1bEncodeGithubComHtrandevMetricsInternalNotify(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Notification) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAba0bf1bDecodeGithubComHtrandevMetricsInternalNotify(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Notification) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAba0bf1bDecodeGithubComHtrandevMetricsInternalNotify(l, v)
+}
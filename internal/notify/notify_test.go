@@ -0,0 +1,535 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/mailru/easyjson"
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/alerting"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+var testNotification = Notification{
+	Receiver:    "ops",
+	Status:      alerting.StateFiring,
+	GroupLabels: model.Labels{"alertname": "HighCPU"},
+	Alerts: []alerting.Alert{
+		{
+			Rule:     "HighCPU",
+			Metric:   "cpu",
+			Severity: "critical",
+			Labels:   model.Labels{"alertname": "HighCPU", "host": "web-1", "severity": "critical"},
+			State:    alerting.StateFiring,
+			Value:    97,
+			ActiveAt: time.Unix(1700000000, 0).UTC(),
+		},
+	},
+}
+
+func TestWebhook(t *testing.T) {
+	testCases := []struct {
+		name             string
+		codes            []int
+		expectedAttempts int32
+		wantErr          bool
+	}{
+		{
+			name:             "success",
+			codes:            []int{http.StatusOK},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retry server errors",
+			codes:            []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "retries exhausted",
+			codes:            []int{http.StatusInternalServerError},
+			expectedAttempts: 3,
+			wantErr:          true,
+		},
+		{
+			name:             "permanent error",
+			codes:            []int{http.StatusBadRequest},
+			expectedAttempts: 1,
+			wantErr:          true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				var got Notification
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.NoError(t, easyjson.Unmarshal(body, &got))
+				require.Equal(t, testNotification.Receiver, got.Receiver)
+
+				w.WriteHeader(tc.codes[min(n, len(tc.codes))-1])
+			}))
+			defer srv.Close()
+
+			w := NewWebhook(srv.URL, resty.New(), nil, WithRetry(2, time.Millisecond, 2*time.Millisecond))
+			err := w.Notify(context.Background(), testNotification)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+// smtpMessage письмо, принятое fakeSMTP.
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTP минимальный SMTP сервер для проверки отправки писем.
+type fakeSMTP struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	msgs []smtpMessage
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeSMTP{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var msg smtpMessage
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			_, creds, _ := strings.Cut(arg, " ")
+			b, _ := base64.StdEncoding.DecodeString(creds)
+			msg.auth = string(b)
+			tp.PrintfLine("235 Authentication successful")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.mu.Lock()
+			s.msgs = append(s.msgs, msg)
+			s.mu.Unlock()
+			msg = smtpMessage{}
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTP) messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.msgs...)
+}
+
+func TestEmail(t *testing.T) {
+	srv := newFakeSMTP(t)
+
+	e := NewEmail(EmailOptions{
+		Addr:     srv.ln.Addr().String(),
+		From:     "metrics@example.com",
+		To:       []string{"ops@example.com", "dev@example.com"},
+		Username: "user",
+		Password: "secret",
+	})
+	require.NoError(t, e.Notify(context.Background(), testNotification))
+
+	msgs := srv.messages()
+	require.Len(t, msgs, 1)
+	msg := msgs[0]
+	require.Equal(t, "\x00user\x00secret", msg.auth)
+	require.Equal(t, "metrics@example.com", msg.from)
+	require.Equal(t, []string{"ops@example.com", "dev@example.com"}, msg.to)
+	require.Contains(t, msg.data, "Subject: [FIRING:1] alertname=HighCPU\n")
+	require.Contains(t, msg.data, `[FIRING] HighCPU cpu{alertname="HighCPU",host="web-1",severity="critical"} = 97`)
+}
+
+func TestEmailTimeout(t *testing.T) {
+	// сервер принимает соединение, но не отвечает
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	e := NewEmail(EmailOptions{
+		Addr:    ln.Addr().String(),
+		From:    "metrics@example.com",
+		To:      []string{"ops@example.com"},
+		Timeout: 50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	require.Error(t, e.Notify(context.Background(), testNotification))
+	require.Less(t, time.Since(start), time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	e = NewEmail(EmailOptions{
+		Addr: ln.Addr().String(),
+		From: "metrics@example.com",
+		To:   []string{"ops@example.com"},
+	})
+	start = time.Now()
+	require.Error(t, e.Notify(ctx, testNotification))
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+
+	f, err := NewFile(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Notify(context.Background(), testNotification))
+	require.NoError(t, f.Notify(context.Background(), testNotification))
+	require.NoError(t, f.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var got Notification
+	require.NoError(t, easyjson.Unmarshal([]byte(lines[1]), &got))
+	require.Equal(t, testNotification, got)
+}
+
+type mockSource struct {
+	alerts []alerting.Alert
+}
+
+func (m *mockSource) Alerts() []alerting.Alert {
+	return m.alerts
+}
+
+type mockNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	err  error
+}
+
+func (m *mockNotifier) Notify(_ context.Context, n Notification) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, n)
+	return nil
+}
+
+func (m *mockNotifier) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sent)
+}
+
+// blockingNotifier ожидает release перед отправкой.
+type blockingNotifier struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (b *blockingNotifier) Notify(ctx context.Context, _ Notification) error {
+	b.calls.Add(1)
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatch проверяет оповещения и ожидает завершения отправок.
+func dispatch(d *Dispatcher, ctx context.Context, now time.Time) {
+	d.Dispatch(ctx, now)
+	d.sending.Wait()
+}
+
+func testAlert(rule, host, severity string, state alerting.State) alerting.Alert {
+	return alerting.Alert{
+		Rule:     rule,
+		Metric:   "cpu",
+		Severity: severity,
+		Labels:   model.Labels{alerting.LabelAlertName: rule, alerting.LabelSeverity: severity, "host": host},
+		State:    state,
+	}
+}
+
+func TestDispatcherGrouping(t *testing.T) {
+	source := &mockSource{}
+	ops := &mockNotifier{}
+	d := NewDispatcher(&DispatcherOptions{
+		Source:          source,
+		Receivers:       map[string]Notifier{"ops": ops},
+		DefaultReceiver: "ops",
+		GroupBy:         []string{alerting.LabelAlertName},
+		GroupWait:       30 * time.Second,
+		RepeatInterval:  time.Hour,
+	})
+
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	// pending оповещения не отправляются
+	source.alerts = []alerting.Alert{testAlert("HighCPU", "web-1", "critical", alerting.StatePending)}
+	dispatch(d, ctx, now)
+	dispatch(d, ctx, now.Add(time.Minute))
+	require.Empty(t, ops.sent)
+
+	// заглушенные и подавленные оповещения не отправляются
+	silenced := testAlert("HighCPU", "web-1", "critical", alerting.StateFiring)
+	silenced.SilencedBy = []string{"maintenance"}
+	inhibited := testAlert("HighLoad", "web-1", "warning", alerting.StateFiring)
+	inhibited.InhibitedBy = []string{"HostDown/up{host=\"web-1\"}"}
+	source.alerts = []alerting.Alert{silenced, inhibited}
+	dispatch(d, ctx, now)
+	dispatch(d, ctx, now.Add(time.Minute))
+	require.Empty(t, ops.sent)
+
+	// оповещения, сработавшие рядом, объединяются в одно уведомление
+	source.alerts = []alerting.Alert{testAlert("HighCPU", "web-1", "critical", alerting.StateFiring)}
+	dispatch(d, ctx, now)
+	source.alerts = append(source.alerts, testAlert("HighCPU", "web-2", "critical", alerting.StateFiring))
+	dispatch(d, ctx, now.Add(10*time.Second))
+	require.Empty(t, ops.sent)
+
+	dispatch(d, ctx, now.Add(30*time.Second))
+	require.Len(t, ops.sent, 1)
+	require.Equal(t, alerting.StateFiring, ops.sent[0].Status)
+	require.Equal(t, model.Labels{alerting.LabelAlertName: "HighCPU"}, ops.sent[0].GroupLabels)
+	require.Len(t, ops.sent[0].Alerts, 2)
+
+	// без изменений уведомление не повторяется до repeat interval
+	dispatch(d, ctx, now.Add(10*time.Minute))
+	require.Len(t, ops.sent, 1)
+	dispatch(d, ctx, now.Add(30*time.Second+time.Hour))
+	require.Len(t, ops.sent, 2)
+	require.Len(t, ops.sent[1].Alerts, 2)
+
+	// разрешение оповещения отправляется после ожидания группы
+	source.alerts[1].State = alerting.StateResolved
+	start := now.Add(2 * time.Hour)
+	dispatch(d, ctx, start)
+	dispatch(d, ctx, start.Add(30*time.Second))
+	require.Len(t, ops.sent, 3)
+	require.Equal(t, alerting.StateFiring, ops.sent[2].Status)
+	require.Equal(t, alerting.StateResolved, ops.sent[2].Alerts[1].State)
+
+	// о разрешенном оповещении повторно не сообщается
+	source.alerts[0].State = alerting.StateResolved
+	dispatch(d, ctx, start.Add(time.Minute))
+	dispatch(d, ctx, start.Add(2*time.Minute))
+	require.Len(t, ops.sent, 4)
+	require.Equal(t, alerting.StateResolved, ops.sent[3].Status)
+	require.Len(t, ops.sent[3].Alerts, 1)
+
+	dispatch(d, ctx, start.Add(5*time.Hour))
+	require.Len(t, ops.sent, 4)
+}
+
+func TestDispatcherRouting(t *testing.T) {
+	testCases := []struct {
+		name     string
+		alert    alerting.Alert
+		expected map[string]int
+	}{
+		{
+			name:     "by severity",
+			alert:    testAlert("HighCPU", "web-1", "critical", alerting.StateFiring),
+			expected: map[string]int{"pager": 1},
+		},
+		{
+			name:     "by label with continue",
+			alert:    testAlert("HighCPU", "db-1", "warning", alerting.StateFiring),
+			expected: map[string]int{"db": 1, "chat": 1},
+		},
+		{
+			name:     "default receiver",
+			alert:    testAlert("HighCPU", "web-1", "warning", alerting.StateFiring),
+			expected: map[string]int{"chat": 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			receivers := map[string]*mockNotifier{"pager": {}, "db": {}, "chat": {}}
+			notifiers := make(map[string]Notifier, len(receivers))
+			for name, r := range receivers {
+				notifiers[name] = r
+			}
+
+			d := NewDispatcher(&DispatcherOptions{
+				Source:    &mockSource{alerts: []alerting.Alert{tc.alert}},
+				Receivers: notifiers,
+				Routes: []Route{
+					{Receiver: "pager", Severities: []string{"critical"}},
+					{Receiver: "db", Matchers: []model.Matcher{{Name: "host", Value: "db-1"}}, Continue: true},
+					{Receiver: "chat", Matchers: []model.Matcher{{Name: "host", Value: "db-1"}}},
+				},
+				DefaultReceiver: "chat",
+				GroupWait:       time.Second,
+			})
+
+			now := time.Now()
+			dispatch(d, context.Background(), now)
+			dispatch(d, context.Background(), now.Add(time.Second))
+
+			for name, r := range receivers {
+				require.Len(t, r.sent, tc.expected[name], name)
+			}
+		})
+	}
+}
+
+func TestDispatcherSlowReceiver(t *testing.T) {
+	slow := &blockingNotifier{release: make(chan struct{})}
+	fast := &mockNotifier{}
+	d := NewDispatcher(&DispatcherOptions{
+		Source:    &mockSource{alerts: []alerting.Alert{testAlert("HighCPU", "web-1", "critical", alerting.StateFiring)}},
+		Receivers: map[string]Notifier{"slow": slow, "fast": fast},
+		Routes: []Route{
+			{Receiver: "slow", Continue: true},
+			{Receiver: "fast"},
+		},
+		GroupWait: time.Second,
+	})
+
+	ctx := context.Background()
+	now := time.Now()
+	d.Dispatch(ctx, now)
+	d.Dispatch(ctx, now.Add(time.Second))
+
+	// медленный получатель не задерживает остальных
+	require.Eventually(t, func() bool { return fast.count() == 1 }, time.Second, 10*time.Millisecond)
+
+	// пока отправка не завершена, уведомление группы не отправляется повторно
+	d.Dispatch(ctx, now.Add(time.Minute))
+	require.Equal(t, int32(1), slow.calls.Load())
+
+	close(slow.release)
+	d.sending.Wait()
+	require.Equal(t, int32(1), slow.calls.Load())
+	require.Equal(t, 1, fast.count())
+}
+
+func TestNewFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notify.yaml")
+	content := `
+group_by: [alertname]
+group_wait: 10s
+repeat_interval: 1h
+default_receiver: ops
+receivers:
+  - name: ops
+    webhook:
+      url: http://localhost:9093/hook
+      max_retry: 1
+    file:
+      path: ` + filepath.Join(dir, "alerts.jsonl") + `
+  - name: mail
+    email:
+      addr: localhost:25
+      from: metrics@example.com
+      to: [ops@example.com]
+routes:
+  - receiver: mail
+    severity: [critical]
+    matchers: ['team="db"']
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, cfg.GroupWait)
+
+	d, err := NewFromConfig(cfg, &mockSource{}, nil)
+	require.NoError(t, err)
+	require.Len(t, d.opts.Receivers, 2)
+	require.Len(t, d.opts.Routes, 1)
+	require.NoError(t, d.Close())
+
+	cfg.Routes[0].Receiver = "unknown"
+	_, err = NewFromConfig(cfg, &mockSource{}, nil)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"slices"
+
+	"github.com/htrandev/metrics/internal/alerting"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// Route правило выбора получателя оповещения.
+type Route struct {
+	// Receiver имя получателя.
+	Receiver string
+	// Severities допустимые важности оповещения. Если пусто, важность не проверяется.
+	Severities []string
+	// Matchers матчеры меток оповещения.
+	Matchers []model.Matcher
+	// Continue продолжает проверку следующих маршрутов после совпадения.
+	Continue bool
+}
+
+// matches сообщает, подходит ли оповещение под маршрут.
+func (r Route) matches(a alerting.Alert) bool {
+	if len(r.Severities) > 0 && !slices.Contains(r.Severities, a.Severity) {
+		return false
+	}
+	return model.MatchLabels(a.Labels, r.Matchers)
+}
+
+// route возвращает получателей оповещения: первый подходящий маршрут
+// и следующие за ним, пока у совпавших маршрутов установлен Continue.
+// Если ни один маршрут не подошел, возвращается получатель по умолчанию.
+func route(routes []Route, defaultReceiver string, a alerting.Alert) []string {
+	var receivers []string
+	for _, r := range routes {
+		if !r.matches(a) {
+			continue
+		}
+		receivers = append(receivers, r.Receiver)
+		if !r.Continue {
+			break
+		}
+	}
+
+	if len(receivers) == 0 && defaultReceiver != "" {
+		receivers = append(receivers, defaultReceiver)
+	}
+	return receivers
+}
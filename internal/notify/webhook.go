@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/pkg/retry"
+)
+
+var _ Notifier = (*Webhook)(nil)
+
+const (
+	// DefaultWebhookMaxRetry количество повторных отправок по умолчанию.
+	DefaultWebhookMaxRetry = 3
+	// DefaultWebhookMinBackoff начальная задержка перед повторной отправкой.
+	DefaultWebhookMinBackoff = 500 * time.Millisecond
+	// DefaultWebhookMaxBackoff максимальная задержка перед повторной отправкой.
+	DefaultWebhookMaxBackoff = 30 * time.Second
+)
+
+// Webhook отправляет уведомления JSON-объектом методом POST на указанный URL.
+// Неудачные отправки повторяются с экспоненциальной задержкой.
+type Webhook struct {
+	url    string
+	client *resty.Client
+
+	maxRetry   int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	logger *zap.Logger
+}
+
+// WebhookOption настраивает Webhook.
+type WebhookOption func(*Webhook)
+
+// WithRetry задает количество повторных отправок и границы задержки между ними.
+func WithRetry(maxRetry int, minBackoff, maxBackoff time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		w.maxRetry = maxRetry
+		if minBackoff > 0 {
+			w.minBackoff = minBackoff
+		}
+		if maxBackoff > 0 {
+			w.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// NewWebhook возвращает новый экземпляр Webhook.
+func NewWebhook(url string, client *resty.Client, l *zap.Logger, opts ...WebhookOption) *Webhook {
+	if l == nil {
+		l = zap.NewNop()
+	}
+	if client == nil {
+		client = resty.New().
+			SetTimeout(30 * time.Second)
+	}
+
+	w := &Webhook{
+		url:        url,
+		client:     client,
+		maxRetry:   DefaultWebhookMaxRetry,
+		minBackoff: DefaultWebhookMinBackoff,
+		maxBackoff: DefaultWebhookMaxBackoff,
+		logger:     l.With(zap.String("scope", "notify/Webhook")),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Notify отправляет уведомление, повторяя неудачные попытки.
+// Ответы 408, 429 и 5xx повторяются, остальные ошибки клиента нет.
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	body, err := easyjson.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("notify/Webhook: marshal notification: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		w.logger.Warn("send notification", zap.Int("attempt", attempt+1), zap.Error(err))
+
+		if errors.Is(err, retry.ErrPermanent) || attempt >= w.maxRetry {
+			return fmt.Errorf("notify/Webhook: %w", err)
+		}
+
+		timer := time.NewTimer(retry.Backoff(attempt, w.minBackoff, w.maxBackoff))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("notify/Webhook: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// post отправляет тело уведомления и проверяет код ответа.
+func (w *Webhook) post(ctx context.Context, body []byte) error {
+	resp, err := w.client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		SetContext(ctx).
+		Post(w.url)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+
+	return retry.CheckStatus(resp.StatusCode())
+}
@@ -0,0 +1,251 @@
+// Package otlp реализует прием метрик OpenTelemetry по протоколу OTLP/HTTP.
+package otlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/htrandev/metrics/internal/cumulative"
+	"github.com/htrandev/metrics/internal/exposition"
+	"github.com/htrandev/metrics/internal/model"
+)
+
+const (
+	// ContentTypeProtobuf тип содержимого OTLP в формате protobuf.
+	ContentTypeProtobuf = "application/x-protobuf"
+	// ContentTypeJSON тип содержимого OTLP в формате JSON.
+	ContentTypeJSON = "application/json"
+)
+
+// MaxBodySize максимальный размер тела запроса.
+const MaxBodySize = 16 << 20
+
+// flagNoRecordedValue флаг точки без значения.
+const flagNoRecordedValue = uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK)
+
+var (
+	// ErrInvalidRequest возвращается при некорректном теле запроса.
+	ErrInvalidRequest = errors.New("invalid otlp request")
+	// ErrUnsupportedContentType возвращается при неподдерживаемом типе содержимого.
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// MediaType возвращает тип содержимого OTLP без параметров.
+// Пустой тип считается protobuf.
+func MediaType(contentType string) (string, error) {
+	if contentType == "" {
+		return ContentTypeProtobuf, nil
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("otlp/mediaType: %v: %w", err, ErrUnsupportedContentType)
+	}
+	if mt != ContentTypeProtobuf && mt != ContentTypeJSON {
+		return "", fmt.Errorf("otlp/mediaType: %q: %w", mt, ErrUnsupportedContentType)
+	}
+	return mt, nil
+}
+
+// Decode разбирает ExportMetricsServiceRequest в формате mediaType.
+func Decode(data []byte, mediaType string) (*colmetricspb.ExportMetricsServiceRequest, error) {
+	var req colmetricspb.ExportMetricsServiceRequest
+
+	var err error
+	switch mediaType {
+	case ContentTypeProtobuf:
+		err = proto.Unmarshal(data, &req)
+	case ContentTypeJSON:
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, &req)
+	default:
+		return nil, fmt.Errorf("otlp/decode: %q: %w", mediaType, ErrUnsupportedContentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("otlp/decode: %v: %w", err, ErrInvalidRequest)
+	}
+	return &req, nil
+}
+
+// Encode кодирует ответ ExportMetricsServiceResponse в формате mediaType.
+func Encode(resp *colmetricspb.ExportMetricsServiceResponse, mediaType string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch mediaType {
+	case ContentTypeJSON:
+		data, err = protojson.Marshal(resp)
+	default:
+		data, err = proto.Marshal(resp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("otlp/encode: %w", err)
+	}
+	return data, nil
+}
+
+// Batch результат преобразования запроса.
+type Batch struct {
+	Metrics []model.MetricDto
+	// Rejected количество точек, которые не удалось преобразовать.
+	Rejected int64
+
+	counters *cumulative.Batch
+}
+
+// Converter преобразует метрики OTLP в MetricDto.
+//
+// Атрибуты ресурса и точки становятся метками, атрибуты точки имеют приоритет.
+// Gauge сохраняются как gauge. Sum сохраняются как counter: для дельта-временности
+// прибавляется целая часть значения точки, а дробный остаток переносится на следующую точку серии,
+// для накопленной сохраняется прирост, см. пакет cumulative.
+// Немонотонные накопленные Sum (UpDownCounter) сохраняются как gauge.
+// Гистограммы и summary не поддерживаются и учитываются в Rejected.
+type Converter struct {
+	counters *cumulative.Tracker
+}
+
+// NewConverter создает Converter.
+func NewConverter() *Converter {
+	return &Converter{counters: cumulative.NewTracker()}
+}
+
+// Convert преобразует запрос в пакет метрик.
+// Текущие значения впервые встреченных накопленных счетчиков запрашиваются у g.
+func (c *Converter) Convert(ctx context.Context, req *colmetricspb.ExportMetricsServiceRequest, g cumulative.Getter) (*Batch, error) {
+	batch := &Batch{
+		Metrics:  make([]model.MetricDto, 0),
+		counters: c.counters.Begin(g),
+	}
+
+	for _, rm := range req.GetResourceMetrics() {
+		resource := attributes(nil, rm.GetResource().GetAttributes())
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				if err := model.ValidateName(m.GetName()); err != nil {
+					c.Release(batch)
+					return nil, fmt.Errorf("otlp/convert: metric: %v: %w", err, ErrInvalidRequest)
+				}
+				c.convertMetric(ctx, batch, m, resource)
+			}
+		}
+	}
+	return batch, nil
+}
+
+// Commit завершает сохраненный пакет.
+func (c *Converter) Commit(b *Batch) {
+	c.counters.Commit(b.counters)
+}
+
+// Release возвращает прирост счетчиков несохраненного пакета,
+// он будет учтен следующим значением серии. После Commit ничего не делает.
+func (c *Converter) Release(b *Batch) {
+	c.counters.Release(b.counters)
+}
+
+// convertMetric добавляет в пакет точки метрики m.
+func (c *Converter) convertMetric(ctx context.Context, batch *Batch, m *metricspb.Metric, resource model.Labels) {
+	switch data := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, dp := range data.Gauge.GetDataPoints() {
+			v, ok := pointValue(dp)
+			if !ok {
+				continue
+			}
+			metric := model.Gauge(m.GetName(), v)
+			metric.Labels = attributes(resource, dp.GetAttributes())
+			batch.Metrics = append(batch.Metrics, metric)
+		}
+	case *metricspb.Metric_Sum:
+		temporality := data.Sum.GetAggregationTemporality()
+		for _, dp := range data.Sum.GetDataPoints() {
+			v, ok := pointValue(dp)
+			if !ok {
+				continue
+			}
+			labels := attributes(resource, dp.GetAttributes())
+
+			switch {
+			case temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+				metric := model.Counter(m.GetName(), 0)
+				metric.Labels = labels
+				if metric.Value.Counter = batch.counters.Add(metric, v); metric.Value.Counter != 0 {
+					batch.Metrics = append(batch.Metrics, metric)
+				}
+			case temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE && data.Sum.GetIsMonotonic():
+				metric := model.Counter(m.GetName(), 0)
+				metric.Labels = labels
+				if metric.Value.Counter = batch.counters.Delta(ctx, metric, v); metric.Value.Counter != 0 {
+					batch.Metrics = append(batch.Metrics, metric)
+				}
+			case temporality == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+				metric := model.Gauge(m.GetName(), v)
+				metric.Labels = labels
+				batch.Metrics = append(batch.Metrics, metric)
+			default:
+				batch.Rejected++
+			}
+		}
+	case *metricspb.Metric_Histogram:
+		batch.Rejected += int64(len(data.Histogram.GetDataPoints()))
+	case *metricspb.Metric_ExponentialHistogram:
+		batch.Rejected += int64(len(data.ExponentialHistogram.GetDataPoints()))
+	case *metricspb.Metric_Summary:
+		batch.Rejected += int64(len(data.Summary.GetDataPoints()))
+	}
+}
+
+// pointValue возвращает значение точки. Возвращает false для точек без значения.
+func pointValue(dp *metricspb.NumberDataPoint) (float64, bool) {
+	if dp.GetFlags()&flagNoRecordedValue != 0 {
+		return 0, false
+	}
+	switch v := dp.GetValue().(type) {
+	case *metricspb.NumberDataPoint_AsDouble:
+		return v.AsDouble, true
+	case *metricspb.NumberDataPoint_AsInt:
+		return float64(v.AsInt), true
+	}
+	return 0, false
+}
+
+// attributes возвращает метки base, дополненные атрибутами attrs.
+// Атрибуты-массивы и вложенные списки пропускаются,
+// недопустимые символы в именах атрибутов (например, точки) заменяются на подчеркивание.
+func attributes(base model.Labels, attrs []*commonpb.KeyValue) model.Labels {
+	labels := base.Clone()
+	for _, kv := range attrs {
+		v, ok := attributeValue(kv.GetValue())
+		if !ok || kv.GetKey() == "" {
+			continue
+		}
+		if labels == nil {
+			labels = make(model.Labels, len(attrs))
+		}
+		labels[exposition.SanitizeLabelName(kv.GetKey())] = v
+	}
+	return labels
+}
+
+func attributeValue(v *commonpb.AnyValue) (string, bool) {
+	switch v := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue, true
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue), true
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10), true
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64), true
+	}
+	return "", false
+}
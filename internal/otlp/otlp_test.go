@@ -0,0 +1,190 @@
+package otlp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+func stringAttr(k, v string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+}
+
+func intPoint(v int64, attrs ...*commonpb.KeyValue) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{Attributes: attrs, Value: &metricspb.NumberDataPoint_AsInt{AsInt: v}}
+}
+
+func doublePoint(v float64, attrs ...*commonpb.KeyValue) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{Attributes: attrs, Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: v}}
+}
+
+func sum(name string, temporality metricspb.AggregationTemporality, monotonic bool, points ...*metricspb.NumberDataPoint) *metricspb.Metric {
+	return &metricspb.Metric{Name: name, Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+		DataPoints:             points,
+		AggregationTemporality: temporality,
+		IsMonotonic:            monotonic,
+	}}}
+}
+
+func exportRequest(metrics ...*metricspb.Metric) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource:     &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", "api")}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: metrics}},
+		}},
+	}
+}
+
+func withLabels(m model.MetricDto, labels model.Labels) model.MetricDto {
+	m.Labels = labels
+	return m
+}
+
+func TestDecode(t *testing.T) {
+	req := exportRequest(&metricspb.Metric{Name: "load", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+		DataPoints: []*metricspb.NumberDataPoint{doublePoint(0.5)},
+	}}})
+
+	t.Run("protobuf", func(t *testing.T) {
+		data, err := proto.Marshal(req)
+		require.NoError(t, err)
+
+		got, err := Decode(data, ContentTypeProtobuf)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(req, got))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data := `{"resourceMetrics":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+			"scopeMetrics":[{"metrics":[{"name":"load","gauge":{"dataPoints":[{"asDouble":0.5}]}}]}]}]}`
+
+		got, err := Decode([]byte(data), ContentTypeJSON)
+		require.NoError(t, err)
+		require.True(t, proto.Equal(req, got))
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := Decode([]byte("{"), ContentTypeJSON)
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("media type", func(t *testing.T) {
+		mt, err := MediaType("application/json; charset=utf-8")
+		require.NoError(t, err)
+		require.Equal(t, ContentTypeJSON, mt)
+
+		_, err = MediaType("text/plain")
+		require.ErrorIs(t, err, ErrUnsupportedContentType)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	api := model.Labels{"service_name": "api"}
+
+	testCases := []struct {
+		name             string
+		requests         []*colmetricspb.ExportMetricsServiceRequest
+		expected         [][]model.MetricDto
+		expectedRejected int64
+	}{
+		{
+			name: "gauge",
+			requests: []*colmetricspb.ExportMetricsServiceRequest{
+				exportRequest(&metricspb.Metric{Name: "queue.size", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+					DataPoints: []*metricspb.NumberDataPoint{intPoint(7, stringAttr("queue", "mail"))},
+				}}}),
+			},
+			expected: [][]model.MetricDto{
+				{withLabels(model.Gauge("queue.size", 7), model.Labels{"service_name": "api", "queue": "mail"})},
+			},
+		},
+		{
+			name: "delta sum",
+			requests: []*colmetricspb.ExportMetricsServiceRequest{
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true, intPoint(3))),
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true, intPoint(2))),
+			},
+			expected: [][]model.MetricDto{
+				{withLabels(model.Counter("requests", 3), api)},
+				{withLabels(model.Counter("requests", 2), api)},
+			},
+		},
+		{
+			name: "fractional delta sum",
+			requests: []*colmetricspb.ExportMetricsServiceRequest{
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true, doublePoint(0.4))),
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true, doublePoint(0.4))),
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA, true, doublePoint(0.4))),
+			},
+			expected: [][]model.MetricDto{
+				{},
+				{},
+				{withLabels(model.Counter("requests", 1), api)},
+			},
+		},
+		{
+			name: "cumulative sum",
+			requests: []*colmetricspb.ExportMetricsServiceRequest{
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, true, doublePoint(10))),
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, true, doublePoint(14))),
+				exportRequest(sum("requests", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, true, doublePoint(1))),
+			},
+			expected: [][]model.MetricDto{
+				{withLabels(model.Counter("requests", 10), api)},
+				{withLabels(model.Counter("requests", 4), api)},
+				{withLabels(model.Counter("requests", 1), api)},
+			},
+		},
+		{
+			name: "cumulative up down counter",
+			requests: []*colmetricspb.ExportMetricsServiceRequest{
+				exportRequest(sum("connections", metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, false, intPoint(5))),
+			},
+			expected: [][]model.MetricDto{
+				{withLabels(model.Gauge("connections", 5), api)},
+			},
+		},
+		{
+			name: "histogram rejected",
+			requests: []*colmetricspb.ExportMetricsServiceRequest{
+				exportRequest(&metricspb.Metric{Name: "latency", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+					DataPoints: []*metricspb.HistogramDataPoint{{Count: 1}},
+				}}}),
+			},
+			expected:         [][]model.MetricDto{{}},
+			expectedRejected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewConverter()
+			for i, req := range tc.requests {
+				batch, err := c.Convert(ctx, req, nil)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected[i], batch.Metrics)
+				require.Equal(t, tc.expectedRejected, batch.Rejected)
+				c.Commit(batch)
+			}
+		})
+	}
+
+	t.Run("metric without name", func(t *testing.T) {
+		_, err := NewConverter().Convert(ctx, exportRequest(&metricspb.Metric{}), nil)
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("invalid metric name", func(t *testing.T) {
+		_, err := NewConverter().Convert(ctx, exportRequest(&metricspb.Metric{Name: "requests,total"}), nil)
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+}
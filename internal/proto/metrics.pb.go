@@ -24,8 +24,9 @@ const (
 type Metric_MType int32
 
 const (
-	Metric_GAUGE   Metric_MType = 0
-	Metric_COUNTER Metric_MType = 1
+	Metric_GAUGE     Metric_MType = 0
+	Metric_COUNTER   Metric_MType = 1
+	Metric_HISTOGRAM Metric_MType = 2
 )
 
 // Enum value maps for Metric_MType.
@@ -33,10 +34,12 @@ var (
 	Metric_MType_name = map[int32]string{
 		0: "GAUGE",
 		1: "COUNTER",
+		2: "HISTOGRAM",
 	}
 	Metric_MType_value = map[string]int32{
-		"GAUGE":   0,
-		"COUNTER": 1,
+		"GAUGE":     0,
+		"COUNTER":   1,
+		"HISTOGRAM": 2,
 	}
 )
 
@@ -64,13 +67,15 @@ func (x Metric_MType) Number() protoreflect.EnumNumber {
 
 // Metric определяет единичную метрику.
 type Metric struct {
-	state            protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Id    string                 `protobuf:"bytes,1,opt,name=id,proto3"`
-	xxx_hidden_Type  Metric_MType           `protobuf:"varint,2,opt,name=type,proto3,enum=metrics.Metric_MType"`
-	xxx_hidden_Delta int64                  `protobuf:"varint,3,opt,name=delta,proto3"`
-	xxx_hidden_Value float64                `protobuf:"fixed64,4,opt,name=value,proto3"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id        string                 `protobuf:"bytes,1,opt,name=id,proto3"`
+	xxx_hidden_Type      Metric_MType           `protobuf:"varint,2,opt,name=type,proto3,enum=metrics.Metric_MType"`
+	xxx_hidden_Delta     int64                  `protobuf:"varint,3,opt,name=delta,proto3"`
+	xxx_hidden_Value     float64                `protobuf:"fixed64,4,opt,name=value,proto3"`
+	xxx_hidden_Histogram *Histogram             `protobuf:"bytes,5,opt,name=histogram,proto3"`
+	xxx_hidden_Labels    map[string]string      `protobuf:"bytes,6,rep,name=labels,proto3" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *Metric) Reset() {
@@ -126,6 +131,20 @@ func (x *Metric) GetValue() float64 {
 	return 0
 }
 
+func (x *Metric) GetHistogram() *Histogram {
+	if x != nil {
+		return x.xxx_hidden_Histogram
+	}
+	return nil
+}
+
+func (x *Metric) GetLabels() map[string]string {
+	if x != nil {
+		return x.xxx_hidden_Labels
+	}
+	return nil
+}
+
 func (x *Metric) SetId(v string) {
 	x.xxx_hidden_Id = v
 }
@@ -142,6 +161,25 @@ func (x *Metric) SetValue(v float64) {
 	x.xxx_hidden_Value = v
 }
 
+func (x *Metric) SetHistogram(v *Histogram) {
+	x.xxx_hidden_Histogram = v
+}
+
+func (x *Metric) SetLabels(v map[string]string) {
+	x.xxx_hidden_Labels = v
+}
+
+func (x *Metric) HasHistogram() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Histogram != nil
+}
+
+func (x *Metric) ClearHistogram() {
+	x.xxx_hidden_Histogram = nil
+}
+
 type Metric_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
@@ -151,6 +189,10 @@ type Metric_builder struct {
 	Delta int64
 	// Поле value для метрик-измерителей.
 	Value float64
+	// Поле histogram для метрик-гистограмм.
+	Histogram *Histogram
+	// Метки (измерения) метрики.
+	Labels map[string]string
 }
 
 func (b0 Metric_builder) Build() *Metric {
@@ -161,20 +203,127 @@ func (b0 Metric_builder) Build() *Metric {
 	x.xxx_hidden_Type = b.Type
 	x.xxx_hidden_Delta = b.Delta
 	x.xxx_hidden_Value = b.Value
+	x.xxx_hidden_Histogram = b.Histogram
+	x.xxx_hidden_Labels = b.Labels
+	return m0
+}
+
+// Histogram определяет распределение значений по бакетам.
+type Histogram struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Bounds []float64              `protobuf:"fixed64,1,rep,packed,name=bounds,proto3"`
+	xxx_hidden_Counts []uint64               `protobuf:"varint,2,rep,packed,name=counts,proto3"`
+	xxx_hidden_Sum    float64                `protobuf:"fixed64,3,opt,name=sum,proto3"`
+	xxx_hidden_Count  uint64                 `protobuf:"varint,4,opt,name=count,proto3"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Histogram) Reset() {
+	*x = Histogram{}
+	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Histogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Histogram) ProtoMessage() {}
+
+func (x *Histogram) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Histogram) GetBounds() []float64 {
+	if x != nil {
+		return x.xxx_hidden_Bounds
+	}
+	return nil
+}
+
+func (x *Histogram) GetCounts() []uint64 {
+	if x != nil {
+		return x.xxx_hidden_Counts
+	}
+	return nil
+}
+
+func (x *Histogram) GetSum() float64 {
+	if x != nil {
+		return x.xxx_hidden_Sum
+	}
+	return 0
+}
+
+func (x *Histogram) GetCount() uint64 {
+	if x != nil {
+		return x.xxx_hidden_Count
+	}
+	return 0
+}
+
+func (x *Histogram) SetBounds(v []float64) {
+	x.xxx_hidden_Bounds = v
+}
+
+func (x *Histogram) SetCounts(v []uint64) {
+	x.xxx_hidden_Counts = v
+}
+
+func (x *Histogram) SetSum(v float64) {
+	x.xxx_hidden_Sum = v
+}
+
+func (x *Histogram) SetCount(v uint64) {
+	x.xxx_hidden_Count = v
+}
+
+type Histogram_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Верхние границы бакетов в порядке возрастания.
+	Bounds []float64
+	// Количество наблюдений в каждом бакете, последний элемент — бакет +Inf.
+	Counts []uint64
+	// Сумма всех наблюдений.
+	Sum float64
+	// Общее количество наблюдений.
+	Count uint64
+}
+
+func (b0 Histogram_builder) Build() *Histogram {
+	m0 := &Histogram{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Bounds = b.Bounds
+	x.xxx_hidden_Counts = b.Counts
+	x.xxx_hidden_Sum = b.Sum
+	x.xxx_hidden_Count = b.Count
 	return m0
 }
 
 // UpdateMetricsRequest содержит список метрик для обновления.
 type UpdateMetricsRequest struct {
-	state              protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Metrics *[]*Metric             `protobuf:"bytes,1,rep,name=metrics,proto3"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Metrics   *[]*Metric             `protobuf:"bytes,1,rep,name=metrics,proto3"`
+	xxx_hidden_Encrypted []byte                 `protobuf:"bytes,2,opt,name=encrypted,proto3"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *UpdateMetricsRequest) Reset() {
 	*x = UpdateMetricsRequest{}
-	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	mi := &file_internal_proto_metrics_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -186,7 +335,7 @@ func (x *UpdateMetricsRequest) String() string {
 func (*UpdateMetricsRequest) ProtoMessage() {}
 
 func (x *UpdateMetricsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_metrics_proto_msgTypes[1]
+	mi := &file_internal_proto_metrics_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -206,14 +355,31 @@ func (x *UpdateMetricsRequest) GetMetrics() []*Metric {
 	return nil
 }
 
+func (x *UpdateMetricsRequest) GetEncrypted() []byte {
+	if x != nil {
+		return x.xxx_hidden_Encrypted
+	}
+	return nil
+}
+
 func (x *UpdateMetricsRequest) SetMetrics(v []*Metric) {
 	x.xxx_hidden_Metrics = &v
 }
 
+func (x *UpdateMetricsRequest) SetEncrypted(v []byte) {
+	if v == nil {
+		v = []byte{}
+	}
+	x.xxx_hidden_Encrypted = v
+}
+
 type UpdateMetricsRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	Metrics []*Metric
+	// Зашифрованный конвертом crypto.Encrypt UpdateMetricsRequest с метриками.
+	// Если задан, поле metrics не используется.
+	Encrypted []byte
 }
 
 func (b0 UpdateMetricsRequest_builder) Build() *UpdateMetricsRequest {
@@ -221,6 +387,7 @@ func (b0 UpdateMetricsRequest_builder) Build() *UpdateMetricsRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.xxx_hidden_Metrics = &b.Metrics
+	x.xxx_hidden_Encrypted = b.Encrypted
 	return m0
 }
 
@@ -233,7 +400,7 @@ type UpdateMetricsResponse struct {
 
 func (x *UpdateMetricsResponse) Reset() {
 	*x = UpdateMetricsResponse{}
-	mi := &file_internal_proto_metrics_proto_msgTypes[2]
+	mi := &file_internal_proto_metrics_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -245,7 +412,7 @@ func (x *UpdateMetricsResponse) String() string {
 func (*UpdateMetricsResponse) ProtoMessage() {}
 
 func (x *UpdateMetricsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_metrics_proto_msgTypes[2]
+	mi := &file_internal_proto_metrics_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -272,39 +439,55 @@ var File_internal_proto_metrics_proto protoreflect.FileDescriptor
 
 const file_internal_proto_metrics_proto_rawDesc = "" +
 	"\n" +
-	"\x1cinternal/proto/metrics.proto\x12\ametrics\"\x90\x01\n" +
+	"\x1cinternal/proto/metrics.proto\x12\ametrics\"\xc1\x02\n" +
 	"\x06Metric\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x15.metrics.Metric.MTypeR\x04type\x12\x14\n" +
 	"\x05delta\x18\x03 \x01(\x03R\x05delta\x12\x14\n" +
-	"\x05value\x18\x04 \x01(\x01R\x05value\"\x1f\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x120\n" +
+	"\thistogram\x18\x05 \x01(\v2\x12.metrics.HistogramR\thistogram\x123\n" +
+	"\x06labels\x18\x06 \x03(\v2\x1b.metrics.Metric.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
 	"\x05MType\x12\t\n" +
 	"\x05GAUGE\x10\x00\x12\v\n" +
-	"\aCOUNTER\x10\x01\"A\n" +
+	"\aCOUNTER\x10\x01\x12\r\n" +
+	"\tHISTOGRAM\x10\x02\"c\n" +
+	"\tHistogram\x12\x16\n" +
+	"\x06bounds\x18\x01 \x03(\x01R\x06bounds\x12\x16\n" +
+	"\x06counts\x18\x02 \x03(\x04R\x06counts\x12\x10\n" +
+	"\x03sum\x18\x03 \x01(\x01R\x03sum\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x04R\x05count\"_\n" +
 	"\x14UpdateMetricsRequest\x12)\n" +
-	"\ametrics\x18\x01 \x03(\v2\x0f.metrics.MetricR\ametrics\"\x17\n" +
+	"\ametrics\x18\x01 \x03(\v2\x0f.metrics.MetricR\ametrics\x12\x1c\n" +
+	"\tencrypted\x18\x02 \x01(\fR\tencrypted\"\x17\n" +
 	"\x15UpdateMetricsResponse2Y\n" +
 	"\aMetrics\x12N\n" +
 	"\rUpdateMetrics\x12\x1d.metrics.UpdateMetricsRequest\x1a\x1e.metrics.UpdateMetricsResponseB,Z*github.com/htrandev/metrics/internal/protob\x06proto3"
 
 var file_internal_proto_metrics_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_proto_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_internal_proto_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_internal_proto_metrics_proto_goTypes = []any{
 	(Metric_MType)(0),             // 0: metrics.Metric.MType
 	(*Metric)(nil),                // 1: metrics.Metric
-	(*UpdateMetricsRequest)(nil),  // 2: metrics.UpdateMetricsRequest
-	(*UpdateMetricsResponse)(nil), // 3: metrics.UpdateMetricsResponse
+	(*Histogram)(nil),             // 2: metrics.Histogram
+	(*UpdateMetricsRequest)(nil),  // 3: metrics.UpdateMetricsRequest
+	(*UpdateMetricsResponse)(nil), // 4: metrics.UpdateMetricsResponse
+	nil,                           // 5: metrics.Metric.LabelsEntry
 }
 var file_internal_proto_metrics_proto_depIdxs = []int32{
 	0, // 0: metrics.Metric.type:type_name -> metrics.Metric.MType
-	1, // 1: metrics.UpdateMetricsRequest.metrics:type_name -> metrics.Metric
-	2, // 2: metrics.Metrics.UpdateMetrics:input_type -> metrics.UpdateMetricsRequest
-	3, // 3: metrics.Metrics.UpdateMetrics:output_type -> metrics.UpdateMetricsResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2, // 1: metrics.Metric.histogram:type_name -> metrics.Histogram
+	5, // 2: metrics.Metric.labels:type_name -> metrics.Metric.LabelsEntry
+	1, // 3: metrics.UpdateMetricsRequest.metrics:type_name -> metrics.Metric
+	3, // 4: metrics.Metrics.UpdateMetrics:input_type -> metrics.UpdateMetricsRequest
+	4, // 5: metrics.Metrics.UpdateMetrics:output_type -> metrics.UpdateMetricsResponse
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_internal_proto_metrics_proto_init() }
@@ -318,7 +501,7 @@ func file_internal_proto_metrics_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_metrics_proto_rawDesc), len(file_internal_proto_metrics_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   3,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,563 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.19.6
+// source: internal/proto/remote.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MetricType задаёт тип семейства метрик.
+type MetricMetadata_MetricType int32
+
+const (
+	MetricMetadata_UNKNOWN        MetricMetadata_MetricType = 0
+	MetricMetadata_COUNTER        MetricMetadata_MetricType = 1
+	MetricMetadata_GAUGE          MetricMetadata_MetricType = 2
+	MetricMetadata_HISTOGRAM      MetricMetadata_MetricType = 3
+	MetricMetadata_GAUGEHISTOGRAM MetricMetadata_MetricType = 4
+	MetricMetadata_SUMMARY        MetricMetadata_MetricType = 5
+	MetricMetadata_INFO           MetricMetadata_MetricType = 6
+	MetricMetadata_STATESET       MetricMetadata_MetricType = 7
+)
+
+// Enum value maps for MetricMetadata_MetricType.
+var (
+	MetricMetadata_MetricType_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "COUNTER",
+		2: "GAUGE",
+		3: "HISTOGRAM",
+		4: "GAUGEHISTOGRAM",
+		5: "SUMMARY",
+		6: "INFO",
+		7: "STATESET",
+	}
+	MetricMetadata_MetricType_value = map[string]int32{
+		"UNKNOWN":        0,
+		"COUNTER":        1,
+		"GAUGE":          2,
+		"HISTOGRAM":      3,
+		"GAUGEHISTOGRAM": 4,
+		"SUMMARY":        5,
+		"INFO":           6,
+		"STATESET":       7,
+	}
+)
+
+func (x MetricMetadata_MetricType) Enum() *MetricMetadata_MetricType {
+	p := new(MetricMetadata_MetricType)
+	*p = x
+	return p
+}
+
+func (x MetricMetadata_MetricType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MetricMetadata_MetricType) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_remote_proto_enumTypes[0].Descriptor()
+}
+
+func (MetricMetadata_MetricType) Type() protoreflect.EnumType {
+	return &file_internal_proto_remote_proto_enumTypes[0]
+}
+
+func (x MetricMetadata_MetricType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// WriteRequest содержит пакет серий, отправляемый Prometheus.
+type WriteRequest struct {
+	state                 protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Timeseries *[]*TimeSeries         `protobuf:"bytes,1,rep,name=timeseries,proto3"`
+	xxx_hidden_Metadata   *[]*MetricMetadata     `protobuf:"bytes,3,rep,name=metadata,proto3"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *WriteRequest) Reset() {
+	*x = WriteRequest{}
+	mi := &file_internal_proto_remote_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRequest) ProtoMessage() {}
+
+func (x *WriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_remote_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *WriteRequest) GetTimeseries() []*TimeSeries {
+	if x != nil {
+		if x.xxx_hidden_Timeseries != nil {
+			return *x.xxx_hidden_Timeseries
+		}
+	}
+	return nil
+}
+
+func (x *WriteRequest) GetMetadata() []*MetricMetadata {
+	if x != nil {
+		if x.xxx_hidden_Metadata != nil {
+			return *x.xxx_hidden_Metadata
+		}
+	}
+	return nil
+}
+
+func (x *WriteRequest) SetTimeseries(v []*TimeSeries) {
+	x.xxx_hidden_Timeseries = &v
+}
+
+func (x *WriteRequest) SetMetadata(v []*MetricMetadata) {
+	x.xxx_hidden_Metadata = &v
+}
+
+type WriteRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Timeseries []*TimeSeries
+	Metadata   []*MetricMetadata
+}
+
+func (b0 WriteRequest_builder) Build() *WriteRequest {
+	m0 := &WriteRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Timeseries = &b.Timeseries
+	x.xxx_hidden_Metadata = &b.Metadata
+	return m0
+}
+
+// TimeSeries определяет серию: набор меток и значения.
+type TimeSeries struct {
+	state              protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Labels  *[]*Label              `protobuf:"bytes,1,rep,name=labels,proto3"`
+	xxx_hidden_Samples *[]*Sample             `protobuf:"bytes,2,rep,name=samples,proto3"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TimeSeries) Reset() {
+	*x = TimeSeries{}
+	mi := &file_internal_proto_remote_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeSeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeSeries) ProtoMessage() {}
+
+func (x *TimeSeries) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_remote_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TimeSeries) GetLabels() []*Label {
+	if x != nil {
+		if x.xxx_hidden_Labels != nil {
+			return *x.xxx_hidden_Labels
+		}
+	}
+	return nil
+}
+
+func (x *TimeSeries) GetSamples() []*Sample {
+	if x != nil {
+		if x.xxx_hidden_Samples != nil {
+			return *x.xxx_hidden_Samples
+		}
+	}
+	return nil
+}
+
+func (x *TimeSeries) SetLabels(v []*Label) {
+	x.xxx_hidden_Labels = &v
+}
+
+func (x *TimeSeries) SetSamples(v []*Sample) {
+	x.xxx_hidden_Samples = &v
+}
+
+type TimeSeries_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Метки серии, имя метрики передается в метке __name__.
+	Labels  []*Label
+	Samples []*Sample
+}
+
+func (b0 TimeSeries_builder) Build() *TimeSeries {
+	m0 := &TimeSeries{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Labels = &b.Labels
+	x.xxx_hidden_Samples = &b.Samples
+	return m0
+}
+
+// Label определяет пару имя-значение метки.
+type Label struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Name  string                 `protobuf:"bytes,1,opt,name=name,proto3"`
+	xxx_hidden_Value string                 `protobuf:"bytes,2,opt,name=value,proto3"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Label) Reset() {
+	*x = Label{}
+	mi := &file_internal_proto_remote_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Label) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Label) ProtoMessage() {}
+
+func (x *Label) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_remote_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Label) GetName() string {
+	if x != nil {
+		return x.xxx_hidden_Name
+	}
+	return ""
+}
+
+func (x *Label) GetValue() string {
+	if x != nil {
+		return x.xxx_hidden_Value
+	}
+	return ""
+}
+
+func (x *Label) SetName(v string) {
+	x.xxx_hidden_Name = v
+}
+
+func (x *Label) SetValue(v string) {
+	x.xxx_hidden_Value = v
+}
+
+type Label_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Name  string
+	Value string
+}
+
+func (b0 Label_builder) Build() *Label {
+	m0 := &Label{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Name = b.Name
+	x.xxx_hidden_Value = b.Value
+	return m0
+}
+
+// Sample определяет значение серии в момент времени.
+type Sample struct {
+	state                protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Value     float64                `protobuf:"fixed64,1,opt,name=value,proto3"`
+	xxx_hidden_Timestamp int64                  `protobuf:"varint,2,opt,name=timestamp,proto3"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Sample) Reset() {
+	*x = Sample{}
+	mi := &file_internal_proto_remote_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Sample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sample) ProtoMessage() {}
+
+func (x *Sample) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_remote_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *Sample) GetValue() float64 {
+	if x != nil {
+		return x.xxx_hidden_Value
+	}
+	return 0
+}
+
+func (x *Sample) GetTimestamp() int64 {
+	if x != nil {
+		return x.xxx_hidden_Timestamp
+	}
+	return 0
+}
+
+func (x *Sample) SetValue(v float64) {
+	x.xxx_hidden_Value = v
+}
+
+func (x *Sample) SetTimestamp(v int64) {
+	x.xxx_hidden_Timestamp = v
+}
+
+type Sample_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Value float64
+	// Время в миллисекундах с начала эпохи.
+	Timestamp int64
+}
+
+func (b0 Sample_builder) Build() *Sample {
+	m0 := &Sample{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Value = b.Value
+	x.xxx_hidden_Timestamp = b.Timestamp
+	return m0
+}
+
+// MetricMetadata описывает тип семейства метрик.
+type MetricMetadata struct {
+	state                       protoimpl.MessageState    `protogen:"opaque.v1"`
+	xxx_hidden_Type             MetricMetadata_MetricType `protobuf:"varint,1,opt,name=type,proto3,enum=metrics.remote.MetricMetadata_MetricType"`
+	xxx_hidden_MetricFamilyName string                    `protobuf:"bytes,2,opt,name=metric_family_name,json=metricFamilyName,proto3"`
+	xxx_hidden_Help             string                    `protobuf:"bytes,4,opt,name=help,proto3"`
+	xxx_hidden_Unit             string                    `protobuf:"bytes,5,opt,name=unit,proto3"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *MetricMetadata) Reset() {
+	*x = MetricMetadata{}
+	mi := &file_internal_proto_remote_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricMetadata) ProtoMessage() {}
+
+func (x *MetricMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_remote_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *MetricMetadata) GetType() MetricMetadata_MetricType {
+	if x != nil {
+		return x.xxx_hidden_Type
+	}
+	return MetricMetadata_UNKNOWN
+}
+
+func (x *MetricMetadata) GetMetricFamilyName() string {
+	if x != nil {
+		return x.xxx_hidden_MetricFamilyName
+	}
+	return ""
+}
+
+func (x *MetricMetadata) GetHelp() string {
+	if x != nil {
+		return x.xxx_hidden_Help
+	}
+	return ""
+}
+
+func (x *MetricMetadata) GetUnit() string {
+	if x != nil {
+		return x.xxx_hidden_Unit
+	}
+	return ""
+}
+
+func (x *MetricMetadata) SetType(v MetricMetadata_MetricType) {
+	x.xxx_hidden_Type = v
+}
+
+func (x *MetricMetadata) SetMetricFamilyName(v string) {
+	x.xxx_hidden_MetricFamilyName = v
+}
+
+func (x *MetricMetadata) SetHelp(v string) {
+	x.xxx_hidden_Help = v
+}
+
+func (x *MetricMetadata) SetUnit(v string) {
+	x.xxx_hidden_Unit = v
+}
+
+type MetricMetadata_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Type             MetricMetadata_MetricType
+	MetricFamilyName string
+	Help             string
+	Unit             string
+}
+
+func (b0 MetricMetadata_builder) Build() *MetricMetadata {
+	m0 := &MetricMetadata{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Type = b.Type
+	x.xxx_hidden_MetricFamilyName = b.MetricFamilyName
+	x.xxx_hidden_Help = b.Help
+	x.xxx_hidden_Unit = b.Unit
+	return m0
+}
+
+var File_internal_proto_remote_proto protoreflect.FileDescriptor
+
+const file_internal_proto_remote_proto_rawDesc = "" +
+	"\n" +
+	"\x1binternal/proto/remote.proto\x12\x0emetrics.remote\"\x8c\x01\n" +
+	"\fWriteRequest\x12:\n" +
+	"\n" +
+	"timeseries\x18\x01 \x03(\v2\x1a.metrics.remote.TimeSeriesR\n" +
+	"timeseries\x12:\n" +
+	"\bmetadata\x18\x03 \x03(\v2\x1e.metrics.remote.MetricMetadataR\bmetadataJ\x04\b\x02\x10\x03\"m\n" +
+	"\n" +
+	"TimeSeries\x12-\n" +
+	"\x06labels\x18\x01 \x03(\v2\x15.metrics.remote.LabelR\x06labels\x120\n" +
+	"\asamples\x18\x02 \x03(\v2\x16.metrics.remote.SampleR\asamples\"1\n" +
+	"\x05Label\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"<\n" +
+	"\x06Sample\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"\xa0\x02\n" +
+	"\x0eMetricMetadata\x12=\n" +
+	"\x04type\x18\x01 \x01(\x0e2).metrics.remote.MetricMetadata.MetricTypeR\x04type\x12,\n" +
+	"\x12metric_family_name\x18\x02 \x01(\tR\x10metricFamilyName\x12\x12\n" +
+	"\x04help\x18\x04 \x01(\tR\x04help\x12\x12\n" +
+	"\x04unit\x18\x05 \x01(\tR\x04unit\"y\n" +
+	"\n" +
+	"MetricType\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\v\n" +
+	"\aCOUNTER\x10\x01\x12\t\n" +
+	"\x05GAUGE\x10\x02\x12\r\n" +
+	"\tHISTOGRAM\x10\x03\x12\x12\n" +
+	"\x0eGAUGEHISTOGRAM\x10\x04\x12\v\n" +
+	"\aSUMMARY\x10\x05\x12\b\n" +
+	"\x04INFO\x10\x06\x12\f\n" +
+	"\bSTATESET\x10\aB,Z*github.com/htrandev/metrics/internal/protob\x06proto3"
+
+var file_internal_proto_remote_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_internal_proto_remote_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_internal_proto_remote_proto_goTypes = []any{
+	(MetricMetadata_MetricType)(0), // 0: metrics.remote.MetricMetadata.MetricType
+	(*WriteRequest)(nil),           // 1: metrics.remote.WriteRequest
+	(*TimeSeries)(nil),             // 2: metrics.remote.TimeSeries
+	(*Label)(nil),                  // 3: metrics.remote.Label
+	(*Sample)(nil),                 // 4: metrics.remote.Sample
+	(*MetricMetadata)(nil),         // 5: metrics.remote.MetricMetadata
+}
+var file_internal_proto_remote_proto_depIdxs = []int32{
+	2, // 0: metrics.remote.WriteRequest.timeseries:type_name -> metrics.remote.TimeSeries
+	5, // 1: metrics.remote.WriteRequest.metadata:type_name -> metrics.remote.MetricMetadata
+	3, // 2: metrics.remote.TimeSeries.labels:type_name -> metrics.remote.Label
+	4, // 3: metrics.remote.TimeSeries.samples:type_name -> metrics.remote.Sample
+	0, // 4: metrics.remote.MetricMetadata.type:type_name -> metrics.remote.MetricMetadata.MetricType
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_remote_proto_init() }
+func file_internal_proto_remote_proto_init() {
+	if File_internal_proto_remote_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_proto_remote_proto_rawDesc), len(file_internal_proto_remote_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_internal_proto_remote_proto_goTypes,
+		DependencyIndexes: file_internal_proto_remote_proto_depIdxs,
+		EnumInfos:         file_internal_proto_remote_proto_enumTypes,
+		MessageInfos:      file_internal_proto_remote_proto_msgTypes,
+	}.Build()
+	File_internal_proto_remote_proto = out.File
+	file_internal_proto_remote_proto_goTypes = nil
+	file_internal_proto_remote_proto_depIdxs = nil
+}
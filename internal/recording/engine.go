@@ -0,0 +1,126 @@
+// Package recording реализует правила записи: выражения над сохраненными
+// метриками, результаты которых периодически сохраняются как gauge метрики.
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// DefaultInterval интервал вычисления правил по умолчанию.
+const DefaultInterval = 15 * time.Second
+
+// Source возвращает текущие значения всех серий.
+type Source interface {
+	GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error)
+}
+
+// Sink сохраняет результаты правил.
+type Sink interface {
+	StoreMany(ctx context.Context, metrics []model.MetricDto) error
+}
+
+// EngineOptions параметры движка правил записи.
+type EngineOptions struct {
+	Rules  []Rule
+	Source Source
+	Sink   Sink
+
+	// Interval интервал вычисления правил. По умолчанию DefaultInterval.
+	Interval time.Duration
+
+	Logger *zap.Logger
+}
+
+// Engine периодически вычисляет правила записи и сохраняет результаты.
+// Правила вычисляются по порядку, результаты правила доступны
+// следующим за ним правилам в том же вычислении.
+type Engine struct {
+	opts *EngineOptions
+}
+
+// NewEngine создает движок правил записи.
+func NewEngine(opts *EngineOptions) *Engine {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	return &Engine{opts: opts}
+}
+
+// Run вычисляет правила с интервалом до отмены контекста.
+func (e *Engine) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Eval(ctx, time.Now()); err != nil {
+			e.opts.Logger.Error("eval recording rules", zap.Error(err), zap.String("scope", "recording/Run"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Eval вычисляет все правила на момент now и сохраняет результаты одним батчем.
+// Ошибка правила не мешает вычислению и сохранению остальных.
+// Не предназначен для конкурентного вызова.
+func (e *Engine) Eval(ctx context.Context, now time.Time) error {
+	metrics, err := e.opts.Source.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("recording/Eval: get all metrics: %w", err)
+	}
+
+	// результаты правил добавляются в копию, чтобы не менять данные источника
+	ec := &evalContext{now: now, metrics: slices.Clone(metrics)}
+	index := make(map[string]int, len(metrics))
+	for i, m := range metrics {
+		index[m.Key()] = i
+	}
+
+	var (
+		errs    []error
+		results []model.MetricDto
+	)
+	for _, rule := range e.opts.Rules {
+		recorded, err := rule.eval(ec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q: %w", rule.Record, err))
+			continue
+		}
+
+		for _, m := range recorded {
+			m.UpdatedAt = now
+			if i, ok := index[m.Key()]; ok {
+				ec.metrics[i] = m
+				continue
+			}
+			index[m.Key()] = len(ec.metrics)
+			ec.metrics = append(ec.metrics, m)
+		}
+		results = append(results, recorded...)
+	}
+
+	if len(results) > 0 {
+		if err := e.opts.Sink.StoreMany(ctx, results); err != nil {
+			errs = append(errs, fmt.Errorf("store results: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("recording/Eval: %w", errors.Join(errs...))
+	}
+	return nil
+}
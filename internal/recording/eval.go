@@ -0,0 +1,255 @@
+package recording
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// evalContext данные для вычисления выражений.
+type evalContext struct {
+	now     time.Time
+	metrics []model.MetricDto
+}
+
+// series значение серии с метками без имени метрики.
+type series struct {
+	labels model.Labels
+	value  float64
+}
+
+// value результат вычисления: скаляр или набор серий.
+type value struct {
+	scalar bool
+	v      float64
+	vector []series
+}
+
+// node узел выражения.
+type node interface {
+	eval(ec *evalContext) (value, error)
+}
+
+// number числовая константа.
+type number float64
+
+func (n number) eval(*evalContext) (value, error) {
+	return value{scalar: true, v: float64(n)}, nil
+}
+
+// selector выбирает серии по имени или регулярному выражению имени и матчерам меток.
+type selector struct {
+	name     string
+	nameRe   *regexp.Regexp
+	matchers []model.Matcher
+}
+
+func (s *selector) matches(m model.MetricDto) bool {
+	if s.nameRe != nil {
+		if !s.nameRe.MatchString(m.Name) {
+			return false
+		}
+	} else if m.Name != s.name {
+		return false
+	}
+	return model.MatchLabels(m.Labels, s.matchers)
+}
+
+func (s *selector) eval(ec *evalContext) (value, error) {
+	var v value
+	for _, m := range ec.metrics {
+		if !s.matches(m) {
+			continue
+		}
+		if x, ok := sampleValue(m); ok {
+			v.vector = append(v.vector, series{labels: m.Labels, value: x})
+		}
+	}
+	return v, nil
+}
+
+// sampleValue возвращает числовое значение серии.
+// Гистограммы не имеют скалярного значения и пропускаются.
+func sampleValue(m model.MetricDto) (float64, bool) {
+	switch m.Value.Type {
+	case model.TypeGauge:
+		return m.Value.Gauge, true
+	case model.TypeCounter:
+		return float64(m.Value.Counter), true
+	default:
+		return 0, false
+	}
+}
+
+// aggregate сводит серии аргумента к скаляру. Если серий нет,
+// результат тоже пуст, и правило ничего не записывает.
+type aggregate struct {
+	op  string
+	arg node
+}
+
+func (a *aggregate) eval(ec *evalContext) (value, error) {
+	v, err := a.arg.eval(ec)
+	if err != nil || v.scalar {
+		return v, err
+	}
+	if len(v.vector) == 0 {
+		return value{}, nil
+	}
+
+	result := v.vector[0].value
+	for _, s := range v.vector[1:] {
+		switch a.op {
+		case "sum", "avg":
+			result += s.value
+		case "min":
+			result = math.Min(result, s.value)
+		case "max":
+			result = math.Max(result, s.value)
+		}
+	}
+	if a.op == "avg" {
+		result /= float64(len(v.vector))
+	}
+	return value{scalar: true, v: result}, nil
+}
+
+// binary арифметическая операция. Скаляр применяется к каждой серии,
+// серии двух наборов сопоставляются по одинаковым меткам.
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b *binary) eval(ec *evalContext) (value, error) {
+	l, err := b.left.eval(ec)
+	if err != nil {
+		return value{}, err
+	}
+	r, err := b.right.eval(ec)
+	if err != nil {
+		return value{}, err
+	}
+
+	switch {
+	case l.scalar && r.scalar:
+		return value{scalar: true, v: b.apply(l.v, r.v)}, nil
+	case l.scalar:
+		result := make([]series, 0, len(r.vector))
+		for _, s := range r.vector {
+			result = append(result, series{labels: s.labels, value: b.apply(l.v, s.value)})
+		}
+		return value{vector: result}, nil
+	case r.scalar:
+		result := make([]series, 0, len(l.vector))
+		for _, s := range l.vector {
+			result = append(result, series{labels: s.labels, value: b.apply(s.value, r.v)})
+		}
+		return value{vector: result}, nil
+	}
+
+	right := make(map[string]float64, len(r.vector))
+	for _, s := range r.vector {
+		key := s.labels.String()
+		if _, ok := right[key]; ok {
+			return value{}, fmt.Errorf("operator %q: duplicate series %s on the right side", b.op, key)
+		}
+		right[key] = s.value
+	}
+
+	var result []series
+	seen := make(map[string]struct{}, len(l.vector))
+	for _, s := range l.vector {
+		key := s.labels.String()
+		if _, ok := seen[key]; ok {
+			return value{}, fmt.Errorf("operator %q: duplicate series %s on the left side", b.op, key)
+		}
+		seen[key] = struct{}{}
+
+		if rv, ok := right[key]; ok {
+			result = append(result, series{labels: s.labels, value: b.apply(s.value, rv)})
+		}
+	}
+	return value{vector: result}, nil
+}
+
+func (b *binary) apply(l, r float64) float64 {
+	switch b.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	default:
+		return l / r
+	}
+}
+
+// ratePoint последнее учтенное значение счетчика серии.
+type ratePoint struct {
+	value float64
+	ts    time.Time
+	// rate последняя вычисленная скорость, hasRate сообщает, что она есть.
+	rate    float64
+	hasRate bool
+}
+
+// rate скорость роста счетчика в секунду между двумя последними обновлениями
+// серии. Уменьшение значения считается сбросом счетчика. Пока серия
+// не обновлялась, возвращается последняя вычисленная скорость.
+type rate struct {
+	*selector
+	last map[string]ratePoint
+}
+
+func (r *rate) eval(ec *evalContext) (value, error) {
+	var v value
+	seen := make(map[string]struct{}, len(r.last))
+	for _, m := range ec.metrics {
+		if !r.matches(m) {
+			continue
+		}
+		x, ok := sampleValue(m)
+		if !ok {
+			continue
+		}
+
+		ts := m.UpdatedAt
+		if ts.IsZero() {
+			ts = ec.now
+		}
+
+		key := m.Key()
+		seen[key] = struct{}{}
+
+		p, ok := r.last[key]
+		if ok && ts.After(p.ts) {
+			increase := x - p.value
+			if increase < 0 {
+				increase = x
+			}
+			p.rate = increase / ts.Sub(p.ts).Seconds()
+			p.hasRate = true
+		}
+		if !ok || ts.After(p.ts) {
+			p.value = x
+			p.ts = ts
+		}
+		r.last[key] = p
+
+		if p.hasRate {
+			v.vector = append(v.vector, series{labels: m.Labels, value: p.rate})
+		}
+	}
+
+	for key := range r.last {
+		if _, ok := seen[key]; !ok {
+			delete(r.last, key)
+		}
+	}
+	return v, nil
+}
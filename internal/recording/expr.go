@@ -0,0 +1,377 @@
+package recording
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// Грамматика выражений:
+//
+//	expr     = term { ("+" | "-") term }
+//	term     = unary { ("*" | "/") unary }
+//	unary    = "-" unary | primary
+//	primary  = number | "(" expr ")" | func "(" expr ")" | selector
+//	func     = "sum" | "avg" | "min" | "max" | "rate"
+//	selector = (name | "regexp") [ "{" matcher { "," matcher } "}" ]
+//
+// Селектор по строке в кавычках выбирает метрики, имя которых полностью
+// удовлетворяет регулярному выражению. Аргументом rate может быть только селектор.
+
+// tokenKind тип лексемы.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenString
+	tokenMatchers
+	tokenOp
+)
+
+// token лексема выражения.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// lex разбивает выражение на лексемы.
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("+-*/(),", c) >= 0:
+			tokens = append(tokens, token{kind: tokenOp, text: string(c), pos: i})
+			i++
+		case c >= '0' && c <= '9' || c == '.':
+			start := i
+			for i < len(expr) && isNumberChar(expr, i) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[start:i], pos: start})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(expr) && isIdentChar(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expr[start:i], pos: start})
+		case c == '"':
+			s, n, err := lexString(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i += n
+		case c == '{':
+			end, err := matchersEnd(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenMatchers, text: expr[i+1 : i+end], pos: i})
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("position %d: unexpected character %q", i, c)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || c == ':' || c >= '0' && c <= '9' || unicode.IsLetter(rune(c))
+}
+
+// isNumberChar сообщает, продолжает ли символ expr[i] число,
+// включая знак экспоненты, например 1e-3.
+func isNumberChar(expr string, i int) bool {
+	c := expr[i]
+	if c >= '0' && c <= '9' || c == '.' || c == 'e' || c == 'E' {
+		return true
+	}
+	return (c == '+' || c == '-') && i > 0 && (expr[i-1] == 'e' || expr[i-1] == 'E')
+}
+
+// lexString разбирает строку в кавычках в начале s и возвращает ее содержимое
+// и длину вместе с кавычками. Экранируется только кавычка, остальные обратные
+// косые черты сохраняются, чтобы не удваивать их в регулярных выражениях.
+func lexString(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			return b.String(), i + 1, nil
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '"':
+			b.WriteByte('"')
+			i++
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// matchersEnd возвращает позицию закрывающей фигурной скобки в s,
+// пропуская скобки внутри значений в кавычках.
+func matchersEnd(s string) (int, error) {
+	quoted := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == '}':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated label matchers")
+}
+
+// splitMatchers разбивает содержимое фигурных скобок на матчеры по запятым вне кавычек.
+func splitMatchers(s string) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quoted && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case !quoted && s[i] == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, s[start:])
+
+	result := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// parser разбирает выражение методом рекурсивного спуска.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse разбирает выражение правила.
+func parse(expr string) (node, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("empty expr: %w", ErrInvalidRule)
+	}
+
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.expr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept пропускает оператор op, если он следующий.
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("position %d: expected %q, got %s", p.peek().pos, op, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	return fmt.Errorf("position %d: unexpected %s", t.pos, t)
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.accept("+"):
+			op = '+'
+		case p.accept("-"):
+			op = '-'
+		default:
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.accept("*"):
+			op = '*'
+		case p.accept("/"):
+			op = '/'
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if p.accept("-") {
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &binary{op: '-', left: number(0), right: n}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenNumber:
+		p.next()
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: invalid number %q", t.pos, t.text)
+		}
+		return number(v), nil
+	case tokenOp:
+		if !p.accept("(") {
+			return nil, p.unexpected()
+		}
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case tokenIdent:
+		if next := p.tokens[p.pos+1]; next.kind == tokenOp && next.text == "(" {
+			return p.call()
+		}
+		return p.selector()
+	case tokenString:
+		return p.selector()
+	default:
+		return nil, p.unexpected()
+	}
+}
+
+// call разбирает вызов функции.
+func (p *parser) call() (node, error) {
+	name := p.next()
+	p.next()
+
+	arg, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	switch name.text {
+	case "sum", "avg", "min", "max":
+		return &aggregate{op: name.text, arg: arg}, nil
+	case "rate":
+		sel, ok := arg.(*selector)
+		if !ok {
+			return nil, fmt.Errorf("position %d: rate argument must be a metric selector", name.pos)
+		}
+		return &rate{selector: sel, last: make(map[string]ratePoint)}, nil
+	default:
+		return nil, fmt.Errorf("position %d: unknown function %q", name.pos, name.text)
+	}
+}
+
+// selector разбирает имя метрики или регулярное выражение имени и матчеры меток.
+func (p *parser) selector() (node, error) {
+	t := p.next()
+
+	sel := &selector{}
+	if t.kind == tokenString {
+		re, err := regexp.Compile("^(?:" + t.text + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("position %d: name regexp: %w", t.pos, err)
+		}
+		sel.nameRe = re
+	} else {
+		sel.name = t.text
+	}
+
+	if p.peek().kind != tokenMatchers {
+		return sel, nil
+	}
+	m := p.next()
+	for _, expr := range splitMatchers(m.text) {
+		matcher, err := model.ParseMatcher(expr)
+		if err != nil {
+			return nil, fmt.Errorf("position %d: %w", m.pos, err)
+		}
+		sel.matchers = append(sel.matchers, matcher)
+	}
+	return sel, nil
+}
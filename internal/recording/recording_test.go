@@ -0,0 +1,252 @@
+package recording
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+type mockSource struct {
+	metrics []model.MetricDto
+}
+
+func (m *mockSource) GetAll(_ context.Context, _ ...model.Matcher) ([]model.MetricDto, error) {
+	return m.metrics, nil
+}
+
+type mockSink struct {
+	stored [][]model.MetricDto
+}
+
+func (m *mockSink) StoreMany(_ context.Context, metrics []model.MetricDto) error {
+	m.stored = append(m.stored, metrics)
+	return nil
+}
+
+func gauge(name string, v float64, labels model.Labels) model.MetricDto {
+	m := model.Gauge(name, v)
+	m.Labels = labels
+	return m
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "ratio", expr: "HeapInuse / HeapSys"},
+		{name: "precedence and parens", expr: "-(a + b) * 2 / 1e-3"},
+		{name: "aggregation over regexp", expr: `sum("CPUutilization\d+")`},
+		{name: "matchers", expr: `avg(load{host=~"web-.*", dc!="b,c"}) - min(load)`},
+		{name: "rate", expr: `max(rate(PollCount{host="a"}))`},
+		{name: "empty", expr: " ", wantErr: true},
+		{name: "unbalanced parens", expr: "(a + b", wantErr: true},
+		{name: "trailing operator", expr: "a +", wantErr: true},
+		{name: "unknown function", expr: "median(a)", wantErr: true},
+		{name: "rate of expression", expr: "rate(a + b)", wantErr: true},
+		{name: "invalid regexp", expr: `sum("cpu(")`, wantErr: true},
+		{name: "invalid matcher", expr: `a{host}`, wantErr: true},
+		{name: "unterminated string", expr: `sum("cpu)`, wantErr: true},
+		{name: "unexpected character", expr: "a % b", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parse(tc.expr)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidRule)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRuleEval(t *testing.T) {
+	metrics := []model.MetricDto{
+		model.Gauge("HeapInuse", 30),
+		model.Gauge("HeapSys", 120),
+		model.Gauge("CPUutilization1", 10),
+		model.Gauge("CPUutilization2", 30),
+		model.Gauge("CPUutilization", 1000),
+		model.Counter("PollCount", 7),
+		gauge("load", 2, model.Labels{"host": "a"}),
+		gauge("load", 4, model.Labels{"host": "b"}),
+		gauge("cores", 4, model.Labels{"host": "a"}),
+		gauge("cores", 8, model.Labels{"host": "c"}),
+		model.Gauge("zero", 0),
+	}
+
+	testCases := []struct {
+		name     string
+		cfg      RuleConfig
+		expected []model.MetricDto
+		wantErr  bool
+	}{
+		{
+			name:     "ratio",
+			cfg:      RuleConfig{Record: "heap_ratio", Expr: "HeapInuse / HeapSys"},
+			expected: []model.MetricDto{model.Gauge("heap_ratio", 0.25)},
+		},
+		{
+			name:     "sum over regexp",
+			cfg:      RuleConfig{Record: "cpu_total", Expr: `sum("CPUutilization\d+")`},
+			expected: []model.MetricDto{model.Gauge("cpu_total", 40)},
+		},
+		{
+			name:     "avg min max",
+			cfg:      RuleConfig{Record: "spread", Expr: `(max(load) - min(load)) / avg(load)`},
+			expected: []model.MetricDto{model.Gauge("spread", 2.0/3)},
+		},
+		{
+			name: "vector and scalar with rule labels",
+			cfg:  RuleConfig{Record: "load_percent", Expr: "load * 100", Labels: map[string]string{"team": "ops"}},
+			expected: []model.MetricDto{
+				gauge("load_percent", 200, model.Labels{"host": "a", "team": "ops"}),
+				gauge("load_percent", 400, model.Labels{"host": "b", "team": "ops"}),
+			},
+		},
+		{
+			name:     "vectors matched by labels",
+			cfg:      RuleConfig{Record: "load_per_core", Expr: "load / cores"},
+			expected: []model.MetricDto{gauge("load_per_core", 0.5, model.Labels{"host": "a"})},
+		},
+		{
+			name:     "counter",
+			cfg:      RuleConfig{Record: "polls_doubled", Expr: "-PollCount * -2"},
+			expected: []model.MetricDto{model.Gauge("polls_doubled", 14)},
+		},
+		{
+			name:     "missing metric",
+			cfg:      RuleConfig{Record: "missing", Expr: "sum(absent) + 1"},
+			expected: []model.MetricDto{},
+		},
+		{
+			name:     "division by zero skipped",
+			cfg:      RuleConfig{Record: "inf", Expr: "HeapInuse / zero"},
+			expected: []model.MetricDto{},
+		},
+		{
+			name:    "ambiguous match",
+			cfg:     RuleConfig{Record: "bad", Expr: `"CPUutilization\d+" + 1 + "CPUutilization\d+"`},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := NewRule(tc.cfg)
+			require.NoError(t, err)
+
+			result, err := rule.eval(&evalContext{now: time.Now(), metrics: metrics})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result, len(tc.expected))
+			for i, m := range result {
+				require.Equal(t, tc.expected[i].Name, m.Name)
+				require.Equal(t, tc.expected[i].Labels, m.Labels)
+				require.Equal(t, model.TypeGauge, m.Value.Type)
+				require.InDelta(t, tc.expected[i].Value.Gauge, m.Value.Gauge, 1e-9)
+			}
+		})
+	}
+}
+
+func TestRate(t *testing.T) {
+	rule, err := NewRule(RuleConfig{Record: "polls_per_second", Expr: "rate(PollCount)"})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	eval := func(v int64, updatedAt time.Time) []model.MetricDto {
+		m := model.Counter("PollCount", v)
+		m.UpdatedAt = updatedAt
+		result, err := rule.eval(&evalContext{now: updatedAt, metrics: []model.MetricDto{m}})
+		require.NoError(t, err)
+		return result
+	}
+
+	// для скорости нужно два значения
+	require.Empty(t, eval(100, start))
+
+	result := eval(160, start.Add(30*time.Second))
+	require.Len(t, result, 1)
+	require.Equal(t, float64(2), result[0].Value.Gauge)
+
+	// серия не обновлялась: скорость сохраняется
+	result = eval(160, start.Add(30*time.Second))
+	require.Len(t, result, 1)
+	require.Equal(t, float64(2), result[0].Value.Gauge)
+
+	// сброс счетчика
+	result = eval(10, start.Add(40*time.Second))
+	require.Len(t, result, 1)
+	require.Equal(t, float64(1), result[0].Value.Gauge)
+}
+
+func TestEngineEval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - record: heap_ratio
+    expr: HeapInuse / HeapSys
+  - record: broken
+    expr: '"Heap.*" + "Heap.*"'
+  - record: heap_percent
+    expr: heap_ratio * 100
+    labels:
+      unit: percent
+`), 0600))
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	source := &mockSource{metrics: []model.MetricDto{
+		model.Gauge("HeapInuse", 30),
+		model.Gauge("HeapSys", 120),
+	}}
+	sink := &mockSink{}
+	e := NewEngine(&EngineOptions{Rules: rules, Source: source, Sink: sink})
+
+	// ошибка правила не мешает сохранению остальных
+	err = e.Eval(context.Background(), time.Now())
+	require.ErrorContains(t, err, `rule "broken"`)
+
+	require.Len(t, sink.stored, 1)
+	require.Equal(t, []model.MetricDto{
+		model.Gauge("heap_ratio", 0.25),
+		gauge("heap_percent", 25, model.Labels{"unit": "percent"}),
+	}, sink.stored[0])
+	require.Len(t, source.metrics, 2)
+}
+
+func TestLoadRules(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty record", content: "rules:\n  - {expr: a}\n"},
+		{name: "invalid expr", content: "rules:\n  - {record: a, expr: 'b +'}\n"},
+		{name: "duplicate record", content: "rules:\n  - {record: a, expr: b}\n  - {record: a, expr: c}\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "recording.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			_, err := LoadRules(path)
+			require.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
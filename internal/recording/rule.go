@@ -0,0 +1,139 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ErrInvalidRule возвращается при некорректном описании правила.
+var ErrInvalidRule = errors.New("invalid recording rule")
+
+// RuleConfig описание правила записи в YAML файле.
+type RuleConfig struct {
+	// Record имя gauge метрики, в которую записывается результат.
+	Record string `yaml:"record"`
+	// Expr выражение, например HeapInuse / HeapSys или sum("CPUutilization\d+").
+	Expr string `yaml:"expr"`
+	// Labels метки, добавляемые к сериям результата.
+	Labels map[string]string `yaml:"labels"`
+}
+
+// File содержимое файла правил.
+type File struct {
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// LoadRules загружает и проверяет правила из YAML файла.
+func LoadRules(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("recording/LoadRules: read file: %w", err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("recording/LoadRules: unmarshal: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(f.Rules))
+	records := make(map[string]struct{}, len(f.Rules))
+	for i, cfg := range f.Rules {
+		rule, err := NewRule(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("recording/LoadRules: rule %d: %w", i, err)
+		}
+		if _, ok := records[rule.Record]; ok {
+			return nil, fmt.Errorf("recording/LoadRules: duplicate record %q: %w", rule.Record, ErrInvalidRule)
+		}
+		records[rule.Record] = struct{}{}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Rule проверенное правило записи.
+type Rule struct {
+	Record string
+	Expr   string
+	Labels model.Labels
+
+	root node
+}
+
+// NewRule проверяет описание правила и разбирает его выражение.
+func NewRule(cfg RuleConfig) (Rule, error) {
+	if cfg.Record == "" {
+		return Rule{}, fmt.Errorf("empty record: %w", ErrInvalidRule)
+	}
+
+	labels := model.Labels(cfg.Labels)
+	if err := labels.Validate(); err != nil {
+		return Rule{}, fmt.Errorf("record %q: %w: labels: %w", cfg.Record, ErrInvalidRule, err)
+	}
+
+	root, err := parse(cfg.Expr)
+	if err != nil {
+		return Rule{}, fmt.Errorf("record %q: %w", cfg.Record, err)
+	}
+
+	return Rule{
+		Record: cfg.Record,
+		Expr:   cfg.Expr,
+		Labels: labels,
+		root:   root,
+	}, nil
+}
+
+// eval вычисляет выражение правила и возвращает gauge метрики результата.
+// Нечисловые результаты, например деление на ноль, пропускаются.
+func (r Rule) eval(ec *evalContext) ([]model.MetricDto, error) {
+	v, err := r.root.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.scalar {
+		v.vector = []series{{value: v.v}}
+	}
+
+	result := make([]model.MetricDto, 0, len(v.vector))
+	seen := make(map[string]struct{}, len(v.vector))
+	for _, s := range v.vector {
+		if math.IsNaN(s.value) || math.IsInf(s.value, 0) {
+			continue
+		}
+
+		m := model.Gauge(r.Record, s.value)
+		m.Labels = mergeLabels(s.labels, r.Labels)
+
+		key := m.Key()
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("duplicate series %s in result", key)
+		}
+		seen[key] = struct{}{}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// mergeLabels возвращает метки серии, дополненные метками правила.
+// Метки правила перекрывают метки серии.
+func mergeLabels(series, rule model.Labels) model.Labels {
+	if len(rule) == 0 {
+		return series.Clone()
+	}
+	labels := make(model.Labels, len(series)+len(rule))
+	for k, v := range series {
+		labels[k] = v
+	}
+	for k, v := range rule {
+		labels[k] = v
+	}
+	return labels
+}
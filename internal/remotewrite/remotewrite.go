@@ -0,0 +1,207 @@
+// Package remotewrite реализует прием данных по протоколу Prometheus remote_write 1.0.
+package remotewrite
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/htrandev/metrics/internal/cumulative"
+	"github.com/htrandev/metrics/internal/model"
+	pb "github.com/htrandev/metrics/internal/proto"
+)
+
+// nameLabel метка, в которой Prometheus передает имя метрики.
+const nameLabel = "__name__"
+
+// staleNaN значение, которым Prometheus помечает устаревшие серии.
+const staleNaN uint64 = 0x7ff0000000000002
+
+const (
+	// MaxBodySize максимальный размер сжатого тела запроса.
+	MaxBodySize = 16 << 20
+	// MaxDecodedSize максимальный размер распакованного WriteRequest.
+	MaxDecodedSize = 32 << 20
+)
+
+var (
+	// ErrInvalidRequest возвращается при некорректном теле запроса.
+	ErrInvalidRequest = errors.New("invalid remote write request")
+	// ErrTooLarge возвращается, если распакованный запрос превышает MaxDecodedSize.
+	ErrTooLarge = errors.New("remote write request is too large")
+)
+
+// DefaultCounterSuffixes суффиксы имен, по которым серии считаются счетчиками.
+var DefaultCounterSuffixes = []string{"_total"}
+
+// Decode читает тело запроса, распаковывает snappy и разбирает WriteRequest.
+// Размер тела ограничивает вызывающий, размер распакованных данных - MaxDecodedSize.
+func Decode(r io.Reader) (*pb.WriteRequest, error) {
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("remotewrite/decode: read body: %w", err)
+	}
+
+	n, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("remotewrite/decode: snappy: %v: %w", err, ErrInvalidRequest)
+	}
+	if n > MaxDecodedSize {
+		return nil, fmt.Errorf("remotewrite/decode: decoded size %d: %w", n, ErrTooLarge)
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("remotewrite/decode: snappy: %v: %w", err, ErrInvalidRequest)
+	}
+
+	var req pb.WriteRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("remotewrite/decode: unmarshal: %v: %w", err, ErrInvalidRequest)
+	}
+	return &req, nil
+}
+
+// Batch результат преобразования WriteRequest.
+type Batch struct {
+	Metrics []model.MetricDto
+
+	counters *cumulative.Batch
+}
+
+// Converter преобразует серии remote_write в метрики.
+//
+// Серии становятся gauge, если их имя не оканчивается на один из суффиксов счетчиков
+// и в метаданных запроса они не помечены как COUNTER.
+// Для счетчиков сохраняется прирост накопленного значения, см. пакет cumulative.
+// Сохраненный пакет завершается вызовом Commit,
+// прирост несохраненного пакета возвращается вызовом Release.
+type Converter struct {
+	suffixes []string
+	counters *cumulative.Tracker
+}
+
+// NewConverter создает Converter с переданными суффиксами имен счетчиков.
+func NewConverter(counterSuffixes []string) *Converter {
+	return &Converter{
+		suffixes: slices.Clone(counterSuffixes),
+		counters: cumulative.NewTracker(),
+	}
+}
+
+// Convert преобразует WriteRequest в пакет метрик.
+// Текущие значения впервые встреченных счетчиков запрашиваются у g.
+func (c *Converter) Convert(ctx context.Context, req *pb.WriteRequest, g cumulative.Getter) (*Batch, error) {
+	counters := make(map[string]bool)
+	for _, md := range req.GetMetadata() {
+		if md.GetType() == pb.MetricMetadata_COUNTER {
+			counters[md.GetMetricFamilyName()] = true
+		}
+	}
+
+	batch := &Batch{
+		Metrics:  make([]model.MetricDto, 0, len(req.GetTimeseries())),
+		counters: c.counters.Begin(g),
+	}
+	for _, ts := range req.GetTimeseries() {
+		metric, err := seriesMetric(ts)
+		if err != nil {
+			c.Release(batch)
+			return nil, fmt.Errorf("remotewrite/convert: %w", err)
+		}
+
+		samples := validSamples(ts.GetSamples())
+		if len(samples) == 0 {
+			continue
+		}
+
+		if !counters[metric.Name] && !c.isCounter(metric.Name) {
+			metric.Value = model.MetricValue{Type: model.TypeGauge, Gauge: samples[len(samples)-1].GetValue()}
+			batch.Metrics = append(batch.Metrics, metric)
+			continue
+		}
+
+		metric.Value.Type = model.TypeCounter
+		var delta int64
+		for _, s := range samples {
+			delta += batch.counters.Delta(ctx, metric, s.GetValue())
+		}
+
+		if delta == 0 {
+			continue
+		}
+		metric.Value.Counter = delta
+		batch.Metrics = append(batch.Metrics, metric)
+	}
+	return batch, nil
+}
+
+// Commit завершает сохраненный пакет.
+func (c *Converter) Commit(b *Batch) {
+	c.counters.Commit(b.counters)
+}
+
+// Release возвращает прирост счетчиков несохраненного пакета,
+// он будет учтен следующим значением серии. После Commit ничего не делает.
+func (c *Converter) Release(b *Batch) {
+	c.counters.Release(b.counters)
+}
+
+// isCounter сообщает, оканчивается ли имя на один из суффиксов счетчиков.
+func (c *Converter) isCounter(name string) bool {
+	for _, suffix := range c.suffixes {
+		if suffix != "" && strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// seriesMetric возвращает метрику с именем и метками серии.
+func seriesMetric(ts *pb.TimeSeries) (model.MetricDto, error) {
+	var metric model.MetricDto
+	for _, l := range ts.GetLabels() {
+		if l.GetName() == nameLabel {
+			metric.Name = l.GetValue()
+			continue
+		}
+		if metric.Labels == nil {
+			metric.Labels = make(model.Labels, len(ts.GetLabels()))
+		}
+		metric.Labels[l.GetName()] = l.GetValue()
+	}
+
+	if metric.Name == "" {
+		return model.MetricDto{}, fmt.Errorf("series without %s label: %w", nameLabel, ErrInvalidRequest)
+	}
+	if err := model.ValidateName(metric.Name); err != nil {
+		return model.MetricDto{}, fmt.Errorf("series: %v: %w", err, ErrInvalidRequest)
+	}
+	if err := metric.Labels.Validate(); err != nil {
+		return model.MetricDto{}, fmt.Errorf("series %s: %w", metric.Name, err)
+	}
+	return metric, nil
+}
+
+// validSamples возвращает значения, упорядоченные по времени, без отметок устаревания.
+func validSamples(samples []*pb.Sample) []*pb.Sample {
+	result := make([]*pb.Sample, 0, len(samples))
+	for _, s := range samples {
+		if math.Float64bits(s.GetValue()) == staleNaN {
+			continue
+		}
+		result = append(result, s)
+	}
+	slices.SortStableFunc(result, func(a, b *pb.Sample) int {
+		return cmp.Compare(a.GetTimestamp(), b.GetTimestamp())
+	})
+	return result
+}
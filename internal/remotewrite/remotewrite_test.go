@@ -0,0 +1,195 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/htrandev/metrics/internal/cumulative"
+	"github.com/htrandev/metrics/internal/model"
+	pb "github.com/htrandev/metrics/internal/proto"
+	"github.com/htrandev/metrics/internal/repository"
+)
+
+type mockGetter map[string]model.MetricDto
+
+func (g mockGetter) Get(_ context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error) {
+	for _, m := range g {
+		if m.Name == name && model.MatchLabels(m.Labels, matchers) {
+			return m, nil
+		}
+	}
+	return model.MetricDto{}, repository.ErrNotFound
+}
+
+func series(name string, labels map[string]string, values ...float64) *pb.TimeSeries {
+	pl := []*pb.Label{pb.Label_builder{Name: nameLabel, Value: name}.Build()}
+	for k, v := range labels {
+		pl = append(pl, pb.Label_builder{Name: k, Value: v}.Build())
+	}
+	samples := make([]*pb.Sample, 0, len(values))
+	for i, v := range values {
+		samples = append(samples, pb.Sample_builder{Value: v, Timestamp: int64(i) * 1000}.Build())
+	}
+	return pb.TimeSeries_builder{Labels: pl, Samples: samples}.Build()
+}
+
+func writeRequest(ts ...*pb.TimeSeries) *pb.WriteRequest {
+	return pb.WriteRequest_builder{Timeseries: ts}.Build()
+}
+
+func TestDecode(t *testing.T) {
+	req := writeRequest(series("up", map[string]string{"job": "node"}, 1))
+	data, err := proto.Marshal(req)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := Decode(bytes.NewReader(snappy.Encode(nil, data)))
+		require.NoError(t, err)
+		require.True(t, proto.Equal(req, got))
+	})
+
+	t.Run("not snappy", func(t *testing.T) {
+		_, err := Decode(bytes.NewReader([]byte("not snappy")))
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("not protobuf", func(t *testing.T) {
+		_, err := Decode(bytes.NewReader(snappy.Encode(nil, []byte{0xff, 0xff})))
+		require.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		// заголовок snappy объявляет размер больше MaxDecodedSize
+		header := binary.AppendUvarint(nil, MaxDecodedSize+1)
+		_, err := Decode(bytes.NewReader(header))
+		require.ErrorIs(t, err, ErrTooLarge)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+
+	labeled := func(m model.MetricDto, labels model.Labels) model.MetricDto {
+		m.Labels = labels
+		return m
+	}
+
+	testCases := []struct {
+		name     string
+		getter   cumulative.Getter
+		requests []*pb.WriteRequest
+		expected [][]model.MetricDto
+		wantErr  bool
+	}{
+		{
+			name: "gauge takes last value",
+			requests: []*pb.WriteRequest{
+				writeRequest(series("node_load1", map[string]string{"instance": "a"}, 0.5, 0.7)),
+			},
+			expected: [][]model.MetricDto{
+				{labeled(model.Gauge("node_load1", 0.7), model.Labels{"instance": "a"})},
+			},
+		},
+		{
+			name: "counter by suffix stores increments",
+			requests: []*pb.WriteRequest{
+				writeRequest(series("http_requests_total", nil, 10, 12.5)),
+				writeRequest(series("http_requests_total", nil, 15)),
+				writeRequest(series("http_requests_total", nil, 15)),
+				writeRequest(series("http_requests_total", nil, 3)),
+			},
+			expected: [][]model.MetricDto{
+				{model.Counter("http_requests_total", 12)},
+				{model.Counter("http_requests_total", 3)},
+				{},
+				{model.Counter("http_requests_total", 3)},
+			},
+		},
+		{
+			name: "counter by metadata",
+			requests: []*pb.WriteRequest{
+				pb.WriteRequest_builder{
+					Timeseries: []*pb.TimeSeries{series("errors", nil, 2)},
+					Metadata: []*pb.MetricMetadata{pb.MetricMetadata_builder{
+						Type:             pb.MetricMetadata_COUNTER,
+						MetricFamilyName: "errors",
+					}.Build()},
+				}.Build(),
+			},
+			expected: [][]model.MetricDto{
+				{model.Counter("errors", 2)},
+			},
+		},
+		{
+			name:   "counter continues from stored value",
+			getter: mockGetter{"jobs_total": model.Counter("jobs_total", 7)},
+			requests: []*pb.WriteRequest{
+				writeRequest(series("jobs_total", nil, 10)),
+			},
+			expected: [][]model.MetricDto{
+				{model.Counter("jobs_total", 3)},
+			},
+		},
+		{
+			name: "stale markers are skipped",
+			requests: []*pb.WriteRequest{
+				writeRequest(series("temperature", nil, 20, math.Float64frombits(staleNaN))),
+			},
+			expected: [][]model.MetricDto{
+				{model.Gauge("temperature", 20)},
+			},
+		},
+		{
+			name: "series without name",
+			requests: []*pb.WriteRequest{
+				writeRequest(pb.TimeSeries_builder{
+					Samples: []*pb.Sample{pb.Sample_builder{Value: 1}.Build()},
+				}.Build()),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid name",
+			requests: []*pb.WriteRequest{
+				writeRequest(series(`temperature{room="a"}`, nil, 20)),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewConverter(DefaultCounterSuffixes)
+			for i, req := range tc.requests {
+				batch, err := c.Convert(ctx, req, tc.getter)
+				if tc.wantErr {
+					require.ErrorIs(t, err, ErrInvalidRequest)
+					return
+				}
+				require.NoError(t, err)
+				require.ElementsMatch(t, tc.expected[i], batch.Metrics)
+				c.Commit(batch)
+			}
+		})
+	}
+}
+
+func TestConvertWithoutCommit(t *testing.T) {
+	ctx := context.Background()
+	c := NewConverter(DefaultCounterSuffixes)
+
+	req := writeRequest(series("jobs_total", nil, 5))
+	for range 2 {
+		batch, err := c.Convert(ctx, req, nil)
+		require.NoError(t, err)
+		require.Equal(t, []model.MetricDto{model.Counter("jobs_total", 5)}, batch.Metrics)
+		c.Release(batch)
+	}
+}
@@ -5,4 +5,6 @@ import "errors"
 var (
 	// ErrNotFound возвращается когда метрика не найдена в хранилище.
 	ErrNotFound = errors.New("not found in storage")
+	// ErrNotSupported возвращается когда хранилище не поддерживает операцию.
+	ErrNotSupported = errors.New("not supported by storage")
 )
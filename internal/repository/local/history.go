@@ -0,0 +1,95 @@
+package local
+
+import (
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ring кольцевой буфер последних значений серии.
+// При limit > 0 хранит не более limit точек, вытесняя самые старые.
+// При retention > 0 хранит только точки не старше retention относительно последней.
+type ring struct {
+	buf  []model.Point
+	head int
+	size int
+
+	limit     int
+	retention time.Duration
+}
+
+func newRing(limit int, retention time.Duration) *ring {
+	return &ring{limit: limit, retention: retention}
+}
+
+// push добавляет точку в конец буфера.
+func (r *ring) push(p model.Point) {
+	switch {
+	case r.limit > 0 && r.size == r.limit:
+		r.buf[r.head] = p
+		r.head = (r.head + 1) % len(r.buf)
+	case r.size == len(r.buf):
+		r.grow()
+		fallthrough
+	default:
+		r.buf[(r.head+r.size)%len(r.buf)] = p
+		r.size++
+	}
+
+	if r.retention > 0 {
+		r.evictBefore(p.Timestamp.Add(-r.retention))
+	}
+}
+
+// grow увеличивает емкость буфера, сохраняя порядок точек.
+func (r *ring) grow() {
+	n := max(2*len(r.buf), 8)
+	if r.limit > 0 {
+		n = min(n, r.limit)
+	}
+	buf := make([]model.Point, n)
+	r.copyTo(buf)
+	r.buf = buf
+	r.head = 0
+}
+
+// evictBefore удаляет точки старше ts.
+func (r *ring) evictBefore(ts time.Time) {
+	for r.size > 0 && r.buf[r.head].Timestamp.Before(ts) {
+		r.buf[r.head] = model.Point{}
+		r.head = (r.head + 1) % len(r.buf)
+		r.size--
+	}
+}
+
+// copyTo копирует точки в dst в порядке добавления.
+func (r *ring) copyTo(dst []model.Point) int {
+	if r.size == 0 {
+		return 0
+	}
+	n := copy(dst, r.buf[r.head:min(r.head+r.size, len(r.buf))])
+	if n < r.size {
+		n += copy(dst[n:], r.buf[:r.size-n])
+	}
+	return n
+}
+
+// points возвращает копию точек в порядке добавления.
+func (r *ring) points() []model.Point {
+	points := make([]model.Point, r.size)
+	r.copyTo(points)
+	return points
+}
+
+// between возвращает копию точек из интервала [from, to].
+func (r *ring) between(from, to time.Time) []model.Point {
+	points := make([]model.Point, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		p := r.buf[(r.head+i)%len(r.buf)]
+		if p.Timestamp.Before(from) || p.Timestamp.After(to) {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
@@ -2,11 +2,13 @@ package local
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"sync"
 	"time"
@@ -24,11 +26,39 @@ type StorageOptions struct {
 	Interval time.Duration
 	Logger   *zap.Logger
 	MaxRetry int
+
+	// HistorySize максимальное количество точек истории на серию.
+	HistorySize int
+	// HistoryRetention максимальная глубина истории серии.
+	// Если HistorySize и HistoryRetention равны нулю, история не хранится.
+	HistoryRetention time.Duration
+
+	// BatchTTL время, в течение которого хранилище помнит идентификаторы
+	// сохраненных пакетов. По умолчанию DefaultBatchTTL.
+	BatchTTL time.Duration
 }
 
+// DefaultBatchTTL время хранения идентификаторов пакетов по умолчанию.
+const DefaultBatchTTL = time.Hour
+
+const (
+	// fileMode права файла метрик.
+	fileMode = 0664
+	// maxRecordSize максимальный размер строки файла метрик.
+	// Строка содержит всю историю серии, поэтому может превышать размер буфера bufio.Scanner по умолчанию.
+	maxRecordSize = 64 << 20
+)
+
 // MemStorage реализует in-memory хранилище метрик.
+// Серии идентифицируются именем метрики и каноническим набором меток.
+// Для каждой серии может храниться ограниченная история значений.
 type MemStorage struct {
 	metrics map[string]model.MetricDto
+	history map[string]*ring
+
+	// batches идентификаторы сохраненных пакетов и время их истечения.
+	batches   map[string]time.Time
+	lastSweep time.Time
 
 	file    *os.File
 	scanner *bufio.Scanner
@@ -67,17 +97,24 @@ func NewRestore(opts *StorageOptions) (*MemStorage, error) {
 
 func new(flag int, opts *StorageOptions) (*MemStorage, error) {
 	metrics := make(map[string]model.MetricDto)
-	f, err := os.OpenFile(opts.FileName, flag, 0664)
+	f, err := os.OpenFile(opts.FileName, flag, fileMode)
 	if err != nil {
 		return nil, fmt.Errorf("restore: open file: %w", err)
 	}
 	if opts.MaxRetry == 0 {
 		opts.MaxRetry = 3
 	}
+	if opts.BatchTTL <= 0 {
+		opts.BatchTTL = DefaultBatchTTL
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxRecordSize)
 	storage := &MemStorage{
 		metrics: metrics,
+		history: make(map[string]*ring),
+		batches: make(map[string]time.Time),
 		file:    f,
-		scanner: bufio.NewScanner(f),
+		scanner: scanner,
 		opts:    opts,
 	}
 
@@ -95,14 +132,22 @@ func (m *MemStorage) Ping(ctx context.Context) error {
 	return nil
 }
 
-// Set записывает значение метрики.
+// Set записывает значение метрики вместе с переданным временем обновления.
+// Если время не передано, используется текущее.
 // Если метрика уже существует, то ничего не делает.
 func (m *MemStorage) Set(Ctx context.Context, request *model.MetricDto) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, ok := m.metrics[request.Name]; !ok {
-		m.metrics[request.Name] = *request
+	key := request.Key()
+	if _, ok := m.metrics[key]; !ok {
+		metric := *request
+		metric.Labels = request.Labels.Clone()
+		metric.Value.Histogram = request.Value.Histogram.Clone()
+		if metric.UpdatedAt.IsZero() {
+			metric.UpdatedAt = time.Now().UTC()
+		}
+		m.metrics[key] = metric
 	}
 
 	return nil
@@ -111,13 +156,32 @@ func (m *MemStorage) Set(Ctx context.Context, request *model.MetricDto) error {
 // Store записывает новое значение метрики.
 // Если метрика существует, то обновляет ее значение.
 func (m *MemStorage) Store(ctx context.Context, request *model.MetricDto) error {
+	if err := request.Validate(); err != nil {
+		return fmt.Errorf("repository/store: %w", err)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	metric, ok := m.metrics[request.Name]
+	m.store(request)
+	return nil
+}
+
+// store записывает провалидированное значение метрики и время обновления серии.
+// Вызывается под блокировкой mu.
+func (m *MemStorage) store(request *model.MetricDto) {
+	now := time.Now().UTC()
+
+	key := request.Key()
+	metric, ok := m.metrics[key]
 	if !ok {
-		m.metrics[request.Name] = *request
-		return nil
+		metric = *request
+		metric.Labels = request.Labels.Clone()
+		metric.Value.Histogram = request.Value.Histogram.Clone()
+		metric.UpdatedAt = now
+		m.metrics[key] = metric
+		m.appendSample(key, metric.Value, now)
+		return
 	}
 
 	switch request.Value.Type {
@@ -125,10 +189,36 @@ func (m *MemStorage) Store(ctx context.Context, request *model.MetricDto) error
 		metric.Value.Gauge = request.Value.Gauge
 	case model.TypeCounter:
 		metric.Value.Counter += request.Value.Counter
+	case model.TypeHistogram:
+		if metric.Value.Histogram == nil {
+			metric.Value.Histogram = request.Value.Histogram.Clone()
+			break
+		}
+		metric.Value.Histogram.Merge(request.Value.Histogram)
 	}
 
-	m.metrics[request.Name] = metric
-	return nil
+	metric.UpdatedAt = now
+	m.metrics[key] = metric
+	m.appendSample(key, metric.Value, now)
+}
+
+// historyEnabled сообщает, хранится ли история серий.
+func (m *MemStorage) historyEnabled() bool {
+	return m.opts.HistorySize > 0 || m.opts.HistoryRetention > 0
+}
+
+// appendSample добавляет значение серии на момент ts в ее историю.
+// Вызывается под блокировкой mu.
+func (m *MemStorage) appendSample(key string, value model.MetricValue, ts time.Time) {
+	if !m.historyEnabled() {
+		return
+	}
+	h, ok := m.history[key]
+	if !ok {
+		h = newRing(m.opts.HistorySize, m.opts.HistoryRetention)
+		m.history[key] = h
+	}
+	h.push(model.Point{Timestamp: ts, Value: value.SampleValue()})
 }
 
 // StoreMany записывает новое значение метрик.
@@ -138,25 +228,35 @@ func (m *MemStorage) StoreMany(ctx context.Context, metrics []model.MetricDto) e
 		log.Println("repository/storeMany: request is nil")
 		return nil
 	}
+	// батч проверяется целиком до записи, чтобы не применить его частично
 	errs := make([]error, 0, len(metrics))
 	for _, metric := range metrics {
-		if err := m.Store(ctx, &metric); err != nil {
-			err = fmt.Errorf("repository/storeMany: store [%+v]: %w", metric, err)
-			errs = append(errs, err)
-			continue
+		if err := metric.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("repository/storeMany: %w", err))
 		}
 	}
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, metric := range metrics {
+		m.store(&metric)
+	}
 	return nil
 }
 
 // StoreManyWithRetry записывает новое значение метрик.
 // Если метрика существует, то обновляет ее значение.
-// При ошибке пытается записать еще maxRetry раз
+// При ошибке пытается записать еще maxRetry раз.
+// Невалидный батч не повторяется.
 func (m *MemStorage) StoreManyWithRetry(ctx context.Context, metrics []model.MetricDto) error {
 	err := m.StoreMany(ctx, metrics)
+	if errors.Is(err, model.ErrInvalidMetric) {
+		return fmt.Errorf("repository/storeManyWithRetry: unretriable: %w", err)
+	}
 	if err != nil {
 		for i := 0; i < m.opts.MaxRetry; i++ {
 			if err := m.StoreMany(ctx, metrics); err != nil {
@@ -169,92 +269,217 @@ func (m *MemStorage) StoreManyWithRetry(ctx context.Context, metrics []model.Met
 	return nil
 }
 
+// StoreBatch атомарно записывает пакет метрик с идентификатором batchID.
+// Если пакет с таким идентификатором уже был записан в течение BatchTTL,
+// метрики повторно не применяются и возвращается false.
+// Если хотя бы одна метрика пакета невалидна, пакет не записывается целиком.
+func (m *MemStorage) StoreBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error) {
+	for _, metric := range metrics {
+		if err := metric.Validate(); err != nil {
+			return false, fmt.Errorf("repository/storeBatch: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweepBatches(now)
+	if expires, ok := m.batches[batchID]; ok && now.Before(expires) {
+		return false, nil
+	}
+
+	for _, metric := range metrics {
+		m.store(&metric)
+	}
+	m.batches[batchID] = now.Add(m.opts.BatchTTL)
+	return true, nil
+}
+
+// sweepBatches удаляет истекшие идентификаторы пакетов не чаще раза в BatchTTL.
+// Вызывается под блокировкой mu.
+func (m *MemStorage) sweepBatches(now time.Time) {
+	if now.Sub(m.lastSweep) < m.opts.BatchTTL {
+		return
+	}
+	for id, expires := range m.batches {
+		if !now.Before(expires) {
+			delete(m.batches, id)
+		}
+	}
+	m.lastSweep = now
+}
+
 // Get возвращает метрику по имени.
-func (m *MemStorage) Get(ctx context.Context, name string) (model.MetricDto, error) {
+// Если переданы матчеры, то возвращает первую в каноническом порядке серию,
+// метки которой им удовлетворяют.
+func (m *MemStorage) Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	metric, ok := m.metrics[name]
+	// серия без меток идет первой в каноническом порядке
+	if metric, ok := m.metrics[name]; ok && model.MatchLabels(metric.Labels, matchers) {
+		return copyMetric(metric), nil
+	}
+
+	var (
+		found model.MetricDto
+		ok    bool
+	)
+	for key, metric := range m.metrics {
+		if metric.Name != name || !model.MatchLabels(metric.Labels, matchers) {
+			continue
+		}
+		if !ok || key < found.Key() {
+			found, ok = metric, true
+		}
+	}
 	if !ok {
 		return model.MetricDto{}, fmt.Errorf("repository/get: metric with name [%s]: %w", name, repository.ErrNotFound)
 	}
-	return metric, nil
+	return copyMetric(found), nil
 }
 
-// GetAll возвращает все метрики.
-func (m *MemStorage) GetAll(ctx context.Context) ([]model.MetricDto, error) {
-	metrics := make([]model.MetricDto, 0, len(m.metrics))
-
+// GetAll возвращает все метрики, метки которых удовлетворяют матчерам.
+func (m *MemStorage) GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	metrics := make([]model.MetricDto, 0, len(m.metrics))
 	for _, metric := range m.metrics {
-		metrics = append(metrics, metric)
+		if !model.MatchLabels(metric.Labels, matchers) {
+			continue
+		}
+		metrics = append(metrics, copyMetric(metric))
 	}
 
 	sort.Slice(metrics, func(i, j int) bool {
-		return metrics[i].Name < metrics[j].Name
+		return metrics[i].Key() < metrics[j].Key()
 	})
 	return metrics, nil
 }
 
-// flush асинхронно сохраняет все метрики в файл каждые Interval секунд.
+// Range возвращает историю значений серий метрики name за интервал [from, to],
+// прореженную с шагом step.
+// Если хранение истории отключено, возвращает repository.ErrNotSupported.
+func (m *MemStorage) Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...model.Matcher) ([]model.Series, error) {
+	if !m.historyEnabled() {
+		return nil, fmt.Errorf("repository/range: history disabled: %w", repository.ErrNotSupported)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]model.Series, 0)
+	for key, h := range m.history {
+		metric, ok := m.metrics[key]
+		if !ok || metric.Name != name || !model.MatchLabels(metric.Labels, matchers) {
+			continue
+		}
+		points := h.between(from, to)
+		if len(points) == 0 {
+			continue
+		}
+		result = append(result, model.Series{
+			Name:   metric.Name,
+			Type:   metric.Value.Type.String(),
+			Labels: metric.Labels.Clone(),
+			Points: model.Downsample(points, from, step),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Labels.String() < result[j].Labels.String()
+	})
+	return result, nil
+}
+
+// copyMetric возвращает копию метрики, не разделяющую память с хранилищем.
+func copyMetric(metric model.MetricDto) model.MetricDto {
+	metric.Labels = metric.Labels.Clone()
+	metric.Value.Histogram = metric.Value.Histogram.Clone()
+	return metric
+}
+
+// flush асинхронно сохраняет все метрики вместе с их историей в файл каждые Interval секунд.
 func (m *MemStorage) flush(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for range ticker.C {
-		m.mu.Lock()
-
-		for _, metric := range m.metrics {
-			data, err := easyjson.Marshal(metric)
-			if err != nil {
-				m.opts.Logger.Error(
-					"marshal metric",
-					zap.Any("metric", metric),
-					zap.Error(err),
-					zap.String("scope", "memstorage/flush"),
-				)
-			}
-			if _, err := m.file.Write(data); err != nil {
-				m.opts.Logger.Error(
-					"write data",
-					zap.String("data", string(data)),
-					zap.Error(err),
-					zap.String("scope", "memstorage/flush"),
-				)
-			}
+		if err := m.save(); err != nil {
+			m.opts.Logger.Error(
+				"save metrics",
+				zap.Error(err),
+				zap.String("scope", "memstorage/flush"),
+			)
+		}
+	}
+}
 
-			if _, err := m.file.Write([]byte("\n")); err != nil {
-				m.opts.Logger.Error(
-					"write new line",
-					zap.Error(err),
-					zap.String("scope", "memstorage/flush"),
-				)
-			}
+// save атомарно перезаписывает файл текущим состоянием всех метрик:
+// снимок пишется во временный файл, который затем переименовывается.
+func (m *MemStorage) save() error {
+	var buf bytes.Buffer
+
+	m.mu.RLock()
+	for key, metric := range m.metrics {
+		rec := record{MetricDto: metric}
+		if h, ok := m.history[key]; ok {
+			rec.History = h.points()
 		}
-		m.mu.Unlock()
+		data, err := easyjson.Marshal(rec)
+		if err != nil {
+			m.mu.RUnlock()
+			return fmt.Errorf("marshal metric [%s]: %w", key, err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	m.mu.RUnlock()
+
+	name := m.opts.FileName
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := tmp.Chmod(fileMode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("rename: %w", err)
 	}
+	return nil
 }
 
-// restore восстанавливает метрики из JSONL файла при запуске.
+// restore восстанавливает метрики и их историю из JSONL файла при запуске.
 func (m *MemStorage) restore() error {
-	ctx := context.Background()
 	// читаем из файла пока не дойдем до конца
 	for m.scanner.Scan() {
 		data := m.scanner.Bytes()
 
-		var metric model.MetricDto
-		if err := easyjson.Unmarshal(data, &metric); err != nil {
+		var rec record
+		if err := easyjson.Unmarshal(data, &rec); err != nil {
 			m.opts.Logger.Error("can't unmarshal data from file", zap.Error(err), zap.String("scope", "restore"))
 			continue
 		}
 
-		m.opts.Logger.Debug("set metric", zap.Any("metric", metric))
-		if err := m.Set(ctx, &metric); err != nil {
-			m.opts.Logger.Error("can't set metric", zap.Error(err), zap.Any("metric", m), zap.String("scope", "restore"))
-			continue
-		}
+		m.opts.Logger.Debug("restore metric", zap.Any("metric", rec.MetricDto))
+		m.restoreRecord(rec)
 	}
 
 	// проверяем наличие ошибки
@@ -264,6 +489,33 @@ func (m *MemStorage) restore() error {
 	return nil
 }
 
+// restoreRecord восстанавливает значение и историю серии.
+// Файлы старого формата дописывались при каждом сохранении, поэтому более поздняя
+// запись серии содержит более свежее состояние и целиком заменяет ранее восстановленную.
+// Если время обновления не сохранено, используется текущее.
+func (m *MemStorage) restoreRecord(rec record) {
+	metric := copyMetric(rec.MetricDto)
+	if metric.UpdatedAt.IsZero() {
+		metric.UpdatedAt = time.Now().UTC()
+	}
+	key := metric.Key()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics[key] = metric
+	if !m.historyEnabled() || len(rec.History) == 0 {
+		delete(m.history, key)
+		return
+	}
+
+	h := newRing(m.opts.HistorySize, m.opts.HistoryRetention)
+	for _, p := range rec.History {
+		h.push(p)
+	}
+	m.history[key] = h
+}
+
 // Close закрывает файл.
 func (m *MemStorage) Close() error {
 	return m.file.Close()
@@ -1,14 +1,18 @@
 package local
 
 import (
+	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/htrandev/metrics/internal/model"
+	"github.com/htrandev/metrics/internal/repository"
 )
 
 var tempLogFileName = "tempTest.log"
@@ -84,6 +88,44 @@ func TestStore(t *testing.T) {
 				Value: model.MetricValue{Type: model.TypeCounter, Counter: 3},
 			},
 		},
+		{
+			name: "filled mem storage histogram",
+			storage: func() *MemStorage {
+				s := filledMemStorage(t)
+				err := s.Store(context.Background(), &model.MetricDto{
+					Name: "histogram",
+					Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+						Bounds: []float64{0.5, 1}, Counts: []uint64{1, 0, 0}, Sum: 0.25, Count: 1,
+					}},
+				})
+				require.NoError(t, err)
+				return s
+			}(),
+			req: &model.MetricDto{
+				Name: "histogram",
+				Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+					Bounds: []float64{0.5, 1}, Counts: []uint64{0, 2, 1}, Sum: 3.5, Count: 3,
+				}},
+			},
+			wantErr: false,
+			expectedValue: model.MetricDto{
+				Name: "histogram",
+				Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+					Bounds: []float64{0.5, 1}, Counts: []uint64{1, 2, 1}, Sum: 3.75, Count: 4,
+				}},
+			},
+		},
+		{
+			name:    "invalid histogram",
+			storage: emptyMemstorage,
+			req: &model.MetricDto{
+				Name: "histogram",
+				Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+					Bounds: []float64{1, 0.5}, Counts: []uint64{0, 0, 0},
+				}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -98,6 +140,7 @@ func TestStore(t *testing.T) {
 			if tc.expectedValue.Name != "" {
 				actValue, err = tc.storage.Get(context.Background(), tc.expectedValue.Name)
 				require.NoError(t, err)
+				actValue = withoutUpdatedAt(t, actValue)
 			}
 
 			require.NoError(t, err)
@@ -164,12 +207,39 @@ func TestStoreMany(t *testing.T) {
 				res, err := tc.storage.GetAll(ctx)
 				require.NoError(t, err)
 
-				require.Equal(t, tc.expectedValue, res)
+				require.Equal(t, tc.expectedValue, allWithoutUpdatedAt(t, res))
 			}
 		})
 	}
 }
 
+func TestStoreManyInvalid(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewRepository(&StorageOptions{
+		FileName: filepath.Join(t.TempDir(), "metrics.json"),
+		Logger:   zap.NewNop(),
+		MaxRetry: 3,
+	})
+	require.NoError(t, err)
+
+	invalid := []model.MetricDto{
+		model.Counter("counter", 1),
+		{Name: "hist", Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+			Bounds: []float64{1},
+			Counts: []uint64{1},
+		}}},
+	}
+
+	// батч не применяется частично и не повторяется
+	err = s.StoreManyWithRetry(ctx, invalid)
+	require.ErrorIs(t, err, model.ErrInvalidMetric)
+	require.ErrorIs(t, err, model.ErrInvalidHistogram)
+
+	_, err = s.Get(ctx, "counter")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+}
+
 func TestGet(t *testing.T) {
 	emptyMemstorage, err := NewRepository(&StorageOptions{
 		FileName: tempLogFileName,
@@ -219,7 +289,7 @@ func TestGet(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
-			require.EqualValues(t, tc.expectedMetric, m)
+			require.EqualValues(t, tc.expectedMetric, withoutUpdatedAt(t, m))
 		})
 	}
 }
@@ -273,7 +343,7 @@ func TestGetAll(t *testing.T) {
 				return
 			}
 			require.NoError(t, err)
-			require.EqualValues(t, tc.expectedResult, m)
+			require.EqualValues(t, tc.expectedResult, allWithoutUpdatedAt(t, m))
 		})
 	}
 }
@@ -376,7 +446,7 @@ func TestSet(t *testing.T) {
 			metrics, err := tc.storage.GetAll(ctx)
 			require.NoError(t, err)
 
-			require.Equal(t, tc.expetedMetrics, metrics)
+			require.Equal(t, tc.expetedMetrics, allWithoutUpdatedAt(t, metrics))
 		})
 	}
 }
@@ -406,3 +476,368 @@ func filledMemStorage(t *testing.T) *MemStorage {
 	}
 	return memstorage
 }
+
+func TestLabels(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewRepository(&StorageOptions{
+		FileName: tempLogFileName,
+		Logger:   zap.NewNop(),
+	})
+	require.NoError(t, err)
+
+	defer func() {
+		err := os.Remove(tempLogFileName)
+		require.NoError(t, err)
+	}()
+
+	web1 := model.Counter("requests", 1)
+	web1.Labels = model.Labels{"host": "web-1"}
+	web2 := model.Counter("requests", 5)
+	web2.Labels = model.Labels{"host": "web-2"}
+
+	require.NoError(t, s.StoreMany(ctx, []model.MetricDto{web1, web2, web1}))
+
+	t.Run("series are separated by labels", func(t *testing.T) {
+		metrics, err := s.GetAll(ctx)
+		require.NoError(t, err)
+
+		expected1 := model.Counter("requests", 2)
+		expected1.Labels = model.Labels{"host": "web-1"}
+		require.Equal(t, []model.MetricDto{expected1, web2}, allWithoutUpdatedAt(t, metrics))
+	})
+
+	t.Run("get all with matcher", func(t *testing.T) {
+		m, err := model.ParseMatcher(`host=~"web-2|web-3"`)
+		require.NoError(t, err)
+
+		metrics, err := s.GetAll(ctx, m)
+		require.NoError(t, err)
+		require.Equal(t, []model.MetricDto{web2}, allWithoutUpdatedAt(t, metrics))
+	})
+
+	t.Run("get with matcher", func(t *testing.T) {
+		m, err := model.ParseMatcher(`host="web-2"`)
+		require.NoError(t, err)
+
+		metric, err := s.Get(ctx, "requests", m)
+		require.NoError(t, err)
+		require.Equal(t, web2, withoutUpdatedAt(t, metric))
+	})
+
+	t.Run("get without matchers returns first series", func(t *testing.T) {
+		metric, err := s.Get(ctx, "requests")
+		require.NoError(t, err)
+		require.Equal(t, "web-1", metric.Labels["host"])
+	})
+
+	t.Run("get not matched", func(t *testing.T) {
+		m, err := model.ParseMatcher(`host="web-3"`)
+		require.NoError(t, err)
+
+		_, err = s.Get(ctx, "requests", m)
+		require.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+func TestRing(t *testing.T) {
+	start := time.Unix(1000, 0)
+	point := func(sec int) model.Point {
+		return model.Point{Timestamp: start.Add(time.Duration(sec) * time.Second), Value: float64(sec)}
+	}
+
+	testCases := []struct {
+		name      string
+		limit     int
+		retention time.Duration
+		pushed    int
+		expected  []model.Point
+	}{
+		{
+			name:     "unbounded by count",
+			limit:    0,
+			pushed:   10,
+			expected: []model.Point{point(0), point(1), point(2), point(3), point(4), point(5), point(6), point(7), point(8), point(9)},
+		},
+		{
+			name:     "count limit",
+			limit:    3,
+			pushed:   10,
+			expected: []model.Point{point(7), point(8), point(9)},
+		},
+		{
+			name:      "retention",
+			retention: 2 * time.Second,
+			pushed:    10,
+			expected:  []model.Point{point(7), point(8), point(9)},
+		},
+		{
+			name:      "count limit and retention",
+			limit:     2,
+			retention: time.Minute,
+			pushed:    10,
+			expected:  []model.Point{point(8), point(9)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newRing(tc.limit, tc.retention)
+			for i := range tc.pushed {
+				r.push(point(i))
+			}
+			require.Equal(t, tc.expected, r.points())
+			require.Equal(t, tc.expected[1:], r.between(tc.expected[1].Timestamp, point(tc.pushed).Timestamp))
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	ctx := context.Background()
+
+	defer func() {
+		err := os.Remove(tempLogFileName)
+		require.NoError(t, err)
+	}()
+
+	t.Run("history disabled", func(t *testing.T) {
+		s, err := NewRepository(&StorageOptions{
+			FileName: tempLogFileName,
+			Logger:   zap.NewNop(),
+		})
+		require.NoError(t, err)
+		defer s.Close()
+
+		_, err = s.Range(ctx, "gauge", time.Time{}, time.Now(), 0)
+		require.ErrorIs(t, err, repository.ErrNotSupported)
+	})
+
+	t.Run("history enabled", func(t *testing.T) {
+		s, err := NewRepository(&StorageOptions{
+			FileName:    tempLogFileName,
+			Logger:      zap.NewNop(),
+			HistorySize: 2,
+		})
+		require.NoError(t, err)
+		defer s.Close()
+
+		from := time.Now()
+		web := model.Counter("requests", 1)
+		web.Labels = model.Labels{"host": "web"}
+		for range 3 {
+			require.NoError(t, s.StoreMany(ctx, []model.MetricDto{model.Gauge("gauge", 0.1), web}))
+		}
+
+		series, err := s.Range(ctx, "requests", from, time.Now(), 0)
+		require.NoError(t, err)
+		require.Len(t, series, 1)
+		require.Equal(t, "counter", series[0].Type)
+		require.Equal(t, model.Labels{"host": "web"}, series[0].Labels)
+		require.Len(t, series[0].Points, 2)
+		require.Equal(t, 2.0, series[0].Points[0].Value)
+		require.Equal(t, 3.0, series[0].Points[1].Value)
+
+		m, err := model.ParseMatcher(`host="db"`)
+		require.NoError(t, err)
+		series, err = s.Range(ctx, "requests", from, time.Now(), 0, m)
+		require.NoError(t, err)
+		require.Empty(t, series)
+	})
+}
+
+func TestRestoreHistory(t *testing.T) {
+	ctx := context.Background()
+
+	defer func() {
+		err := os.Remove(tempLogFileName)
+		require.NoError(t, err)
+	}()
+
+	opts := func() *StorageOptions {
+		return &StorageOptions{
+			FileName:    tempLogFileName,
+			Logger:      zap.NewNop(),
+			HistorySize: 10,
+		}
+	}
+
+	s, err := NewRepository(opts())
+	require.NoError(t, err)
+
+	from := time.Now()
+	require.NoError(t, s.Store(ctx, &model.MetricDto{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.1}}))
+	require.NoError(t, s.Store(ctx, &model.MetricDto{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: 0.2}}))
+	stored, err := s.Get(ctx, "gauge")
+	require.NoError(t, err)
+	require.NoError(t, s.save())
+	require.NoError(t, s.Close())
+
+	restored, err := NewRestore(opts())
+	require.NoError(t, err)
+	defer restored.Close()
+
+	metric, err := restored.Get(ctx, "gauge")
+	require.NoError(t, err)
+	require.Equal(t, 0.2, metric.Value.Gauge)
+	require.True(t, stored.UpdatedAt.Equal(metric.UpdatedAt), "updated_at is not restored")
+
+	series, err := restored.Range(ctx, "gauge", from, time.Now(), 0)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Points, 2)
+	require.Equal(t, 0.1, series[0].Points[0].Value)
+	require.Equal(t, 0.2, series[0].Points[1].Value)
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	opts := &StorageOptions{
+		FileName:    filepath.Join(t.TempDir(), "metrics.json"),
+		Logger:      zap.NewNop(),
+		HistorySize: 5000,
+	}
+
+	s, err := NewRepository(opts)
+	require.NoError(t, err)
+	defer s.Close()
+
+	// история серии больше буфера bufio.Scanner по умолчанию
+	for i := range 5000 {
+		require.NoError(t, s.Store(ctx, &model.MetricDto{Name: "gauge", Value: model.MetricValue{Type: model.TypeGauge, Gauge: float64(i)}}))
+	}
+	// повторное сохранение перезаписывает файл, а не дописывает его
+	require.NoError(t, s.save())
+	require.NoError(t, s.save())
+
+	data, err := os.ReadFile(opts.FileName)
+	require.NoError(t, err)
+	require.Greater(t, len(data), 64*1024)
+	require.Equal(t, 1, bytes.Count(data, []byte("\n")))
+
+	restored, err := NewRestore(opts)
+	require.NoError(t, err)
+	defer restored.Close()
+
+	series, err := restored.Range(ctx, "gauge", time.Time{}, time.Now(), 0)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Points, 5000)
+}
+
+func TestRestoreLastWins(t *testing.T) {
+	ctx := context.Background()
+
+	name := filepath.Join(t.TempDir(), "metrics.json")
+	// файл старого формата дописывался при каждом сохранении
+	data := `{"name":"gauge","Value":{"type":1,"gauge":1},"history":[{"ts":"2026-10-17T00:00:00Z","value":1}]}
+{"name":"gauge","Value":{"type":1,"gauge":2},"history":[{"ts":"2026-10-17T00:00:00Z","value":1},{"ts":"2026-10-17T00:00:01Z","value":2}]}
+`
+	require.NoError(t, os.WriteFile(name, []byte(data), 0664))
+
+	s, err := NewRestore(&StorageOptions{FileName: name, Logger: zap.NewNop(), HistorySize: 10})
+	require.NoError(t, err)
+	defer s.Close()
+
+	metric, err := s.Get(ctx, "gauge")
+	require.NoError(t, err)
+	require.Equal(t, 2.0, metric.Value.Gauge)
+
+	series, err := s.Range(ctx, "gauge", time.Time{}, time.Now(), 0)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Points, 2)
+}
+
+func TestStoreBatch(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := NewRepository(&StorageOptions{
+		FileName: tempLogFileName,
+		Logger:   zap.NewNop(),
+		BatchTTL: time.Minute,
+	})
+	require.NoError(t, err)
+
+	defer func() {
+		err := os.Remove(tempLogFileName)
+		require.NoError(t, err)
+	}()
+
+	batch := []model.MetricDto{
+		model.Counter("counter", 2),
+		model.Gauge("gauge", 0.5),
+	}
+
+	t.Run("first delivery is applied", func(t *testing.T) {
+		applied, err := s.StoreBatch(ctx, "batch-1", batch)
+		require.NoError(t, err)
+		require.True(t, applied)
+	})
+
+	t.Run("duplicate is acknowledged without applying", func(t *testing.T) {
+		applied, err := s.StoreBatch(ctx, "batch-1", batch)
+		require.NoError(t, err)
+		require.False(t, applied)
+
+		metric, err := s.Get(ctx, "counter")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), metric.Value.Counter)
+	})
+
+	t.Run("other batch is applied", func(t *testing.T) {
+		applied, err := s.StoreBatch(ctx, "batch-2", batch)
+		require.NoError(t, err)
+		require.True(t, applied)
+
+		metric, err := s.Get(ctx, "counter")
+		require.NoError(t, err)
+		require.Equal(t, int64(4), metric.Value.Counter)
+	})
+
+	t.Run("invalid batch is not applied nor remembered", func(t *testing.T) {
+		invalid := []model.MetricDto{
+			model.Counter("counter", 1),
+			{Name: "hist", Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+				Bounds: []float64{1},
+				Counts: []uint64{1},
+			}}},
+		}
+		_, err := s.StoreBatch(ctx, "batch-3", invalid)
+		require.ErrorIs(t, err, model.ErrInvalidHistogram)
+
+		metric, err := s.Get(ctx, "counter")
+		require.NoError(t, err)
+		require.Equal(t, int64(4), metric.Value.Counter)
+		require.NotContains(t, s.batches, "batch-3")
+	})
+
+	t.Run("expired batch is applied again", func(t *testing.T) {
+		s.mu.Lock()
+		s.batches["batch-1"] = time.Now().Add(-time.Second)
+		s.mu.Unlock()
+
+		applied, err := s.StoreBatch(ctx, "batch-1", batch)
+		require.NoError(t, err)
+		require.True(t, applied)
+	})
+}
+
+// withoutUpdatedAt проверяет, что хранилище заполнило время обновления серии,
+// и сбрасывает его для сравнения значений.
+func withoutUpdatedAt(t *testing.T, metric model.MetricDto) model.MetricDto {
+	t.Helper()
+	require.False(t, metric.UpdatedAt.IsZero(), "updated_at of %s is not set", metric.Key())
+	metric.UpdatedAt = time.Time{}
+	return metric
+}
+
+// allWithoutUpdatedAt применяет withoutUpdatedAt к каждой серии.
+func allWithoutUpdatedAt(t *testing.T, metrics []model.MetricDto) []model.MetricDto {
+	t.Helper()
+	result := make([]model.MetricDto, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, withoutUpdatedAt(t, m))
+	}
+	return result
+}
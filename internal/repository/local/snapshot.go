@@ -0,0 +1,12 @@
+package local
+
+import "github.com/htrandev/metrics/internal/model"
+
+// record строка JSONL файла: метрика и ее история.
+// Строки без истории совпадают с форматом model.MetricDto.
+//
+//easyjson:json
+type record struct {
+	model.MetricDto
+	History []model.Point `json:"history,omitempty"`
+}
@@ -3,12 +3,16 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/htrandev/metrics/internal/model"
 	"github.com/htrandev/metrics/internal/repository"
@@ -18,17 +22,48 @@ import (
 type PostgresRepository struct {
 	db       *sql.DB
 	maxRetry int
+	types    *pgtype.Map
+
+	// partitions содержит дни, для которых уже созданы партиции metric_samples.
+	partitions sync.Map
+
+	// batchTTL время хранения идентификаторов сохраненных пакетов.
+	batchTTL time.Duration
+	// lastSweep время последней очистки истекших идентификаторов в наносекундах.
+	lastSweep atomic.Int64
+}
+
+// DefaultBatchTTL время хранения идентификаторов пакетов по умолчанию.
+const DefaultBatchTTL = time.Hour
+
+// Option настраивает PostgresRepository.
+type Option func(*PostgresRepository)
+
+// WithBatchTTL задает время, в течение которого хранилище помнит
+// идентификаторы сохраненных пакетов.
+func WithBatchTTL(ttl time.Duration) Option {
+	return func(r *PostgresRepository) {
+		if ttl > 0 {
+			r.batchTTL = ttl
+		}
+	}
 }
 
 // New возвращает новый экземпляр PostgresRepository.
-func New(db *sql.DB, maxRetry int) *PostgresRepository {
+func New(db *sql.DB, maxRetry int, opts ...Option) *PostgresRepository {
 	if maxRetry == 0 {
 		maxRetry = 3
 	}
-	return &PostgresRepository{
+	r := &PostgresRepository{
 		db:       db,
 		maxRetry: maxRetry,
+		types:    pgtype.NewMap(),
+		batchTTL: DefaultBatchTTL,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 // Ping проверяет доступность PostgreSQL соединения.
@@ -46,7 +81,7 @@ func (r *PostgresRepository) Close() error {
 
 // Truncate удаляет все метрики из таблицы.
 func (r *PostgresRepository) Truncate(ctx context.Context) error {
-	_, err := r.db.ExecContext(ctx, `TRUNCATE TABLE metrics`)
+	_, err := r.db.ExecContext(ctx, `TRUNCATE TABLE metrics, metric_samples, ingest_batches`)
 	if err != nil {
 		return fmt.Errorf("repository/truncate: exec: %w", err)
 	}
@@ -54,114 +89,339 @@ func (r *PostgresRepository) Truncate(ctx context.Context) error {
 }
 
 // Get возвращает метрику по имени.
-func (r *PostgresRepository) Get(ctx context.Context, name string) (model.MetricDto, error) {
-	query := `SELECT type, gauge, counter
+// Если переданы матчеры, то возвращает первую в каноническом порядке серию,
+// метки которой им удовлетворяют.
+func (r *PostgresRepository) Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error) {
+	query := `SELECT name, type, gauge, counter, hist_bounds, hist_counts, hist_sum, hist_count, labels, updated_at
 		FROM metrics
 		WHERE name = $1
-		LIMIT 1
+		ORDER BY labels_key
 	;`
 
-	row := r.db.QueryRowContext(ctx, query, name)
-	var (
-		t       model.MetricType
-		gauge   sql.NullFloat64
-		counter sql.NullInt64
-	)
+	rows, err := r.db.QueryContext(ctx, query, name)
+	if err != nil {
+		return model.MetricDto{}, fmt.Errorf("repository/get: query context: %w", err)
+	}
+	defer rows.Close()
 
-	if err := row.Scan(&t, &gauge, &counter); err != nil {
-		if err == sql.ErrNoRows {
-			return model.MetricDto{}, repository.ErrNotFound
+	for rows.Next() {
+		m, err := r.scanMetric(rows)
+		if err != nil {
+			return model.MetricDto{}, fmt.Errorf("repository/get: %w", err)
+		}
+		if model.MatchLabels(m.Labels, matchers) {
+			return m, nil
 		}
-		return model.MetricDto{}, fmt.Errorf("repository/get: scan: %w", err)
 	}
 
-	if row.Err() != nil {
-		return model.MetricDto{}, fmt.Errorf("repository/get: row: %w", row.Err())
+	if rows.Err() != nil {
+		return model.MetricDto{}, fmt.Errorf("repository/get: rows: %w", rows.Err())
 	}
 
-	return buildMetric(name, t, gauge.Float64, counter.Int64), nil
+	return model.MetricDto{}, repository.ErrNotFound
 }
 
-// GetAll возвращает все метрики.
-func (r *PostgresRepository) GetAll(ctx context.Context) ([]model.MetricDto, error) {
-	query := `SELECT name, type, gauge, counter
+// GetAll возвращает все метрики, метки которых удовлетворяют матчерам.
+func (r *PostgresRepository) GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error) {
+	query := `SELECT name, type, gauge, counter, hist_bounds, hist_counts, hist_sum, hist_count, labels, updated_at
 		FROM metrics
+		ORDER BY name, labels_key
 	;`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("repository/getAll: query context: %w", err)
 	}
+	defer rows.Close()
 
 	metrics := make([]model.MetricDto, 0)
 	for rows.Next() {
-		var (
-			name    string
-			t       model.MetricType
-			gauge   sql.NullFloat64
-			counter sql.NullInt64
-		)
-
-		if err := rows.Scan(&name, &t, &gauge, &counter); err != nil {
-			return nil, fmt.Errorf("repository/getAll: scan: %w", err)
+		m, err := r.scanMetric(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repository/getAll: %w", err)
+		}
+		if !model.MatchLabels(m.Labels, matchers) {
+			continue
 		}
-
-		m := buildMetric(name, t, gauge.Float64, counter.Int64)
 		metrics = append(metrics, m)
 	}
 
 	if rows.Err() != nil {
-		return nil, fmt.Errorf("repository/getAll: rows.Err(): %w", err)
+		return nil, fmt.Errorf("repository/getAll: rows.Err(): %w", rows.Err())
 	}
 
 	return metrics, nil
 }
 
-// Store сохраняет/обновляет метрику.
-func (r *PostgresRepository) Store(ctx context.Context, metric *model.MetricDto) error {
-	query := storeQuery()
-	_, err := r.db.ExecContext(ctx, query,
-		metric.Name,
-		metric.Value.Type,
-		metric.Value.Gauge,
-		metric.Value.Counter,
+// scanMetric читает метрику из текущей строки результата запроса.
+func (r *PostgresRepository) scanMetric(rows *sql.Rows) (model.MetricDto, error) {
+	var (
+		name    string
+		t       model.MetricType
+		gauge   sql.NullFloat64
+		counter sql.NullInt64
+		h       histogramColumns
+		labels  []byte
+		updated time.Time
 	)
+
+	if err := rows.Scan(&name, &t, &gauge, &counter,
+		r.types.SQLScanner(&h.bounds), r.types.SQLScanner(&h.counts), &h.sum, &h.count, &labels, &updated); err != nil {
+		return model.MetricDto{}, fmt.Errorf("scan: %w", err)
+	}
+
+	m := buildMetric(name, t, gauge.Float64, counter.Int64, h.histogram())
+	m.UpdatedAt = updated.UTC()
+	if len(labels) > 0 {
+		if err := json.Unmarshal(labels, &m.Labels); err != nil {
+			return model.MetricDto{}, fmt.Errorf("unmarshal labels: %w", err)
+		}
+		m.Labels = m.Labels.Clone()
+	}
+	return m, nil
+}
+
+// Store сохраняет/обновляет метрику и добавляет новое значение в историю.
+func (r *PostgresRepository) Store(ctx context.Context, metric *model.MetricDto) error {
+	if err := metric.Validate(); err != nil {
+		return fmt.Errorf("repository/store: %w", err)
+	}
+
+	ts := time.Now().UTC()
+	if err := r.ensurePartition(ctx, ts); err != nil {
+		return fmt.Errorf("repository/store: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository/store: begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	value, err := scanStored(tx.QueryRowContext(ctx, storeQuery(), metricArgs(metric)...))
 	if err != nil {
 		return fmt.Errorf("repository/store: exec query: %w", err)
 	}
+
+	if _, err := tx.ExecContext(ctx, sampleQuery(), sampleArgs(metric, value, ts)...); err != nil {
+		return fmt.Errorf("repository/store: append sample: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository/store: commit: %w", err)
+	}
 	return nil
 }
 
-// StoreMany сохраняет батч метрик.
+// StoreMany сохраняет батч метрик и добавляет их новые значения в историю.
+// Батч сохраняется в одной транзакции целиком или не сохраняется совсем.
 func (r *PostgresRepository) StoreMany(ctx context.Context, metrics []model.MetricDto) error {
 	if len(metrics) == 0 {
 		return nil
 	}
 
-	query := storeQuery()
+	ts := time.Now().UTC()
+	if err := r.ensurePartition(ctx, ts); err != nil {
+		return fmt.Errorf("repository/storeMany: %w", err)
+	}
 
-	stmt, err := r.db.PrepareContext(ctx, query)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("repository/storeMany: prepare query: %w", err)
+		return fmt.Errorf("repository/storeMany: begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := r.storeAll(ctx, tx, metrics, ts); err != nil {
+		return fmt.Errorf("repository/storeMany: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("repository/storeMany: commit: %w", err)
+	}
+	return nil
+}
+
+// storeAll сохраняет метрики и их новые значения с моментом ts в транзакции tx.
+// Невалидный батч отклоняется целиком до записи, первая ошибка записи прерывает сохранение.
+// Партиция для ts должна быть создана заранее.
+func (r *PostgresRepository) storeAll(ctx context.Context, tx *sql.Tx, metrics []model.MetricDto, ts time.Time) error {
+	for _, metric := range metrics {
+		if err := metric.Validate(); err != nil {
+			return err
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, storeQuery())
+	if err != nil {
+		return fmt.Errorf("prepare query: %w", err)
 	}
 	defer stmt.Close()
 
-	errs := make([]error, 0, len(metrics))
+	sampleStmt, err := tx.PrepareContext(ctx, sampleQuery())
+	if err != nil {
+		return fmt.Errorf("prepare sample query: %w", err)
+	}
+	defer sampleStmt.Close()
+
 	for _, metric := range metrics {
-		_, err := stmt.ExecContext(ctx, metric.Name,
-			metric.Value.Type,
-			metric.Value.Gauge,
-			metric.Value.Counter)
+		value, err := scanStored(stmt.QueryRowContext(ctx, metricArgs(&metric)...))
 		if err != nil {
-			err = fmt.Errorf("repository/storeMany: exec stmt: %w", err)
-			errs = append(errs, err)
+			return fmt.Errorf("exec stmt [%s]: %w", metric.Key(), err)
+		}
+		if _, err := sampleStmt.ExecContext(ctx, sampleArgs(&metric, value, ts)...); err != nil {
+			return fmt.Errorf("append sample [%s]: %w", metric.Key(), err)
 		}
 	}
+	return nil
+}
+
+// StoreBatch атомарно сохраняет пакет метрик с идентификатором batchID.
+// Идентификатор и метрики записываются в одной транзакции, поэтому пакет,
+// уже сохраненный в течение batchTTL, повторно не применяется и возвращается false.
+// При сетевых ошибках PostgreSQL сохранение повторяется.
+func (r *PostgresRepository) StoreBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error) {
+	r.sweepBatches(ctx)
+
+	applied, err := r.storeBatch(ctx, batchID, metrics)
+	for i := 0; err != nil && isPgConnErr(err) && i < r.maxRetry; i++ {
+		delay := i*2 + 1
+		time.Sleep(time.Second * time.Duration(delay))
+		applied, err = r.storeBatch(ctx, batchID, metrics)
+	}
+	if err != nil {
+		return false, fmt.Errorf("repository/storeBatch: %w", err)
+	}
+	return applied, nil
+}
 
-	if len(errs) > 0 {
-		return errors.Join(errs...)
+// storeBatch выполняет одну попытку сохранения пакета в транзакции.
+func (r *PostgresRepository) storeBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error) {
+	ts := time.Now().UTC()
+	if err := r.ensurePartition(ctx, ts); err != nil {
+		return false, err
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// истекший идентификатор переиспользуется, действующий - оставляется как есть
+	query := `INSERT INTO ingest_batches (batch_id, expires_at)
+		VALUES ($1, now() + $2 * interval '1 second')
+		ON CONFLICT (batch_id) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE ingest_batches.expires_at < now()
+		RETURNING batch_id
+	;`
+
+	var id string
+	err = tx.QueryRowContext(ctx, query, batchID, r.batchTTL.Seconds()).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("remember batch: %w", err)
+	}
+
+	if err := r.storeAll(ctx, tx, metrics, ts); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return true, nil
+}
+
+// sweepBatches удаляет истекшие идентификаторы пакетов не чаще раза в batchTTL.
+func (r *PostgresRepository) sweepBatches(ctx context.Context) {
+	now := time.Now().UnixNano()
+	last := r.lastSweep.Load()
+	if now-last < int64(r.batchTTL) || !r.lastSweep.CompareAndSwap(last, now) {
+		return
+	}
+	// ошибка очистки не мешает сохранению: истекшие записи переиспользуются при вставке
+	_, _ = r.db.ExecContext(ctx, `DELETE FROM ingest_batches WHERE expires_at < now();`)
+}
+
+// Range возвращает историю значений серий метрики name за интервал [from, to],
+// прореженную с шагом step.
+func (r *PostgresRepository) Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...model.Matcher) ([]model.Series, error) {
+	query := `SELECT type, labels, labels_key, ts, value
+		FROM metric_samples
+		WHERE name = $1 AND ts >= $2 AND ts <= $3
+		ORDER BY labels_key, type, ts
+	;`
+
+	rows, err := r.db.QueryContext(ctx, query, name, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("repository/range: query context: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Series, 0)
+	var lastKey string
+	for rows.Next() {
+		var (
+			t      model.MetricType
+			labels []byte
+			key    string
+			p      model.Point
+		)
+		if err := rows.Scan(&t, &labels, &key, &p.Timestamp, &p.Value); err != nil {
+			return nil, fmt.Errorf("repository/range: scan: %w", err)
+		}
+
+		key = t.String() + key
+		if len(result) == 0 || key != lastKey {
+			s := model.Series{Name: name, Type: t.String()}
+			if err := json.Unmarshal(labels, &s.Labels); err != nil {
+				return nil, fmt.Errorf("repository/range: unmarshal labels: %w", err)
+			}
+			s.Labels = s.Labels.Clone()
+			result = append(result, s)
+			lastKey = key
+		}
+		last := &result[len(result)-1]
+		last.Points = append(last.Points, p)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("repository/range: rows: %w", rows.Err())
+	}
+
+	filtered := result[:0]
+	for _, s := range result {
+		if !model.MatchLabels(s.Labels, matchers) {
+			continue
+		}
+		s.Points = model.Downsample(s.Points, from, step)
+		filtered = append(filtered, s)
+	}
+	return filtered, nil
+}
+
+// ensurePartition создает суточную партицию metric_samples для момента ts.
+// Партиции по умолчанию нет, поэтому без суточной партиции значения не сохраняются.
+func (r *PostgresRepository) ensurePartition(ctx context.Context, ts time.Time) error {
+	day := ts.UTC().Truncate(24 * time.Hour)
+	if _, ok := r.partitions.Load(day); ok {
+		return nil
+	}
+
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS metric_samples_%s
+		PARTITION OF metric_samples
+		FOR VALUES FROM ('%s') TO ('%s')
+	;`,
+		day.Format("20060102"),
+		day.Format(time.RFC3339),
+		day.Add(24*time.Hour).Format(time.RFC3339),
+	)
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("create partition %s: %w", day.Format(time.DateOnly), err)
+	}
+	r.partitions.Store(day, struct{}{})
 	return nil
 }
 
@@ -199,20 +459,120 @@ func isPgConnErr(err error) bool {
 // Set сохраняет метрику с перезаписью предыдущих занчений.
 func (r *PostgresRepository) Set(ctx context.Context, metric *model.MetricDto) error {
 	query := setQuery()
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.db.ExecContext(ctx, query, metricArgs(metric)...)
+	if err != nil {
+		return fmt.Errorf("repository/set: exec query: %w", err)
+	}
+	return nil
+}
+
+// histogramColumns содержит значения колонок гистограммы, прочитанные из БД.
+type histogramColumns struct {
+	bounds []float64
+	counts []int64
+	sum    sql.NullFloat64
+	count  sql.NullInt64
+}
+
+// histogram преобразует колонки в model.Histogram.
+// Возвращает nil, если гистограмма не была сохранена.
+func (c histogramColumns) histogram() *model.Histogram {
+	if c.counts == nil {
+		return nil
+	}
+	h := &model.Histogram{
+		Bounds: c.bounds,
+		Counts: make([]uint64, 0, len(c.counts)),
+		Sum:    c.sum.Float64,
+		Count:  uint64(c.count.Int64),
+	}
+	if h.Bounds == nil {
+		h.Bounds = []float64{}
+	}
+	for _, v := range c.counts {
+		h.Counts = append(h.Counts, uint64(v))
+	}
+	return h
+}
+
+// metricArgs возвращает аргументы для storeQuery и setQuery.
+// Метки сохраняются в JSONB, а их каноническое представление входит в первичный ключ.
+func metricArgs(metric *model.MetricDto) []any {
+	var (
+		bounds []float64
+		counts []int64
+		sum    float64
+		count  int64
+	)
+	if h := metric.Value.Histogram; metric.Value.Type == model.TypeHistogram && h != nil {
+		bounds = h.Bounds
+		if bounds == nil {
+			bounds = []float64{}
+		}
+		counts = make([]int64, 0, len(h.Counts))
+		for _, v := range h.Counts {
+			counts = append(counts, int64(v))
+		}
+		sum = h.Sum
+		count = int64(h.Count)
+	}
+
+	labels, err := json.Marshal(metric.Labels)
+	if err != nil || metric.Labels == nil {
+		labels = []byte("{}")
+	}
+
+	return []any{
 		metric.Name,
 		metric.Value.Type,
 		metric.Value.Gauge,
 		metric.Value.Counter,
+		bounds,
+		counts,
+		sum,
+		count,
+		string(labels),
+		metric.Labels.String(),
+	}
+}
+
+// scanStored читает значение метрики после UPSERT из storeQuery.
+func scanStored(row *sql.Row) (model.MetricValue, error) {
+	var (
+		v       model.MetricValue
+		count   sql.NullInt64
+		gauge   sql.NullFloat64
+		counter sql.NullInt64
 	)
-	if err != nil {
-		return fmt.Errorf("repository/set: exec query: %w", err)
+	if err := row.Scan(&v.Type, &gauge, &counter, &count); err != nil {
+		return model.MetricValue{}, err
+	}
+	v.Gauge = gauge.Float64
+	v.Counter = counter.Int64
+	if v.Type == model.TypeHistogram {
+		v.Histogram = &model.Histogram{Count: uint64(count.Int64)}
+	}
+	return v, nil
+}
+
+// sampleArgs возвращает аргументы для sampleQuery.
+func sampleArgs(metric *model.MetricDto, value model.MetricValue, ts time.Time) []any {
+	labels, err := json.Marshal(metric.Labels)
+	if err != nil || metric.Labels == nil {
+		labels = []byte("{}")
+	}
+	return []any{
+		metric.Name,
+		metric.Value.Type,
+		string(labels),
+		metric.Labels.String(),
+		ts,
+		value.SampleValue(),
 	}
-	return nil
 }
 
 // buildMetric преобразует переданные данные структуру model.Metric с учетом типа.
-func buildMetric(name string, t model.MetricType, gauge float64, counter int64) model.MetricDto {
+func buildMetric(name string, t model.MetricType, gauge float64, counter int64, h *model.Histogram) model.MetricDto {
 	var m model.MetricDto
 
 	switch t {
@@ -220,29 +580,55 @@ func buildMetric(name string, t model.MetricType, gauge float64, counter int64)
 		return model.Gauge(name, gauge)
 	case model.TypeCounter:
 		return model.Counter(name, counter)
+	case model.TypeHistogram:
+		return model.HistogramMetric(name, h)
 	}
 
 	return m
 }
 
-// storeQuery возвращает UPSERT запрос с накоплением counter.
+// storeQuery возвращает UPSERT запрос с накоплением counter и бакетов гистограммы.
+// Если границы бакетов гистограммы изменились, то она перезаписывается.
+// Время обновления серии устанавливается временем сервера БД.
+// Запрос возвращает сохраненное значение метрики для записи в историю.
 func storeQuery() string {
-	return `INSERT INTO metrics (name, type, gauge, counter) 
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (name, type)
+	return `INSERT INTO metrics (name, type, gauge, counter, hist_bounds, hist_counts, hist_sum, hist_count, labels, labels_key) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (name, type, labels_key)
 		DO UPDATE SET 
 			gauge = $3, 
-			counter = metrics.counter + $4
+			counter = metrics.counter + $4,
+			hist_bounds = $5,
+			hist_counts = CASE WHEN metrics.hist_bounds = $5 THEN (
+				SELECT array_agg(s.stored + s.received ORDER BY s.i)
+				FROM unnest(metrics.hist_counts, $6::BIGINT[]) WITH ORDINALITY AS s(stored, received, i)
+			) ELSE $6 END,
+			hist_sum = CASE WHEN metrics.hist_bounds = $5 THEN metrics.hist_sum + $7 ELSE $7 END,
+			hist_count = CASE WHEN metrics.hist_bounds = $5 THEN metrics.hist_count + $8 ELSE $8 END,
+			updated_at = now()
+		RETURNING type, gauge, counter, hist_count
+	;`
+}
+
+// sampleQuery возвращает запрос добавления значения в историю метрики.
+func sampleQuery() string {
+	return `INSERT INTO metric_samples (name, type, labels, labels_key, ts, value)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	;`
 }
 
 // setQuery возвращает UPSERT запрос с полной перезаписью counter.
 func setQuery() string {
-	return `INSERT INTO metrics (name, type, gauge, counter) 
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (name, type) 
+	return `INSERT INTO metrics (name, type, gauge, counter, hist_bounds, hist_counts, hist_sum, hist_count, labels, labels_key) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (name, type, labels_key) 
 		DO UPDATE SET 
 			gauge = $3, 
-			counter = $4
+			counter = $4,
+			hist_bounds = $5,
+			hist_counts = $6,
+			hist_sum = $7,
+			hist_count = $8,
+			updated_at = now()
 	;`
 }
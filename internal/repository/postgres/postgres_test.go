@@ -111,7 +111,7 @@ func TestSet(t *testing.T) {
 
 			m, err := r.Get(ctx, tc.metric.Name)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedMetric, m)
+			require.Equal(t, tc.expectedMetric, withoutUpdatedAt(t, m))
 		})
 	}
 }
@@ -122,6 +122,7 @@ func TestStore(t *testing.T) {
 
 	storeGauge := "store gauge"
 	storeCounter := "store counter"
+	storeHistogram := "store histogram"
 
 	r := setupTesting(t)
 
@@ -162,6 +163,42 @@ func TestStore(t *testing.T) {
 			}(),
 			expectedMetric: model.Counter(storeCounter, 3),
 		},
+		{
+			name: "valid histogram first",
+			metric: func() *model.MetricDto {
+				m := model.HistogramMetric(storeHistogram, &model.Histogram{
+					Bounds: []float64{0.1, 1}, Counts: []uint64{1, 0, 1}, Sum: 2.05, Count: 2,
+				})
+				return &m
+			}(),
+			expectedMetric: model.HistogramMetric(storeHistogram, &model.Histogram{
+				Bounds: []float64{0.1, 1}, Counts: []uint64{1, 0, 1}, Sum: 2.05, Count: 2,
+			}),
+		},
+		{
+			name: "valid histogram second",
+			metric: func() *model.MetricDto {
+				m := model.HistogramMetric(storeHistogram, &model.Histogram{
+					Bounds: []float64{0.1, 1}, Counts: []uint64{0, 1, 0}, Sum: 0.5, Count: 1,
+				})
+				return &m
+			}(),
+			expectedMetric: model.HistogramMetric(storeHistogram, &model.Histogram{
+				Bounds: []float64{0.1, 1}, Counts: []uint64{1, 1, 1}, Sum: 2.55, Count: 3,
+			}),
+		},
+		{
+			name: "histogram with new bounds",
+			metric: func() *model.MetricDto {
+				m := model.HistogramMetric(storeHistogram, &model.Histogram{
+					Bounds: []float64{5}, Counts: []uint64{1, 0}, Sum: 3, Count: 1,
+				})
+				return &m
+			}(),
+			expectedMetric: model.HistogramMetric(storeHistogram, &model.Histogram{
+				Bounds: []float64{5}, Counts: []uint64{1, 0}, Sum: 3, Count: 1,
+			}),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -171,7 +208,7 @@ func TestStore(t *testing.T) {
 
 			m, err := r.Get(ctx, tc.metric.Name)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedMetric, m)
+			require.Equal(t, tc.expectedMetric, withoutUpdatedAt(t, m))
 		})
 	}
 }
@@ -231,7 +268,7 @@ func TestGet(t *testing.T) {
 
 			m, err := r.Get(ctx, tc.expectedMetric.Name)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedMetric, m)
+			require.Equal(t, tc.expectedMetric, withoutUpdatedAt(t, m))
 		})
 	}
 }
@@ -283,7 +320,7 @@ func TestGetAll(t *testing.T) {
 
 			m, err := r.GetAll(ctx)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedMetrics, m)
+			require.Equal(t, tc.expectedMetrics, allWithoutUpdatedAt(t, m))
 		})
 	}
 }
@@ -333,11 +370,32 @@ func TestStoreMany(t *testing.T) {
 
 			m, err := r.GetAll(ctx)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedMetrics, m)
+			require.Equal(t, tc.expectedMetrics, allWithoutUpdatedAt(t, m))
 		})
 	}
 }
 
+func TestStoreManyAtomic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	r := setupTesting(t)
+
+	invalid := []model.MetricDto{
+		model.Counter("atomic counter", 1),
+		{Name: "atomic hist", Value: model.MetricValue{Type: model.TypeHistogram, Histogram: &model.Histogram{
+			Bounds: []float64{1},
+			Counts: []uint64{1},
+		}}},
+	}
+	err := r.StoreMany(ctx, invalid)
+	require.ErrorIs(t, err, model.ErrInvalidMetric)
+
+	m, err := r.GetAll(ctx)
+	require.NoError(t, err)
+	require.Empty(t, m)
+}
+
 func TestStoreManyWithRetry(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -441,7 +499,82 @@ func TestStoreManyWithRetry(t *testing.T) {
 
 			m, err := tc.repository.GetAll(ctx)
 			require.NoError(t, err)
-			require.Equal(t, tc.expectedMetrics, m)
+			require.Equal(t, tc.expectedMetrics, allWithoutUpdatedAt(t, m))
 		})
 	}
 }
+
+func TestLabels(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	r := setupTesting(t)
+
+	web1 := model.Counter("labels counter", 1)
+	web1.Labels = model.Labels{"host": "web-1"}
+	web2 := model.Counter("labels counter", 5)
+	web2.Labels = model.Labels{"host": "web-2"}
+
+	err := r.StoreMany(ctx, []model.MetricDto{web1, web2, web1})
+	require.NoError(t, err)
+
+	m, err := model.ParseMatcher(`host="web-1"`)
+	require.NoError(t, err)
+
+	got, err := r.Get(ctx, "labels counter", m)
+	require.NoError(t, err)
+
+	expected := model.Counter("labels counter", 2)
+	expected.Labels = model.Labels{"host": "web-1"}
+	require.Equal(t, expected, got)
+
+	all, err := r.GetAll(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, []model.MetricDto{expected}, all)
+}
+
+func TestRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	r := setupTesting(t)
+
+	from := time.Now().Add(-time.Minute)
+
+	err := r.StoreMany(ctx, []model.MetricDto{model.Gauge("range gauge", 1), model.Counter("range counter", 1)})
+	require.NoError(t, err)
+	err = r.StoreMany(ctx, []model.MetricDto{model.Gauge("range gauge", 2), model.Counter("range counter", 2)})
+	require.NoError(t, err)
+
+	series, err := r.Range(ctx, "range gauge", from, time.Now(), 0)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Points, 2)
+	require.Equal(t, 1.0, series[0].Points[0].Value)
+	require.Equal(t, 2.0, series[0].Points[1].Value)
+
+	series, err = r.Range(ctx, "range counter", from, time.Now(), time.Hour)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	require.Len(t, series[0].Points, 1)
+	require.Equal(t, 3.0, series[0].Points[0].Value)
+}
+
+// withoutUpdatedAt проверяет, что хранилище заполнило время обновления серии,
+// и сбрасывает его для сравнения значений.
+func withoutUpdatedAt(t *testing.T, metric model.MetricDto) model.MetricDto {
+	t.Helper()
+	require.False(t, metric.UpdatedAt.IsZero(), "updated_at of %s is not set", metric.Key())
+	metric.UpdatedAt = time.Time{}
+	return metric
+}
+
+// allWithoutUpdatedAt применяет withoutUpdatedAt к каждой серии.
+func allWithoutUpdatedAt(t *testing.T, metrics []model.MetricDto) []model.MetricDto {
+	t.Helper()
+	result := make([]model.MetricDto, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, withoutUpdatedAt(t, m))
+	}
+	return result
+}
@@ -2,22 +2,49 @@ package router
 
 import (
 	"crypto/rsa"
-	"net"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
+	"github.com/htrandev/metrics/internal/auth"
 	"github.com/htrandev/metrics/internal/handler"
 	"github.com/htrandev/metrics/internal/handler/middleware"
+	"github.com/htrandev/metrics/pkg/netutil"
+	"github.com/htrandev/metrics/pkg/sign"
 )
 
 type RouterOptions struct {
-	Signature string
-	Subnet    *net.IPNet
-	Key       *rsa.PrivateKey
-	Logger    *zap.Logger
-	Handler   *handler.MetricHandler
+	// Verifier проверяет подписи запросов. Если nil, подписи не проверяются.
+	Verifier *sign.Verifier
+	Key      *rsa.PrivateKey
+	Logger   *zap.Logger
+	Handler  *handler.MetricHandler
+
+	// LegacyKey и LegacyUntil задают переходный период, в течение которого
+	// принимаются запросы с подписью HashSHA256 и без подписи, см. middleware.WithLegacy.
+	LegacyKey   sign.Signature
+	LegacyUntil time.Time
+
+	// Subnets доверенные подсети агентов. Если пусто, IP-адрес не проверяется.
+	Subnets netutil.Subnets
+	// Proxies доверенные прокси, через которые проходится заголовок X-Forwarded-For.
+	Proxies netutil.Subnets
+	// StrictIP запрещает запросы, IP-адрес которых не удалось определить.
+	StrictIP bool
+
+	// Tokens реестр API токенов агентов. Если nil, токены не проверяются.
+	Tokens auth.Registry
+
+	// CertIdentity включает определение агента по клиентскому сертификату
+	// вместо заголовка X-Real-IP.
+	CertIdentity bool
+
+	// UnsignedIngest разрешает неподписанную запись через протоколы приема
+	// (remote_write, InfluxDB, Graphite, OTLP) для сторонних источников.
+	// Действует, только если доступ ограничен Tokens или Subnets.
+	UnsignedIngest bool
 }
 
 // New возвращает новый экземляр роутера.
@@ -30,9 +57,28 @@ type RouterOptions struct {
 //   - GET    /value/ - получить значение метрики в формате JSON
 //   - GET    /ping - проверка доступности БД
 //   - POST   /updates/ - обновить несколько метрик в формате JSON
+//   - GET    /api/v1/range - получить историю значений метрики в формате JSON
+//   - GET    /metrics - получить все метрики в формате экспозиции Prometheus
+//   - POST   /api/v1/write - принять метрики по протоколу Prometheus remote_write
+//   - POST   /write, /api/v2/write - принять метрики в формате InfluxDB line protocol
+//   - POST   /graphite - принять метрики в формате Graphite plaintext
+//   - POST   /v1/metrics - принять метрики OpenTelemetry по протоколу OTLP/HTTP
+//   - GET    /api/v1/alerts - получить состояния оповещений в формате JSON
+//   - GET    /api/v1/baselines - получить оценки нормальных значений правил аномалий в формате JSON
+//   - GET    /api/v1/silences - получить заглушения оповещений в формате JSON
+//   - POST   /api/v1/silences - создать заглушение
+//   - GET    /api/v1/silences/{id} - получить заглушение
+//   - PUT    /api/v1/silences/{id} - изменить заглушение
+//   - DELETE /api/v1/silences/{id} - удалить заглушение
 func New(opts RouterOptions) *chi.Mux {
 	r := chi.NewRouter()
 
+	r.Use(middleware.RequestID(), middleware.RealIP(opts.Proxies))
+
+	if opts.CertIdentity {
+		r.Use(middleware.CertIdentity())
+	}
+
 	var (
 		getMethodChecker  = middleware.MethodChecker(http.MethodGet)
 		postMethodChecker = middleware.MethodChecker(http.MethodPost)
@@ -41,35 +87,91 @@ func New(opts RouterOptions) *chi.Mux {
 
 		ct = middleware.ContentType()
 
-		signer = middleware.Sign(opts.Signature, opts.Logger)
+		signOpts = []middleware.SignOption{middleware.WithLegacy(opts.LegacyKey, opts.LegacyUntil)}
+		signer   = middleware.Sign(opts.Verifier, opts.Logger, signOpts...)
 
 		compressor = middleware.Compress(opts.Logger)
 
 		rsa = middleware.RSA(opts.Key, opts.Logger)
+
+		reader = middleware.Auth(opts.Tokens, auth.ScopeRead, opts.Logger)
+		writer = middleware.Auth(opts.Tokens, auth.ScopeWrite, opts.Logger)
+		admin  = middleware.Auth(opts.Tokens, auth.ScopeAdmin, opts.Logger)
 	)
 
-	r.With(getMethodChecker, l, signer, compressor).
+	r.With(getMethodChecker, l, reader, signer, compressor).
 		Get("/", opts.Handler.GetAll)
 
-	r.With(getMethodChecker, l, signer).
+	r.With(getMethodChecker, l, reader, signer).
 		Get("/value/{metricType}/{metricName}", opts.Handler.Get)
 
-	r.With(postMethodChecker, l, signer).
+	r.With(postMethodChecker, l, writer, signer).
 		Post("/update/{metricType}/{metricName}/{metricValue}", opts.Handler.Update)
 
-	r.With(postMethodChecker, l, ct, signer, compressor).
+	r.With(postMethodChecker, l, writer, ct, signer, compressor).
 		Post("/update/", opts.Handler.UpdateJSON)
 
-	r.With(postMethodChecker, l, ct, signer, compressor).
+	r.With(postMethodChecker, l, reader, ct, signer, compressor).
 		Post("/value/", opts.Handler.GetJSON)
 
 	r.With(getMethodChecker).
 		Get("/ping", opts.Handler.Ping)
 
-	middlewares := make([]func(http.Handler) http.Handler, 0, 7)
-	middlewares = append(middlewares, postMethodChecker, l, ct, rsa, signer, compressor)
-	if opts.Subnet != nil {
-		middlewares = append(middlewares, middleware.Subnet(opts.Subnet))
+	r.With(getMethodChecker, l, reader, signer, compressor).
+		Get("/api/v1/range", opts.Handler.Range)
+
+	r.With(getMethodChecker, l, reader, signer, compressor).
+		Get("/api/v1/alerts", opts.Handler.Alerts)
+
+	r.With(getMethodChecker, l, reader, signer, compressor).
+		Get("/api/v1/baselines", opts.Handler.Baselines)
+
+	r.Route("/api/v1/silences", func(r chi.Router) {
+		r.With(l, reader, signer, compressor).Get("/", opts.Handler.ListSilences)
+		r.With(l, reader, signer, compressor).Get("/{id}", opts.Handler.GetSilence)
+		r.With(l, admin, ct, signer, compressor).Post("/", opts.Handler.CreateSilence)
+		r.With(l, admin, ct, signer, compressor).Put("/{id}", opts.Handler.UpdateSilence)
+		r.With(l, admin, signer).Delete("/{id}", opts.Handler.DeleteSilence)
+	})
+
+	scrape := []func(http.Handler) http.Handler{getMethodChecker, l, reader, compressor}
+	if len(opts.Subnets) > 0 {
+		scrape = append(scrape, middleware.Subnet(opts.Subnets, opts.StrictIP))
+	}
+	r.With(scrape...).
+		Get("/metrics", opts.Handler.Prometheus)
+
+	// сторонние источники (Prometheus, Telegraf, OpenTelemetry Collector) не подписывают запросы,
+	// неподписанная запись через протоколы приема разрешается явно и только при проверке токенов или подсетей
+	ingestSigner := signer
+	if opts.UnsignedIngest && (opts.Tokens != nil || len(opts.Subnets) > 0) {
+		ingestSigner = middleware.Sign(opts.Verifier, opts.Logger, append(signOpts, middleware.AllowUnsigned())...)
+	}
+
+	remoteWrite := []func(http.Handler) http.Handler{postMethodChecker, l, writer, ingestSigner}
+	if len(opts.Subnets) > 0 {
+		remoteWrite = append(remoteWrite, middleware.Subnet(opts.Subnets, opts.StrictIP))
+	}
+	r.With(remoteWrite...).
+		Post("/api/v1/write", opts.Handler.RemoteWrite)
+
+	ingest := []func(http.Handler) http.Handler{postMethodChecker, l, writer, ingestSigner, compressor}
+	if len(opts.Subnets) > 0 {
+		ingest = append(ingest, middleware.Subnet(opts.Subnets, opts.StrictIP))
+	}
+	r.With(ingest...).
+		Post("/write", opts.Handler.InfluxWrite)
+	r.With(ingest...).
+		Post("/api/v2/write", opts.Handler.InfluxWrite)
+	r.With(ingest...).
+		Post("/graphite", opts.Handler.GraphiteWrite)
+	r.With(ingest...).
+		Post("/v1/metrics", opts.Handler.OTLP)
+
+	middlewares := make([]func(http.Handler) http.Handler, 0, 8)
+	middlewares = append(middlewares, postMethodChecker, l, writer, ct, signer, rsa, compressor)
+	if len(opts.Subnets) > 0 {
+		middlewares = append(middlewares, middleware.Subnet(opts.Subnets, opts.StrictIP))
 	}
 	r.With(middlewares...).
 		Post("/updates/", opts.Handler.UpdateManyJSON)
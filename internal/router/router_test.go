@@ -53,11 +53,11 @@ func Example() {
 
 	// инициализируем роутер
 	router := New(RouterOptions{
-		Signature: "",
-		Subnet:    nil,
-		Key:       nil,
-		Logger:    logger,
-		Handler:   handler,
+		Verifier: nil,
+		Subnets:  nil,
+		Key:      nil,
+		Logger:   logger,
+		Handler:  handler,
 	})
 
 	// инициализируем сервис
@@ -3,6 +3,7 @@ package metrics
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -11,12 +12,14 @@ import (
 
 // Storage предоставляет интерфейс для работы с хранилищем.
 type Storage interface {
-	Get(ctx context.Context, name string) (model.MetricDto, error)
-	GetAll(ctx context.Context) ([]model.MetricDto, error)
+	Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error)
+	GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error)
+	Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...model.Matcher) ([]model.Series, error)
 
 	Store(ctx context.Context, metric *model.MetricDto) error
 	StoreMany(ctx context.Context, metric []model.MetricDto) error
 	StoreManyWithRetry(ctx context.Context, metric []model.MetricDto) error
+	StoreBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error)
 
 	Ping(ctx context.Context) error
 }
@@ -46,23 +49,33 @@ func (s *MetricsService) Ping(ctx context.Context) error {
 }
 
 // Get возвращает метрику по имени.
-func (s *MetricsService) Get(ctx context.Context, name string) (model.MetricDto, error) {
-	m, err := s.opts.Storage.Get(ctx, name)
+// Если переданы матчеры, то возвращается первая серия, метки которой им удовлетворяют.
+func (s *MetricsService) Get(ctx context.Context, name string, matchers ...model.Matcher) (model.MetricDto, error) {
+	m, err := s.opts.Storage.Get(ctx, name, matchers...)
 	if err != nil {
 		return model.MetricDto{}, fmt.Errorf("get metric: %w", err)
 	}
 	return m, nil
 }
 
-// GetAll возвращает все метрики.
-func (s *MetricsService) GetAll(ctx context.Context) ([]model.MetricDto, error) {
-	m, err := s.opts.Storage.GetAll(ctx)
+// GetAll возвращает все метрики, метки которых удовлетворяют матчерам.
+func (s *MetricsService) GetAll(ctx context.Context, matchers ...model.Matcher) ([]model.MetricDto, error) {
+	m, err := s.opts.Storage.GetAll(ctx, matchers...)
 	if err != nil {
 		return nil, fmt.Errorf("get all metrics: %w", err)
 	}
 	return m, nil
 }
 
+// Range возвращает историю значений серий метрики за интервал [from, to] с шагом step.
+func (s *MetricsService) Range(ctx context.Context, name string, from, to time.Time, step time.Duration, matchers ...model.Matcher) ([]model.Series, error) {
+	series, err := s.opts.Storage.Range(ctx, name, from, to, step, matchers...)
+	if err != nil {
+		return nil, fmt.Errorf("range metric: %w", err)
+	}
+	return series, nil
+}
+
 // Store сохраняет одну метрику.
 func (s *MetricsService) Store(ctx context.Context, m *model.MetricDto) error {
 	if m == nil {
@@ -99,3 +112,25 @@ func (s *MetricsService) StoreManyWithRetry(ctx context.Context, metrics []model
 
 	return nil
 }
+
+// StoreBatch сохраняет батч с идентификатором batchID не более одного раза.
+// Возвращает false, если батч с таким идентификатором уже был сохранен.
+// Если идентификатор пуст, батч сохраняется с повторными попытками без дедупликации.
+func (s *MetricsService) StoreBatch(ctx context.Context, batchID string, metrics []model.MetricDto) (bool, error) {
+	if len(metrics) == 0 {
+		return true, nil
+	}
+
+	if batchID == "" {
+		if err := s.StoreManyWithRetry(ctx, metrics); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	applied, err := s.opts.Storage.StoreBatch(ctx, batchID, metrics)
+	if err != nil {
+		return false, fmt.Errorf("store batch [%s]: %w", batchID, err)
+	}
+	return applied, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -14,6 +15,7 @@ var (
 	errStore              = errors.New("store error")
 	errStoreMany          = errors.New("store many error")
 	errStoreManyWithRetry = errors.New("store many with retry error")
+	errStoreBatch         = errors.New("store batch error")
 
 	errGet    = errors.New("get error")
 	errGetAll = errors.New("getAll error")
@@ -29,8 +31,12 @@ type mockStorage struct {
 	storeErr              bool
 	storeManyErr          bool
 	storeManyWithRetryErr bool
+	storeBatchErr         bool
 	pingErr               bool
 
+	// batches идентификаторы батчей, уже переданных в StoreBatch.
+	batches map[string]bool
+
 	gauge  bool
 	filled bool
 }
@@ -48,7 +54,7 @@ func (m *mockStorage) Set(_ context.Context, _ *model.MetricDto) error {
 	return nil
 }
 
-func (m *mockStorage) Get(_ context.Context, _ string) (model.MetricDto, error) {
+func (m *mockStorage) Get(_ context.Context, _ string, _ ...model.Matcher) (model.MetricDto, error) {
 	if m.getErr {
 		return model.MetricDto{}, errGet
 	}
@@ -65,7 +71,7 @@ func (m *mockStorage) Get(_ context.Context, _ string) (model.MetricDto, error)
 	return metric, nil
 }
 
-func (m *mockStorage) GetAll(_ context.Context) ([]model.MetricDto, error) {
+func (m *mockStorage) GetAll(_ context.Context, _ ...model.Matcher) ([]model.MetricDto, error) {
 	if m.getAllErr {
 		return nil, errGetAll
 	}
@@ -75,6 +81,10 @@ func (m *mockStorage) GetAll(_ context.Context) ([]model.MetricDto, error) {
 	return []model.MetricDto{}, nil
 }
 
+func (m *mockStorage) Range(_ context.Context, _ string, _, _ time.Time, _ time.Duration, _ ...model.Matcher) ([]model.Series, error) {
+	return nil, nil
+}
+
 func (m *mockStorage) Store(_ context.Context, _ *model.MetricDto) error {
 	if m.storeErr {
 		return errStore
@@ -96,6 +106,20 @@ func (m *mockStorage) StoreManyWithRetry(_ context.Context, _ []model.MetricDto)
 	return nil
 }
 
+func (m *mockStorage) StoreBatch(_ context.Context, batchID string, _ []model.MetricDto) (bool, error) {
+	if m.storeBatchErr {
+		return false, errStoreBatch
+	}
+	if m.batches[batchID] {
+		return false, nil
+	}
+	if m.batches == nil {
+		m.batches = make(map[string]bool)
+	}
+	m.batches[batchID] = true
+	return true, nil
+}
+
 func TestGet(t *testing.T) {
 	ctx := context.Background()
 	testCases := []struct {
@@ -344,6 +368,84 @@ func TestStoreManyWithRetry(t *testing.T) {
 	}
 }
 
+func TestStoreBatch(t *testing.T) {
+	ctx := context.Background()
+	metrics := []model.MetricDto{
+		model.Gauge("gauge", 0.1),
+		model.Counter("counter", 1),
+	}
+
+	testCases := []struct {
+		name            string
+		storage         *mockStorage
+		batchID         string
+		metrics         []model.MetricDto
+		wantErr         bool
+		expectedError   error
+		expectedApplied bool
+	}{
+		{
+			name:            "new batch",
+			storage:         &mockStorage{},
+			batchID:         "batch",
+			metrics:         metrics,
+			expectedApplied: true,
+		},
+		{
+			name:            "duplicate batch",
+			storage:         &mockStorage{batches: map[string]bool{"batch": true}},
+			batchID:         "batch",
+			metrics:         metrics,
+			expectedApplied: false,
+		},
+		{
+			name:            "without batch id",
+			storage:         &mockStorage{storeBatchErr: true},
+			batchID:         "",
+			metrics:         metrics,
+			expectedApplied: true,
+		},
+		{
+			name:          "without batch id store error",
+			storage:       &mockStorage{storeManyWithRetryErr: true},
+			batchID:       "",
+			metrics:       metrics,
+			wantErr:       true,
+			expectedError: errStoreManyWithRetry,
+		},
+		{
+			name:          "invalid",
+			storage:       &mockStorage{storeBatchErr: true},
+			batchID:       "batch",
+			metrics:       metrics,
+			wantErr:       true,
+			expectedError: errStoreBatch,
+		},
+		{
+			name:            "nil request",
+			storage:         &mockStorage{storeBatchErr: true},
+			batchID:         "batch",
+			metrics:         nil,
+			expectedApplied: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewService(&ServiсeOptions{Storage: tc.storage})
+
+			applied, err := s.StoreBatch(ctx, tc.batchID, tc.metrics)
+			if tc.wantErr {
+				require.Error(t, err)
+				require.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedApplied, applied)
+		})
+	}
+}
+
 func TestPing(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,164 @@
+package silence
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mailru/easyjson"
+)
+
+// maxRecordSize максимальный размер записи файла заглушений.
+const maxRecordSize = 1 << 20
+
+// fileRecord запись файла заглушений.
+//
+//easyjson:json
+type fileRecord struct {
+	Silence
+	// Deleted отмечает удаление заглушения.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// FileStorage хранит заглушения в JSONL файле.
+//
+// Каждое изменение дописывается в конец файла отдельной строкой,
+// более поздняя запись заглушения заменяет предыдущие. При загрузке
+// файл перезаписывается только актуальными заглушениями.
+type FileStorage struct {
+	path string
+
+	mu sync.Mutex
+}
+
+// NewFileStorage возвращает хранилище заглушений в файле path.
+func NewFileStorage(path string) *FileStorage {
+	return &FileStorage{path: path}
+}
+
+// Load читает заглушения из файла и сжимает файл.
+// Отсутствие файла не является ошибкой.
+func (f *FileStorage) Load(_ context.Context) ([]Silence, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("silence/Load: open file: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		order    []string
+		silences = make(map[string]Silence)
+	)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxRecordSize)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec fileRecord
+		if err := easyjson.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("silence/Load: line %d: %w", n, err)
+		}
+		if rec.Deleted {
+			delete(silences, rec.ID)
+			continue
+		}
+		if _, ok := silences[rec.ID]; !ok {
+			order = append(order, rec.ID)
+		}
+		silences[rec.ID] = rec.Silence
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("silence/Load: scan: %w", err)
+	}
+
+	result := make([]Silence, 0, len(silences))
+	for _, id := range order {
+		if s, ok := silences[id]; ok {
+			result = append(result, s)
+			delete(silences, id)
+		}
+	}
+
+	if err := f.compact(result); err != nil {
+		return nil, fmt.Errorf("silence/Load: %w", err)
+	}
+	return result, nil
+}
+
+// Save дописывает заглушение в файл.
+func (f *FileStorage) Save(_ context.Context, s Silence) error {
+	if err := f.append(fileRecord{Silence: s}); err != nil {
+		return fmt.Errorf("silence/Save: %w", err)
+	}
+	return nil
+}
+
+// Delete дописывает в файл отметку об удалении заглушения.
+func (f *FileStorage) Delete(_ context.Context, id string) error {
+	if err := f.append(fileRecord{Silence: Silence{ID: id}, Deleted: true}); err != nil {
+		return fmt.Errorf("silence/Delete: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStorage) append(rec fileRecord) error {
+	b, err := easyjson.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0664)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	if _, err := file.Write(append(b, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	return file.Close()
+}
+
+// compact атомарно перезаписывает файл заглушениями silences.
+func (f *FileStorage) compact(silences []Silence) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, s := range silences {
+		b, err := easyjson.Marshal(fileRecord{Silence: s})
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("marshal: %w", err)
+		}
+		w.Write(b)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
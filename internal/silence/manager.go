@@ -0,0 +1,190 @@
+package silence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// Storage постоянное хранилище заглушений.
+type Storage interface {
+	// Load возвращает все сохраненные заглушения.
+	Load(ctx context.Context) ([]Silence, error)
+	// Save добавляет или заменяет заглушение с тем же идентификатором.
+	Save(ctx context.Context, s Silence) error
+	// Delete удаляет заглушение.
+	Delete(ctx context.Context, id string) error
+}
+
+// Manager хранит заглушения в памяти и сохраняет изменения в Storage.
+// Заглушения загружаются из хранилища при создании, поэтому изменения,
+// сделанные другими экземплярами сервера, не видны до перезапуска.
+type Manager struct {
+	storage Storage
+
+	mu       sync.RWMutex
+	silences map[string]Silence
+
+	now func() time.Time
+}
+
+// NewManager загружает заглушения из storage.
+// Если storage nil, заглушения хранятся только в памяти.
+func NewManager(ctx context.Context, storage Storage) (*Manager, error) {
+	m := &Manager{
+		storage:  storage,
+		silences: make(map[string]Silence),
+		now:      time.Now,
+	}
+	if storage == nil {
+		return m, nil
+	}
+
+	silences, err := storage.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("silence/NewManager: load: %w", err)
+	}
+	for _, s := range silences {
+		if err := s.compile(); err != nil {
+			return nil, fmt.Errorf("silence/NewManager: silence %s: %w", s.ID, err)
+		}
+		m.silences[s.ID] = s
+	}
+	return m, nil
+}
+
+// List возвращает все заглушения, упорядоченные по времени начала.
+func (m *Manager) List() []Silence {
+	now := m.now()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	silences := make([]Silence, 0, len(m.silences))
+	for _, s := range m.silences {
+		s.Status = s.StatusAt(now)
+		silences = append(silences, s)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		if !silences[i].StartsAt.Equal(silences[j].StartsAt) {
+			return silences[i].StartsAt.Before(silences[j].StartsAt)
+		}
+		return silences[i].ID < silences[j].ID
+	})
+	return silences
+}
+
+// Get возвращает заглушение по идентификатору.
+func (m *Manager) Get(id string) (Silence, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.silences[id]
+	if !ok {
+		return Silence{}, ErrNotFound
+	}
+	s.Status = s.StatusAt(m.now())
+	return s, nil
+}
+
+// Create проверяет и сохраняет новое заглушение. Если время начала
+// не задано, заглушение начинается сразу.
+func (m *Manager) Create(ctx context.Context, s Silence) (Silence, error) {
+	now := m.now()
+
+	s.ID = uuid.NewString()
+	if s.StartsAt.IsZero() {
+		s.StartsAt = now
+	}
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	if err := m.put(ctx, s); err != nil {
+		return Silence{}, fmt.Errorf("silence/Create: %w", err)
+	}
+	s.Status = s.StatusAt(now)
+	return s, nil
+}
+
+// Update заменяет матчеры, интервал и комментарий заглушения id.
+func (m *Manager) Update(ctx context.Context, id string, s Silence) (Silence, error) {
+	old, err := m.Get(id)
+	if err != nil {
+		return Silence{}, fmt.Errorf("silence/Update: %w", err)
+	}
+
+	now := m.now()
+	s.ID = id
+	s.CreatedAt = old.CreatedAt
+	s.UpdatedAt = now
+	if s.StartsAt.IsZero() {
+		s.StartsAt = old.StartsAt
+	}
+	if s.CreatedBy == "" {
+		s.CreatedBy = old.CreatedBy
+	}
+
+	if err := m.put(ctx, s); err != nil {
+		return Silence{}, fmt.Errorf("silence/Update: %w", err)
+	}
+	s.Status = s.StatusAt(now)
+	return s, nil
+}
+
+// put проверяет заглушение и сохраняет его в хранилище и в памяти.
+func (m *Manager) put(ctx context.Context, s Silence) error {
+	if err := s.compile(); err != nil {
+		return err
+	}
+	s.Status = ""
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.storage != nil {
+		if err := m.storage.Save(ctx, s); err != nil {
+			return fmt.Errorf("save: %w", err)
+		}
+	}
+	m.silences[s.ID] = s
+	return nil
+}
+
+// Delete удаляет заглушение.
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.silences[id]; !ok {
+		return fmt.Errorf("silence/Delete: %w", ErrNotFound)
+	}
+	if m.storage != nil {
+		if err := m.storage.Delete(ctx, id); err != nil {
+			return fmt.Errorf("silence/Delete: %w", err)
+		}
+	}
+	delete(m.silences, id)
+	return nil
+}
+
+// Silenced возвращает идентификаторы заглушений, действующих на момент now
+// и заглушающих оповещение с метками labels.
+func (m *Manager) Silenced(labels model.Labels, now time.Time) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []string
+	for id, s := range m.silences {
+		if s.StatusAt(now) == StatusActive && s.Matches(labels) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
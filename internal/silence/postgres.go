@@ -0,0 +1,92 @@
+package silence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresStorage хранит заглушения в таблице silences.
+type PostgresStorage struct {
+	db    *sql.DB
+	types *pgtype.Map
+}
+
+// NewPostgresStorage возвращает хранилище заглушений поверх db.
+func NewPostgresStorage(db *sql.DB) *PostgresStorage {
+	return &PostgresStorage{db: db, types: pgtype.NewMap()}
+}
+
+// Load возвращает все заглушения.
+func (p *PostgresStorage) Load(ctx context.Context) ([]Silence, error) {
+	query := `SELECT id, matchers, starts_at, ends_at, created_by, comment, created_at, updated_at
+		FROM silences
+		ORDER BY created_at
+	;`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("silence/Load: query: %w", err)
+	}
+	defer rows.Close()
+
+	var silences []Silence
+	for rows.Next() {
+		var s Silence
+		err := rows.Scan(&s.ID, p.types.SQLScanner(&s.Matchers), &s.StartsAt, &s.EndsAt,
+			&s.CreatedBy, &s.Comment, &s.CreatedAt, &s.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("silence/Load: scan: %w", err)
+		}
+		s.StartsAt = s.StartsAt.UTC()
+		s.EndsAt = s.EndsAt.UTC()
+		s.CreatedAt = s.CreatedAt.UTC()
+		s.UpdatedAt = s.UpdatedAt.UTC()
+		silences = append(silences, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("silence/Load: rows: %w", err)
+	}
+	return silences, nil
+}
+
+// Save добавляет или обновляет заглушение.
+func (p *PostgresStorage) Save(ctx context.Context, s Silence) error {
+	query := `INSERT INTO silences (id, matchers, starts_at, ends_at, created_by, comment, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			matchers = EXCLUDED.matchers,
+			starts_at = EXCLUDED.starts_at,
+			ends_at = EXCLUDED.ends_at,
+			created_by = EXCLUDED.created_by,
+			comment = EXCLUDED.comment,
+			updated_at = EXCLUDED.updated_at
+	;`
+
+	_, err := p.db.ExecContext(ctx, query, s.ID, s.Matchers, s.StartsAt, s.EndsAt,
+		s.CreatedBy, s.Comment, s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("silence/Save: exec: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет заглушение.
+func (p *PostgresStorage) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM silences WHERE id = $1;`
+
+	res, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("silence/Delete: exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("silence/Delete: rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
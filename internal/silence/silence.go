@@ -0,0 +1,96 @@
+// Package silence реализует заглушения оповещений на время обслуживания.
+package silence
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+var (
+	// ErrNotFound возвращается для неизвестного заглушения.
+	ErrNotFound = errors.New("silence not found")
+	// ErrInvalidSilence возвращается при некорректном описании заглушения.
+	ErrInvalidSilence = errors.New("invalid silence")
+)
+
+// Status состояние заглушения.
+type Status string
+
+const (
+	// StatusPending заглушение еще не началось.
+	StatusPending Status = "pending"
+	// StatusActive заглушение действует.
+	StatusActive Status = "active"
+	// StatusExpired заглушение закончилось.
+	StatusExpired Status = "expired"
+)
+
+// Silence заглушение оповещений, метки которых удовлетворяют всем матчерам,
+// в интервале [StartsAt, EndsAt).
+//
+//easyjson:json
+type Silence struct {
+	ID string `json:"id"`
+	// Matchers матчеры меток оповещения в формате name="value", name=~"regexp".
+	// Имя правила доступно в метке alertname.
+	Matchers  []string  `json:"matchers"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Status вычисляется при чтении и не хранится.
+	Status Status `json:"status,omitempty"`
+
+	matchers []model.Matcher
+}
+
+// Silences список заглушений.
+//
+//easyjson:json
+type Silences []Silence
+
+// compile проверяет заглушение и разбирает его матчеры.
+func (s *Silence) compile() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("matchers required: %w", ErrInvalidSilence)
+	}
+	if s.CreatedBy == "" {
+		return fmt.Errorf("created_by required: %w", ErrInvalidSilence)
+	}
+	if s.EndsAt.IsZero() || !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at: %w", ErrInvalidSilence)
+	}
+
+	s.matchers = make([]model.Matcher, 0, len(s.Matchers))
+	for _, expr := range s.Matchers {
+		m, err := model.ParseMatcher(expr)
+		if err != nil {
+			return fmt.Errorf("%w: matcher: %w", ErrInvalidSilence, err)
+		}
+		s.matchers = append(s.matchers, m)
+	}
+	return nil
+}
+
+// StatusAt возвращает состояние заглушения на момент now.
+func (s Silence) StatusAt(now time.Time) Status {
+	switch {
+	case now.Before(s.StartsAt):
+		return StatusPending
+	case now.Before(s.EndsAt):
+		return StatusActive
+	default:
+		return StatusExpired
+	}
+}
+
+// Matches сообщает, заглушает ли заглушение оповещение с метками labels.
+// Состояние заглушения не учитывается.
+func (s Silence) Matches(labels model.Labels) bool {
+	return model.MatchLabels(labels, s.matchers)
+}
@@ -0,0 +1,282 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package silence
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson5b49b24fDecodeGithubComHtrandevMetricsInternalSilence(in *jlexer.Lexer, out *Silences) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(Silences, 0, 0)
+			} else {
+				*out = Silences{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Silence
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				(v1).UnmarshalEasyJSON(in)
+			}
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5b49b24fEncodeGithubComHtrandevMetricsInternalSilence(out *jwriter.Writer, in Silences) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Silences) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5b49b24fEncodeGithubComHtrandevMetricsInternalSilence(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Silences) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5b49b24fEncodeGithubComHtrandevMetricsInternalSilence(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Silences) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5b49b24fDecodeGithubComHtrandevMetricsInternalSilence(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Silences) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5b49b24fDecodeGithubComHtrandevMetricsInternalSilence(l, v)
+}
+func easyjson5b49b24fDecodeGithubComHtrandevMetricsInternalSilence1(in *jlexer.Lexer, out *Silence) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "id":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.ID = string(in.String())
+			}
+		case "matchers":
+			if in.IsNull() {
+				in.Skip()
+				out.Matchers = nil
+			} else {
+				in.Delim('[')
+				if out.Matchers == nil {
+					if !in.IsDelim(']') {
+						out.Matchers = make([]string, 0, 4)
+					} else {
+						out.Matchers = []string{}
+					}
+				} else {
+					out.Matchers = (out.Matchers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v4 = string(in.String())
+					}
+					out.Matchers = append(out.Matchers, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "starts_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.StartsAt).UnmarshalJSON(data))
+				}
+			}
+		case "ends_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.EndsAt).UnmarshalJSON(data))
+				}
+			}
+		case "created_by":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.CreatedBy = string(in.String())
+			}
+		case "comment":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Comment = string(in.String())
+			}
+		case "created_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.CreatedAt).UnmarshalJSON(data))
+				}
+			}
+		case "updated_at":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+				}
+			}
+		case "status":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Status = Status(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5b49b24fEncodeGithubComHtrandevMetricsInternalSilence1(out *jwriter.Writer, in Silence) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"matchers\":"
+		out.RawString(prefix)
+		if in.Matchers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Matchers {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"starts_at\":"
+		out.RawString(prefix)
+		out.Raw((in.StartsAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"ends_at\":"
+		out.RawString(prefix)
+		out.Raw((in.EndsAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"created_by\":"
+		out.RawString(prefix)
+		out.String(string(in.CreatedBy))
+	}
+	if in.Comment != "" {
+		const prefix string = ",\"comment\":"
+		out.RawString(prefix)
+		out.String(string(in.Comment))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	if in.Status != "" {
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Silence) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5b49b24fEncodeGithubComHtrandevMetricsInternalSilence1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Silence) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5b49b24fEncodeGithubComHtrandevMetricsInternalSilence1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Silence) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5b49b24fDecodeGithubComHtrandevMetricsInternalSilence1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Silence) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5b49b24fDecodeGithubComHtrandevMetricsInternalSilence1(l, v)
+}
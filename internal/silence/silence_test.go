@@ -0,0 +1,171 @@
+package silence
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+func TestSilenceValidate(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		silence Silence
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			silence: Silence{
+				Matchers:  []string{`alertname="HighCPU"`, `host=~"web-.*"`},
+				StartsAt:  now,
+				EndsAt:    now.Add(time.Hour),
+				CreatedBy: "ops",
+			},
+		},
+		{
+			name:    "no matchers",
+			silence: Silence{StartsAt: now, EndsAt: now.Add(time.Hour), CreatedBy: "ops"},
+			wantErr: true,
+		},
+		{
+			name: "invalid matcher",
+			silence: Silence{
+				Matchers:  []string{`host`},
+				StartsAt:  now,
+				EndsAt:    now.Add(time.Hour),
+				CreatedBy: "ops",
+			},
+			wantErr: true,
+		},
+		{
+			name: "ends before start",
+			silence: Silence{
+				Matchers:  []string{`host="web-1"`},
+				StartsAt:  now,
+				EndsAt:    now.Add(-time.Hour),
+				CreatedBy: "ops",
+			},
+			wantErr: true,
+		},
+		{
+			name: "no creator",
+			silence: Silence{
+				Matchers: []string{`host="web-1"`},
+				StartsAt: now,
+				EndsAt:   now.Add(time.Hour),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.silence.compile()
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSilence)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "silences.jsonl")
+
+	m, err := NewManager(ctx, NewFileStorage(path))
+	require.NoError(t, err)
+	m.now = func() time.Time { return now }
+
+	web, err := m.Create(ctx, Silence{
+		Matchers:  []string{`host="web-1"`},
+		EndsAt:    now.Add(time.Hour),
+		CreatedBy: "ops",
+		Comment:   "kernel upgrade",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, web.ID)
+	require.Equal(t, now, web.StartsAt)
+	require.Equal(t, StatusActive, web.Status)
+
+	db, err := m.Create(ctx, Silence{
+		Matchers:  []string{`alertname="HighCPU"`, `host=~"db-.*"`},
+		StartsAt:  now.Add(time.Hour),
+		EndsAt:    now.Add(2 * time.Hour),
+		CreatedBy: "dba",
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, db.Status)
+
+	_, err = m.Create(ctx, Silence{Matchers: []string{`host="web-2"`}, CreatedBy: "ops"})
+	require.ErrorIs(t, err, ErrInvalidSilence)
+
+	labels := model.Labels{"alertname": "HighCPU", "host": "db-1"}
+	require.Equal(t, []string{web.ID}, m.Silenced(model.Labels{"alertname": "HighCPU", "host": "web-1"}, now))
+	require.Empty(t, m.Silenced(labels, now))
+	require.Equal(t, []string{db.ID}, m.Silenced(labels, now.Add(90*time.Minute)))
+	require.Empty(t, m.Silenced(labels, now.Add(2*time.Hour)))
+
+	web.EndsAt = now.Add(3 * time.Hour)
+	web.Comment = "kernel upgrade, extended"
+	updated, err := m.Update(ctx, web.ID, web)
+	require.NoError(t, err)
+	require.Equal(t, web.CreatedAt, updated.CreatedAt)
+	require.Equal(t, "kernel upgrade, extended", updated.Comment)
+
+	_, err = m.Update(ctx, "unknown", web)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, m.Delete(ctx, db.ID))
+	require.ErrorIs(t, m.Delete(ctx, db.ID), ErrNotFound)
+	_, err = m.Get(db.ID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// после перезапуска восстанавливаются актуальные версии заглушений
+	restored, err := NewManager(ctx, NewFileStorage(path))
+	require.NoError(t, err)
+	restored.now = m.now
+	require.Equal(t, m.List(), restored.List())
+	require.Len(t, restored.List(), 1)
+	require.Equal(t, []string{web.ID}, restored.Silenced(model.Labels{"host": "web-1"}, now.Add(2*time.Hour)))
+
+	// файл сжимается при загрузке
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, bytes.Count(b, []byte("\n")))
+}
+
+func TestManagerInMemory(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := NewManager(ctx, nil)
+	require.NoError(t, err)
+
+	s, err := m.Create(ctx, Silence{
+		Matchers:  []string{`host="web-1"`},
+		EndsAt:    time.Now().Add(time.Hour),
+		CreatedBy: "ops",
+	})
+	require.NoError(t, err)
+	require.Len(t, m.List(), 1)
+	require.NoError(t, m.Delete(ctx, s.ID))
+	require.Empty(t, m.List())
+}
+
+func TestFileStorageCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "silences.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\n"), 0600))
+
+	_, err := NewManager(context.Background(), NewFileStorage(path))
+	require.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+// ErrInvalidLine возвращается при некорректной строке StatsD.
+var ErrInvalidLine = errors.New("invalid statsd line")
+
+// MinRate минимальная частота выборки. Значение пересчитывается с весом 1/Rate,
+// поэтому меньшие частоты позволили бы одной строкой добавить произвольно большой вес.
+const MinRate = 0.001
+
+// MaxCounterValue максимальный модуль значения счетчика: целые до 2^53 точно представимы float64,
+// и значение одной строки с учетом частоты выборки не переполняет int64.
+const MaxCounterValue = 1 << 53
+
+// Kind тип метрики StatsD.
+type Kind uint8
+
+const (
+	KindCounter Kind = iota // c
+	KindGauge               // g
+	KindTimer               // ms
+)
+
+// Sample разобранная строка StatsD.
+type Sample struct {
+	Name   string
+	Labels model.Labels
+	Kind   Kind
+	Value  float64
+	// Rate частота выборки из диапазона [MinRate, 1].
+	Rate float64
+	// Relative сообщает, что значение gauge задано со знаком и изменяет текущее значение.
+	Relative bool
+}
+
+// Parse разбирает строку вида name:value|type[|@rate][|#tag:value,...].
+// Поддерживаются типы c, g и ms. Теги в формате DogStatsD становятся метками.
+func Parse(line string) (Sample, error) {
+	typeStart := strings.IndexByte(line, '|')
+	if typeStart < 0 {
+		return Sample{}, fmt.Errorf("parse %q: missing type: %w", line, ErrInvalidLine)
+	}
+	nameEnd := strings.LastIndexByte(line[:typeStart], ':')
+	if nameEnd <= 0 {
+		return Sample{}, fmt.Errorf("parse %q: missing name: %w", line, ErrInvalidLine)
+	}
+
+	s := Sample{Name: line[:nameEnd], Rate: 1}
+	if err := model.ValidateName(s.Name); err != nil {
+		return Sample{}, fmt.Errorf("parse %q: name: %w", line, ErrInvalidLine)
+	}
+	fields := strings.Split(line[nameEnd+1:], "|")
+
+	switch fields[1] {
+	case "c":
+		s.Kind = KindCounter
+	case "g":
+		s.Kind = KindGauge
+	case "ms":
+		s.Kind = KindTimer
+	default:
+		return Sample{}, fmt.Errorf("parse %q: unsupported type %q: %w", line, fields[1], ErrInvalidLine)
+	}
+
+	raw := fields[0]
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Sample{}, fmt.Errorf("parse %q: value: %w", line, ErrInvalidLine)
+	}
+	if s.Kind == KindCounter && math.Abs(v) > MaxCounterValue {
+		return Sample{}, fmt.Errorf("parse %q: counter value out of range: %w", line, ErrInvalidLine)
+	}
+	s.Value = v
+	s.Relative = s.Kind == KindGauge && (strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "-"))
+
+	for _, f := range fields[2:] {
+		switch {
+		case strings.HasPrefix(f, "@"):
+			rate, err := strconv.ParseFloat(f[1:], 64)
+			if err != nil || rate < MinRate || rate > 1 {
+				return Sample{}, fmt.Errorf("parse %q: sample rate: %w", line, ErrInvalidLine)
+			}
+			s.Rate = rate
+		case strings.HasPrefix(f, "#"):
+			s.Labels = parseTags(f[1:])
+			if err := s.Labels.Validate(); err != nil {
+				return Sample{}, fmt.Errorf("parse %q: tags: %w", line, ErrInvalidLine)
+			}
+		default:
+			return Sample{}, fmt.Errorf("parse %q: unknown field %q: %w", line, f, ErrInvalidLine)
+		}
+	}
+	return s, nil
+}
+
+// parseTags разбирает теги вида a:1,b:2. Тег без значения становится меткой с пустым значением,
+// теги без имени пропускаются.
+func parseTags(s string) model.Labels {
+	labels := make(model.Labels)
+	for _, tag := range strings.Split(s, ",") {
+		if tag == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(tag, ":")
+		if name == "" {
+			continue
+		}
+		labels[name] = value
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
@@ -0,0 +1,243 @@
+// Package statsd реализует прием метрик по протоколу StatsD через UDP.
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+const maxPacketSize = 64 * 1024
+
+// DefaultFlushInterval интервал сохранения по умолчанию.
+const DefaultFlushInterval = 10 * time.Second
+
+// DefaultTimerBounds границы бакетов гистограммы таймеров в миллисекундах.
+var DefaultTimerBounds = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Storer сохраняет пакет метрик.
+type Storer interface {
+	StoreMany(ctx context.Context, metrics []model.MetricDto) error
+}
+
+// ServerOptions параметры StatsD сервера.
+type ServerOptions struct {
+	// FlushInterval интервал, за который агрегируются значения перед сохранением.
+	// По умолчанию используется DefaultFlushInterval.
+	FlushInterval time.Duration
+	// TimerBounds границы бакетов гистограммы таймеров.
+	// По умолчанию используется DefaultTimerBounds.
+	TimerBounds []float64
+
+	Storer Storer
+	Logger *zap.Logger
+}
+
+// Server принимает пакеты StatsD и периодически сохраняет агрегированные значения.
+//
+// Счетчики суммируются с учетом частоты выборки и сохраняются как counter.
+// Gauge сохраняют последнее значение, значения со знаком изменяют текущее.
+// Таймеры сохраняются как histogram.
+type Server struct {
+	opts *ServerOptions
+
+	mu       sync.Mutex
+	counters map[string]*counter
+	gauges   map[string]*gauge
+	timers   map[string]model.MetricDto
+}
+
+type counter struct {
+	metric model.MetricDto
+	value  float64
+}
+
+type gauge struct {
+	metric  model.MetricDto
+	changed bool
+}
+
+// New создает StatsD сервер.
+func New(opts *ServerOptions) *Server {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+	if len(opts.TimerBounds) == 0 {
+		opts.TimerBounds = DefaultTimerBounds
+	}
+	return &Server{
+		opts:     opts,
+		counters: make(map[string]*counter),
+		gauges:   make(map[string]*gauge),
+		timers:   make(map[string]model.MetricDto),
+	}
+}
+
+// Serve читает пакеты из conn, пока не будет отменен ctx.
+// После отмены закрывает conn и сохраняет накопленные значения.
+func (s *Server) Serve(ctx context.Context, conn net.PacketConn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.flushLoop(ctx)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				cancel()
+				wg.Wait()
+				return nil
+			}
+			return fmt.Errorf("statsd/serve: read packet: %w", err)
+		}
+		s.handlePacket(string(buf[:n]))
+	}
+}
+
+// flushLoop сохраняет значения каждые FlushInterval и при отмене ctx.
+func (s *Server) flushLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// контекст уже отменен, сохраняем накопленное с отдельным контекстом
+			s.Flush(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			s.Flush(ctx)
+		}
+	}
+}
+
+// handlePacket разбирает пакет, строки в котором разделены переводом строки.
+func (s *Server) handlePacket(packet string) {
+	for _, line := range strings.Split(packet, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sample, err := Parse(line)
+		if err != nil {
+			s.opts.Logger.Debug("parse line", zap.Error(err), zap.String("scope", "statsd/handlePacket"))
+			continue
+		}
+		s.Add(sample)
+	}
+}
+
+// Add добавляет значение в агрегат текущего интервала.
+func (s *Server) Add(sample Sample) {
+	metric := model.MetricDto{Name: sample.Name, Labels: sample.Labels}
+	key := metric.Key()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch sample.Kind {
+	case KindCounter:
+		c, ok := s.counters[key]
+		if !ok {
+			metric.Value.Type = model.TypeCounter
+			c = &counter{metric: metric}
+			s.counters[key] = c
+		}
+		c.value += sample.Value / sample.Rate
+	case KindGauge:
+		g, ok := s.gauges[key]
+		if !ok {
+			metric.Value.Type = model.TypeGauge
+			g = &gauge{metric: metric}
+			s.gauges[key] = g
+		}
+		if sample.Relative {
+			g.metric.Value.Gauge += sample.Value
+		} else {
+			g.metric.Value.Gauge = sample.Value
+		}
+		g.changed = true
+	case KindTimer:
+		t, ok := s.timers[key]
+		if !ok {
+			metric.Value.Type = model.TypeHistogram
+			metric.Value.Histogram = model.NewHistogram(slices.Clone(s.opts.TimerBounds))
+			t = metric
+			s.timers[key] = t
+		}
+		t.Value.Histogram.ObserveN(sample.Value, uint64(math.Round(1/sample.Rate)))
+	}
+}
+
+// Flush сохраняет значения, накопленные с предыдущего сохранения.
+// Gauge сохраняются, только если изменились, но их значения сохраняются между интервалами.
+func (s *Server) Flush(ctx context.Context) {
+	metrics := s.collect()
+	if len(metrics) == 0 {
+		return
+	}
+
+	if err := s.opts.Storer.StoreMany(ctx, metrics); err != nil {
+		s.opts.Logger.Error("store many", zap.Error(err), zap.Int("count", len(metrics)), zap.String("scope", "statsd/flush"))
+	}
+}
+
+// maxFlushDelta максимальный модуль прироста счетчика за один интервал.
+const maxFlushDelta = 1 << 62
+
+// collect возвращает агрегаты интервала и сбрасывает счетчики и таймеры.
+func (s *Server) collect() []model.MetricDto {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := make([]model.MetricDto, 0, len(s.counters)+len(s.gauges)+len(s.timers))
+	for key, c := range s.counters {
+		// сумма за интервал ограничивается диапазоном int64, превышение переносится как остаток
+		delta := max(min(math.Round(c.value), maxFlushDelta), -maxFlushDelta)
+		if delta == 0 {
+			continue
+		}
+		c.metric.Value.Counter = int64(delta)
+		metrics = append(metrics, c.metric)
+
+		// дробный остаток переносится в следующий интервал
+		c.value -= delta
+		if c.value == 0 {
+			delete(s.counters, key)
+		}
+	}
+	for _, g := range s.gauges {
+		if !g.changed {
+			continue
+		}
+		metrics = append(metrics, g.metric)
+		g.changed = false
+	}
+	for _, t := range s.timers {
+		metrics = append(metrics, t)
+	}
+	clear(s.timers)
+	return metrics
+}
@@ -0,0 +1,172 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/htrandev/metrics/internal/model"
+)
+
+type mockStorer struct {
+	mu      sync.Mutex
+	metrics []model.MetricDto
+}
+
+func (m *mockStorer) StoreMany(_ context.Context, metrics []model.MetricDto) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = append(m.metrics, metrics...)
+	return nil
+}
+
+func (m *mockStorer) stored() []model.MetricDto {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.MetricDto(nil), m.metrics...)
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		line     string
+		expected Sample
+		wantErr  bool
+	}{
+		{
+			name:     "counter",
+			line:     "requests:1|c",
+			expected: Sample{Name: "requests", Kind: KindCounter, Value: 1, Rate: 1},
+		},
+		{
+			name:     "counter with sample rate",
+			line:     "requests:2|c|@0.1",
+			expected: Sample{Name: "requests", Kind: KindCounter, Value: 2, Rate: 0.1},
+		},
+		{
+			name:     "gauge",
+			line:     "temperature:21.5|g",
+			expected: Sample{Name: "temperature", Kind: KindGauge, Value: 21.5, Rate: 1},
+		},
+		{
+			name:     "relative gauge",
+			line:     "queue:-3|g",
+			expected: Sample{Name: "queue", Kind: KindGauge, Value: -3, Rate: 1, Relative: true},
+		},
+		{
+			name:     "timer with tags",
+			line:     "latency:320|ms|#host:web-1,env",
+			expected: Sample{Name: "latency", Kind: KindTimer, Value: 320, Rate: 1, Labels: model.Labels{"host": "web-1", "env": ""}},
+		},
+		{name: "missing type", line: "requests:1", wantErr: true},
+		{name: "missing name", line: ":1|c", wantErr: true},
+		{name: "invalid name", line: `requests{code="200"}:1|c`, wantErr: true},
+		{name: "invalid value", line: "requests:abc|c", wantErr: true},
+		{name: "unsupported type", line: "users:1|s", wantErr: true},
+		{name: "invalid sample rate", line: "requests:1|c|@2", wantErr: true},
+		{name: "sample rate below minimum", line: "latency:1|ms|@0.0000001", wantErr: true},
+		{name: "invalid tag name", line: "requests:1|c|#host.name:web-1", wantErr: true},
+		{name: "nan counter", line: "requests:NaN|c", wantErr: true},
+		{name: "infinite gauge", line: "temperature:+Inf|g", wantErr: true},
+		{name: "negative infinite timer", line: "latency:-inf|ms", wantErr: true},
+		{name: "value out of range", line: "temperature:1e400|g", wantErr: true},
+		{name: "counter out of range", line: "requests:1e300|c", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.line)
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidLine)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	ctx := context.Background()
+	storer := &mockStorer{}
+	s := New(&ServerOptions{
+		TimerBounds: []float64{100, 500},
+		Storer:      storer,
+		Logger:      zap.NewNop(),
+	})
+
+	s.handlePacket("requests:1|c\nrequests:1|c|@0.5\nqueue:10|g\nqueue:+2|g\nlatency:50|ms\nlatency:700|ms|@0.5\nbroken")
+	s.Flush(ctx)
+
+	expected := []model.MetricDto{
+		model.Counter("requests", 3),
+		model.Gauge("queue", 12),
+		model.HistogramMetric("latency", &model.Histogram{
+			Bounds: []float64{100, 500},
+			Counts: []uint64{1, 0, 2},
+			Sum:    1450,
+			Count:  3,
+		}),
+	}
+	require.ElementsMatch(t, expected, storer.stored())
+
+	// без новых значений ничего не сохраняется, gauge сохраняет значение между интервалами
+	s.Flush(ctx)
+	require.Len(t, storer.stored(), 3)
+
+	s.handlePacket("queue:-5|g")
+	s.Flush(ctx)
+	require.Equal(t, model.Gauge("queue", 7), storer.stored()[3])
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	storer := &mockStorer{}
+	s := New(&ServerOptions{
+		FlushInterval: 20 * time.Millisecond,
+		Storer:        storer,
+		Logger:        zap.NewNop(),
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ctx, conn)
+	}()
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("jobs:2|c|#queue:mail\ntemperature:20|g"))
+	require.NoError(t, err)
+
+	mail := model.Counter("jobs", 2)
+	mail.Labels = model.Labels{"queue": "mail"}
+	require.Eventually(t, func() bool {
+		return len(storer.stored()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.ElementsMatch(t, []model.MetricDto{mail, model.Gauge("temperature", 20)}, storer.stored())
+
+	// значения, полученные до остановки, сохраняются при завершении
+	_, err = client.Write([]byte("jobs:1|c"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.counters) == 1 || len(storer.stored()) == 3
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Contains(t, storer.stored(), model.Counter("jobs", 1))
+}
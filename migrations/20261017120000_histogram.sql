@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics
+    ADD COLUMN IF NOT EXISTS hist_bounds DOUBLE PRECISION[],
+    ADD COLUMN IF NOT EXISTS hist_counts BIGINT[],
+    ADD COLUMN IF NOT EXISTS hist_sum DOUBLE PRECISION DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS hist_count BIGINT DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE metrics
+    DROP COLUMN IF EXISTS hist_bounds,
+    DROP COLUMN IF EXISTS hist_counts,
+    DROP COLUMN IF EXISTS hist_sum,
+    DROP COLUMN IF EXISTS hist_count;
+-- +goose StatementEnd
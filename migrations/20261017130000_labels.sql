@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics
+    ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS labels_key TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_pkey;
+ALTER TABLE metrics ADD PRIMARY KEY (name, type, labels_key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM metrics WHERE labels_key <> '';
+
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_pkey;
+ALTER TABLE metrics ADD PRIMARY KEY (name, type);
+
+ALTER TABLE metrics
+    DROP COLUMN IF EXISTS labels,
+    DROP COLUMN IF EXISTS labels_key;
+-- +goose StatementEnd
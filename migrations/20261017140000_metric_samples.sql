@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS metric_samples (
+    name TEXT NOT NULL,
+    type SMALLINT NOT NULL,
+    labels JSONB NOT NULL DEFAULT '{}',
+    labels_key TEXT NOT NULL DEFAULT '',
+    ts TIMESTAMPTZ NOT NULL,
+    value DOUBLE PRECISION NOT NULL
+) PARTITION BY RANGE (ts);
+
+CREATE TABLE IF NOT EXISTS metric_samples_default PARTITION OF metric_samples DEFAULT;
+
+CREATE INDEX IF NOT EXISTS metric_samples_series_ts_idx ON metric_samples (name, labels_key, ts);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS metric_samples;
+-- +goose StatementEnd
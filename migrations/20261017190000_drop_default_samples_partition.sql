@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metric_samples DETACH PARTITION metric_samples_default;
+
+DO $$
+DECLARE
+    d DATE;
+BEGIN
+    FOR d IN SELECT DISTINCT (ts AT TIME ZONE 'UTC')::DATE FROM metric_samples_default LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF metric_samples FOR VALUES FROM (%L) TO (%L)',
+            'metric_samples_' || to_char(d, 'YYYYMMDD'),
+            d::TIMESTAMP AT TIME ZONE 'UTC',
+            (d + 1)::TIMESTAMP AT TIME ZONE 'UTC'
+        );
+    END LOOP;
+END $$;
+
+INSERT INTO metric_samples SELECT * FROM metric_samples_default;
+
+DROP TABLE metric_samples_default;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS metric_samples_default PARTITION OF metric_samples DEFAULT;
+-- +goose StatementEnd
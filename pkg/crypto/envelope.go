@@ -0,0 +1,124 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Envelope format (all integers are big endian):
+//
+//	magic       4 bytes  "MENV"
+//	version     1 byte   EnvelopeVersion
+//	keyLen      2 bytes  length of the wrapped data key
+//	wrappedKey  keyLen   AES-256 data key encrypted with RSA-OAEP (SHA-256)
+//	nonce       12 bytes AES-GCM nonce
+//	ciphertext  rest     AES-256-GCM sealed payload with the tag
+//
+// The header (magic, version, keyLen and wrappedKey) is authenticated
+// as additional data, so it can not be changed without failing decryption.
+const (
+	envelopeMagic = "MENV"
+	// EnvelopeVersion is the current envelope format version.
+	EnvelopeVersion byte = 1
+
+	dataKeySize = 32
+	headerSize  = len(envelopeMagic) + 1 + 2
+)
+
+var (
+	// ErrInvalidEnvelope is returned when payload is not a well-formed envelope.
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	// ErrUnsupportedVersion is returned for envelopes of unknown format version.
+	ErrUnsupportedVersion = errors.New("unsupported envelope version")
+)
+
+// Encrypt encrypts payload of any size with a random AES-256-GCM data key
+// and wraps the data key with public key using RSA-OAEP.
+func Encrypt(key *rsa.PublicKey, payload []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("crypto: generate data key: %w", err)
+	}
+
+	wrapped, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, dataKey, nil)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: wrap data key: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %w", err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("crypto: generate nonce: %w", err)
+	}
+
+	size := headerSize + len(wrapped) + len(nonce) + len(payload) + gcm.Overhead()
+	out := make([]byte, 0, size)
+	out = append(out, envelopeMagic...)
+	out = append(out, EnvelopeVersion)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(wrapped)))
+	out = append(out, wrapped...)
+	header := out[:len(out):len(out)]
+
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, payload, header), nil
+}
+
+// Decrypt unwraps the data key with private key and decrypts the envelope payload.
+func Decrypt(key *rsa.PrivateKey, payload []byte) ([]byte, error) {
+	if len(payload) < headerSize || !bytes.HasPrefix(payload, []byte(envelopeMagic)) {
+		return nil, fmt.Errorf("crypto: decrypt payload: %w", ErrInvalidEnvelope)
+	}
+	if v := payload[len(envelopeMagic)]; v != EnvelopeVersion {
+		return nil, fmt.Errorf("crypto: decrypt payload: version %d: %w", v, ErrUnsupportedVersion)
+	}
+
+	keyLen := int(binary.BigEndian.Uint16(payload[len(envelopeMagic)+1 : headerSize]))
+	if len(payload) < headerSize+keyLen {
+		return nil, fmt.Errorf("crypto: decrypt payload: truncated key: %w", ErrInvalidEnvelope)
+	}
+	header := payload[:headerSize+keyLen]
+
+	dataKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, header[headerSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: unwrap data key: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %w", err)
+	}
+
+	rest := payload[len(header):]
+	if len(rest) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, fmt.Errorf("crypto: decrypt payload: truncated ciphertext: %w", ErrInvalidEnvelope)
+	}
+
+	decrypted, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: decrypt payload: %w", err)
+	}
+	return decrypted, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return gcm, nil
+}
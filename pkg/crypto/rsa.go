@@ -1,9 +1,7 @@
 package crypto
 
 import (
-	"crypto/rand"
 	"crypto/rsa"
-	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -53,21 +51,3 @@ func PublicKey(filename string) (*rsa.PublicKey, error) {
 
 	return publicKey, nil
 }
-
-// Encrypt encrypts payload with public key.
-func Encrypt(key *rsa.PublicKey, payload []byte) ([]byte, error) {
-	encrypted, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, payload, nil)
-	if err != nil {
-		return nil, fmt.Errorf("crypto: encrypt payload: %w", err)
-	}
-	return encrypted, nil
-}
-
-// Decrypt decrypts payload with private key.
-func Decrypt(key *rsa.PrivateKey, payload []byte) ([]byte, error) {
-	decrypted, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, payload, nil)
-	if err != nil {
-		return nil, fmt.Errorf("crypto: decrypt payload: %w", err)
-	}
-	return decrypted, nil
-}
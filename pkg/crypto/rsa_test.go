@@ -1,6 +1,9 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
 	"path/filepath"
 	"testing"
 
@@ -34,3 +37,87 @@ func TestRsa(t *testing.T) {
 
 	require.Equal(t, testdata, decrypted)
 }
+
+func TestEnvelope(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// payload much larger than RSA-OAEP limit for 2048-bit key
+	payload := bytes.Repeat([]byte(`{"id":"metric","type":"gauge","value":0.1}`), 1000)
+
+	encrypted, err := Encrypt(&privateKey.PublicKey, payload)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		key           *rsa.PrivateKey
+		data          func() []byte
+		wantErr       bool
+		expectedError error
+	}{
+		{
+			name: "valid",
+			key:  privateKey,
+			data: func() []byte { return encrypted },
+		},
+		{
+			name:          "not an envelope",
+			key:           privateKey,
+			data:          func() []byte { return payload },
+			wantErr:       true,
+			expectedError: ErrInvalidEnvelope,
+		},
+		{
+			name: "unsupported version",
+			key:  privateKey,
+			data: func() []byte {
+				b := bytes.Clone(encrypted)
+				b[len(envelopeMagic)] = EnvelopeVersion + 1
+				return b
+			},
+			wantErr:       true,
+			expectedError: ErrUnsupportedVersion,
+		},
+		{
+			name:          "truncated",
+			key:           privateKey,
+			data:          func() []byte { return encrypted[:headerSize+10] },
+			wantErr:       true,
+			expectedError: ErrInvalidEnvelope,
+		},
+		{
+			name: "tampered ciphertext",
+			key:  privateKey,
+			data: func() []byte {
+				b := bytes.Clone(encrypted)
+				b[len(b)-1] ^= 0xff
+				return b
+			},
+			wantErr: true,
+		},
+		{
+			name:    "wrong key",
+			key:     otherKey,
+			data:    func() []byte { return encrypted },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decrypted, err := Decrypt(tc.key, tc.data())
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedError != nil {
+					require.ErrorIs(t, err, tc.expectedError)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, payload, decrypted)
+		})
+	}
+}
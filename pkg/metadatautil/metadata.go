@@ -2,13 +2,24 @@ package metadatautil
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"google.golang.org/grpc/metadata"
+
+	"github.com/htrandev/metrics/pkg/sign"
 )
 
 const (
 	keyRealIP  = "real_ip"
-	keyHash256 = "hash_256"
+	keyBatchID = "batch_id"
+	keyAuth    = "authorization"
+	keyReqID   = "x-request-id"
+
+	keySignKeyID     = "sign_key_id"
+	keySignTimestamp = "sign_timestamp"
+	keySignNonce     = "sign_nonce"
+	keySignature     = "signature"
 )
 
 // SetRealIP устанавливает в контекст IP-адрес.
@@ -21,14 +32,71 @@ func GetRealIP(ctx context.Context) string {
 	return getKey(ctx, keyRealIP)
 }
 
-// SetHash256 устанавливает в контекст hash256.
-func SetHash256(ctx context.Context, hash string) context.Context {
-	return setKey(ctx, keyHash256, hash)
+// ReplaceRealIP заменяет IP-адрес во входящем контексте.
+func ReplaceRealIP(ctx context.Context, ip string) context.Context {
+	return replaceKey(ctx, keyRealIP, ip)
+}
+
+// SetRequestID устанавливает в контекст идентификатор запроса.
+func SetRequestID(ctx context.Context, id string) context.Context {
+	return setKey(ctx, keyReqID, id)
+}
+
+// GetRequestID возвращает идентификатор запроса из контекста.
+func GetRequestID(ctx context.Context) string {
+	return getKey(ctx, keyReqID)
+}
+
+// ReplaceRequestID заменяет идентификатор запроса во входящем контексте.
+func ReplaceRequestID(ctx context.Context, id string) context.Context {
+	return replaceKey(ctx, keyReqID, id)
+}
+
+// SetStamp устанавливает в контекст параметры подписи запроса.
+func SetStamp(ctx context.Context, st sign.Stamp) context.Context {
+	ctx = setKey(ctx, keySignKeyID, st.KeyID)
+	ctx = setKey(ctx, keySignTimestamp, strconv.FormatInt(st.Timestamp, 10))
+	ctx = setKey(ctx, keySignNonce, st.Nonce)
+	return setKey(ctx, keySignature, st.Signature)
+}
+
+// GetStamp возвращает параметры подписи запроса из контекста.
+// Возвращает false, если запрос не подписан.
+func GetStamp(ctx context.Context) (sign.Stamp, bool, error) {
+	signature := getKey(ctx, keySignature)
+	if signature == "" {
+		return sign.Stamp{}, false, nil
+	}
+	ts, err := strconv.ParseInt(getKey(ctx, keySignTimestamp), 10, 64)
+	if err != nil {
+		return sign.Stamp{}, true, fmt.Errorf("parse timestamp: %w", sign.ErrMalformed)
+	}
+	return sign.Stamp{
+		KeyID:     getKey(ctx, keySignKeyID),
+		Timestamp: ts,
+		Nonce:     getKey(ctx, keySignNonce),
+		Signature: signature,
+	}, true, nil
 }
 
-// GetHash256 возвращает hash из контекста.
-func GetHash256(ctx context.Context) string {
-	return getKey(ctx, keyHash256)
+// SetBearerToken устанавливает в контекст токен агента.
+func SetBearerToken(ctx context.Context, token string) context.Context {
+	return setKey(ctx, keyAuth, "Bearer "+token)
+}
+
+// GetAuthorization возвращает значение authorization из контекста.
+func GetAuthorization(ctx context.Context) string {
+	return getKey(ctx, keyAuth)
+}
+
+// SetBatchID устанавливает в контекст идентификатор пакета метрик.
+func SetBatchID(ctx context.Context, id string) context.Context {
+	return setKey(ctx, keyBatchID, id)
+}
+
+// GetBatchID возвращает идентификатор пакета метрик из контекста.
+func GetBatchID(ctx context.Context) string {
+	return getKey(ctx, keyBatchID)
 }
 
 func getKey(ctx context.Context, key string) string {
@@ -51,3 +119,13 @@ func setKey(ctx context.Context, key, val string) context.Context {
 	md.Set(key, val)
 	return metadata.NewOutgoingContext(ctx, md)
 }
+
+func replaceKey(ctx context.Context, key, val string) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+	md = md.Copy()
+	md.Set(key, val)
+	return metadata.NewIncomingContext(ctx, md)
+}
@@ -3,6 +3,7 @@ package netutil
 import (
 	"fmt"
 	"net"
+	"strings"
 )
 
 // CIDR return subnet of given string.
@@ -14,6 +15,74 @@ func CIDR(s string) (*net.IPNet, error) {
 	return subnet, nil
 }
 
+// Subnets is a list of IPv4 and IPv6 subnets.
+type Subnets []*net.IPNet
+
+// ParseCIDRs returns subnets of given values.
+// Each value may hold several comma separated CIDRs, empty entries are skipped.
+func ParseCIDRs(values ...string) (Subnets, error) {
+	var subnets Subnets
+	for _, value := range values {
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			subnet, err := CIDR(s)
+			if err != nil {
+				return nil, err
+			}
+			subnets = append(subnets, subnet)
+		}
+	}
+	return subnets, nil
+}
+
+// Contains reports whether ip belongs to any of subnets.
+func (s Subnets) Contains(ip net.IP) bool {
+	for _, subnet := range s {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns client ip of connection with remote address addr.
+//
+// If the connection comes from a trusted proxy, X-Forwarded-For hops are walked
+// from right to left while they belong to trusted proxies, the first untrusted hop
+// is the client. Hops added by the client itself are never reached.
+// Returns nil if ip can't be resolved.
+func ClientIP(addr string, forwardedFor []string, proxies Subnets) net.IP {
+	ip := parseIP(addr)
+	if ip == nil {
+		return nil
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0 && proxies.Contains(ip); i-- {
+		hop := parseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+	}
+	return ip
+}
+
+// parseIP parses ip with optional port.
+func parseIP(s string) net.IP {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return net.ParseIP(s)
+}
+
 // GetLocalIP returns local ip address.
 func GetLocalIP() (net.IP, error) {
 	addresses, err := net.InterfaceAddrs()
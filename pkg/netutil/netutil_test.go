@@ -0,0 +1,93 @@
+package netutil
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	subnets, err := ParseCIDRs("10.0.0.0/8, 192.168.1.0/24", "", "fd00::/8")
+	require.NoError(t, err)
+	require.Len(t, subnets, 3)
+
+	require.True(t, subnets.Contains(net.ParseIP("10.1.2.3")))
+	require.True(t, subnets.Contains(net.ParseIP("192.168.1.7")))
+	require.True(t, subnets.Contains(net.ParseIP("fd12::1")))
+	require.False(t, subnets.Contains(net.ParseIP("172.16.0.1")))
+	require.False(t, subnets.Contains(net.ParseIP("2001:db8::1")))
+
+	_, err = ParseCIDRs("10.0.0.0/8,invalid")
+	require.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseCIDRs("10.0.0.0/8,fd00::/8")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		addr         string
+		forwardedFor []string
+		expectedIP   string
+	}{
+		{
+			name:       "direct connection",
+			addr:       "192.168.1.5:4321",
+			expectedIP: "192.168.1.5",
+		},
+		{
+			name:         "untrusted peer forwarded for is ignored",
+			addr:         "192.168.1.5:4321",
+			forwardedFor: []string{"10.0.0.1"},
+			expectedIP:   "192.168.1.5",
+		},
+		{
+			name:         "trusted proxy",
+			addr:         "10.0.0.2:4321",
+			forwardedFor: []string{"192.168.1.5"},
+			expectedIP:   "192.168.1.5",
+		},
+		{
+			name:         "spoofed hop before trusted chain",
+			addr:         "10.0.0.2:4321",
+			forwardedFor: []string{"10.9.9.9, 172.16.0.3", "10.0.0.3"},
+			expectedIP:   "172.16.0.3",
+		},
+		{
+			name:         "ipv6 proxy",
+			addr:         "[fd00::2]:4321",
+			forwardedFor: []string{"2001:db8::5"},
+			expectedIP:   "2001:db8::5",
+		},
+		{
+			name:         "trusted proxy without forwarded for",
+			addr:         "10.0.0.2:4321",
+			forwardedFor: nil,
+			expectedIP:   "10.0.0.2",
+		},
+		{
+			name:         "invalid hop",
+			addr:         "10.0.0.2:4321",
+			forwardedFor: []string{"unknown"},
+			expectedIP:   "10.0.0.2",
+		},
+		{
+			name:       "unresolvable address",
+			addr:       "pipe",
+			expectedIP: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ip := ClientIP(tc.addr, tc.forwardedFor, proxies)
+			if tc.expectedIP == "" {
+				require.Nil(t, ip)
+				return
+			}
+			require.Equal(t, tc.expectedIP, ip.String())
+		})
+	}
+}
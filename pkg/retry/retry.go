@@ -0,0 +1,40 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// ErrPermanent означает, что повторная отправка не поможет.
+var ErrPermanent = errors.New("permanent delivery error")
+
+// Backoff возвращает задержку перед повторной попыткой attempt:
+// экспоненциальную от minBackoff, ограниченную maxBackoff,
+// со случайным разбросом в ее половине.
+func Backoff(attempt int, minBackoff, maxBackoff time.Duration) time.Duration {
+	d := maxBackoff
+	if attempt < 32 {
+		if exp := minBackoff << attempt; exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// CheckStatus проверяет код HTTP ответа.
+// Ответы 2xx успешны, 408, 429 и 5xx повторяются,
+// остальные возвращают ошибку, обернутую в ErrPermanent.
+func CheckStatus(code int) error {
+	switch {
+	case code >= 200 && code < 300:
+		return nil
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= 500:
+		return fmt.Errorf("unexpected status code: %d", code)
+	default:
+		return fmt.Errorf("%w: unexpected status code: %d", ErrPermanent, code)
+	}
+}
@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "first", attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "exponential", attempt: 3, min: 400 * time.Millisecond, max: 800 * time.Millisecond},
+		{name: "capped", attempt: 10, min: 500 * time.Millisecond, max: time.Second},
+		{name: "overflow", attempt: 100, min: 500 * time.Millisecond, max: time.Second},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := Backoff(tc.attempt, 100*time.Millisecond, time.Second)
+			require.GreaterOrEqual(t, d, tc.min)
+			require.LessOrEqual(t, d, tc.max)
+		})
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	testCases := []struct {
+		name      string
+		code      int
+		wantErr   bool
+		permanent bool
+	}{
+		{name: "ok", code: http.StatusOK},
+		{name: "accepted", code: http.StatusAccepted},
+		{name: "timeout", code: http.StatusRequestTimeout, wantErr: true},
+		{name: "too many requests", code: http.StatusTooManyRequests, wantErr: true},
+		{name: "server error", code: http.StatusBadGateway, wantErr: true},
+		{name: "bad request", code: http.StatusBadRequest, wantErr: true, permanent: true},
+		{name: "redirect", code: http.StatusFound, wantErr: true, permanent: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckStatus(tc.code)
+			if !tc.wantErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tc.permanent, errors.Is(err, ErrPermanent))
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeyID идентификатор ключа, используемый, если он не задан явно.
+const DefaultKeyID = "default"
+
+// DefaultWindow допустимое расхождение времени подписи и времени проверки по умолчанию.
+const DefaultWindow = 5 * time.Minute
+
+// HTTP заголовки подписи запроса.
+const (
+	HeaderKeyID     = "X-Signature-Key-Id"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+var (
+	// ErrMalformed возвращается, если параметры подписи отсутствуют или некорректны.
+	ErrMalformed = errors.New("malformed signature")
+	// ErrUnknownKey возвращается для подписи неизвестным или выведенным из оборота ключом.
+	ErrUnknownKey = errors.New("unknown signature key")
+	// ErrStale возвращается, если время подписи выходит за допустимое окно.
+	ErrStale = errors.New("stale signature timestamp")
+	// ErrReplay возвращается при повторном использовании nonce.
+	ErrReplay = errors.New("signature nonce already used")
+	// ErrInvalid возвращается, если подпись не совпадает.
+	ErrInvalid = errors.New("invalid signature")
+)
+
+// Stamp параметры подписи запроса.
+// Подпись покрывает идентификатор ключа, время, nonce, адресат запроса и тело.
+type Stamp struct {
+	KeyID     string
+	Timestamp int64
+	Nonce     string
+	Signature string
+}
+
+// NewStamp подписывает тело запроса к target ключом key с идентификатором keyID.
+// target определяет адресат запроса, например "POST /updates/" или полное имя gRPC метода.
+func NewStamp(keyID string, key Signature, target string, body []byte) (Stamp, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return Stamp{}, fmt.Errorf("sign: generate nonce: %w", err)
+	}
+
+	st := Stamp{
+		KeyID:     keyID,
+		Timestamp: time.Now().Unix(),
+		Nonce:     hex.EncodeToString(nonce),
+	}
+	st.Signature = base64.RawURLEncoding.EncodeToString(key.Sign(st.canonical(target, body)))
+	return st, nil
+}
+
+// canonical возвращает подписываемое представление запроса.
+func (st Stamp) canonical(target string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return []byte(strings.Join([]string{
+		"v1",
+		st.KeyID,
+		strconv.FormatInt(st.Timestamp, 10),
+		st.Nonce,
+		target,
+		hex.EncodeToString(sum[:]),
+	}, "\n"))
+}
+
+// SetHeader записывает параметры подписи в HTTP заголовки.
+func (st Stamp) SetHeader(h http.Header) {
+	h.Set(HeaderKeyID, st.KeyID)
+	h.Set(HeaderTimestamp, strconv.FormatInt(st.Timestamp, 10))
+	h.Set(HeaderNonce, st.Nonce)
+	h.Set(HeaderSignature, st.Signature)
+}
+
+// StampFromHeader читает параметры подписи из HTTP заголовков.
+// Возвращает false, если запрос не подписан.
+func StampFromHeader(h http.Header) (Stamp, bool, error) {
+	if h.Get(HeaderSignature) == "" {
+		return Stamp{}, false, nil
+	}
+	ts, err := strconv.ParseInt(h.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return Stamp{}, true, fmt.Errorf("parse timestamp: %w", ErrMalformed)
+	}
+	return Stamp{
+		KeyID:     h.Get(HeaderKeyID),
+		Timestamp: ts,
+		Nonce:     h.Get(HeaderNonce),
+		Signature: h.Get(HeaderSignature),
+	}, true, nil
+}
+
+// Keyring набор активных ключей подписи по их идентификаторам.
+// Для ротации новый ключ добавляется в набор до перевода на него агентов,
+// а старый удаляется после.
+type Keyring map[string]Signature
+
+// ParseKeyring разбирает ключи в формате "id:secret".
+func ParseKeyring(specs []string) (Keyring, error) {
+	keys := make(Keyring, len(specs))
+	for _, spec := range specs {
+		id, secret, ok := strings.Cut(spec, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("sign: parse key [%s]: expected id:secret: %w", id, ErrMalformed)
+		}
+		keys[id] = Signature(secret)
+	}
+	return keys, nil
+}
+
+// Verifier проверяет подписи запросов с защитой от повторов.
+// Запрос принимается, если время подписи отличается от текущего не более чем на окно,
+// а его nonce не встречался в течение этого окна.
+type Verifier struct {
+	keys   Keyring
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewVerifier создает Verifier для набора ключей keys.
+// Если window не положителен, используется DefaultWindow.
+func NewVerifier(keys Keyring, window time.Duration) *Verifier {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Verifier{
+		keys:   keys,
+		window: window,
+		now:    time.Now,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Enabled сообщает, задан ли хотя бы один ключ.
+func (v *Verifier) Enabled() bool {
+	return v != nil && len(v.keys) > 0
+}
+
+// Verify проверяет подпись тела запроса к target.
+func (v *Verifier) Verify(st Stamp, target string, body []byte) error {
+	if st.Nonce == "" || st.Signature == "" {
+		return fmt.Errorf("sign: verify: %w", ErrMalformed)
+	}
+
+	key, ok := v.keys[st.KeyID]
+	if !ok {
+		return fmt.Errorf("sign: verify: key [%s]: %w", st.KeyID, ErrUnknownKey)
+	}
+
+	now := v.now()
+	signedAt := time.Unix(st.Timestamp, 0)
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return fmt.Errorf("sign: verify: signed at %s: %w", signedAt.UTC().Format(time.RFC3339), ErrStale)
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(st.Signature)
+	if err != nil {
+		return fmt.Errorf("sign: verify: decode signature: %w", ErrMalformed)
+	}
+	if !hmac.Equal(got, key.Sign(st.canonical(target, body))) {
+		return fmt.Errorf("sign: verify: %w", ErrInvalid)
+	}
+
+	// nonce запоминается только для верной подписи, чтобы чужие запросы
+	// не могли заполнить кэш
+	return v.remember(st.KeyID+":"+st.Nonce, signedAt.Add(v.window), now)
+}
+
+// remember сохраняет nonce до момента expires.
+func (v *Verifier) remember(nonce string, expires, now time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if now.Sub(v.lastSweep) >= v.window {
+		for n, exp := range v.nonces {
+			if now.After(exp) {
+				delete(v.nonces, n)
+			}
+		}
+		v.lastSweep = now
+	}
+
+	if _, ok := v.nonces[nonce]; ok {
+		return fmt.Errorf("sign: verify: %w", ErrReplay)
+	}
+	v.nonces[nonce] = expires
+	return nil
+}
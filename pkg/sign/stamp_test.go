@@ -0,0 +1,167 @@
+package sign
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier(t *testing.T) {
+	keys := Keyring{"old": Signature("old-secret"), "new": Signature("new-secret")}
+	body := []byte(`[{"id":"gauge","type":"gauge","value":0.1}]`)
+	target := "POST /updates/"
+
+	stamp := func(t *testing.T, keyID string, key Signature, shift time.Duration) Stamp {
+		st, err := NewStamp(keyID, key, target, body)
+		require.NoError(t, err)
+		if shift != 0 {
+			st.Timestamp = time.Now().Add(shift).Unix()
+			st = resign(st, key, target, body)
+		}
+		return st
+	}
+
+	testCases := []struct {
+		name          string
+		stamp         func(t *testing.T) Stamp
+		target        string
+		body          []byte
+		expectedError error
+	}{
+		{
+			name:   "valid",
+			stamp:  func(t *testing.T) Stamp { return stamp(t, "new", keys["new"], 0) },
+			target: target,
+			body:   body,
+		},
+		{
+			name:   "rotated key still active",
+			stamp:  func(t *testing.T) Stamp { return stamp(t, "old", keys["old"], 0) },
+			target: target,
+			body:   body,
+		},
+		{
+			name:          "unknown key",
+			stamp:         func(t *testing.T) Stamp { return stamp(t, "retired", Signature("retired"), 0) },
+			target:        target,
+			body:          body,
+			expectedError: ErrUnknownKey,
+		},
+		{
+			name:          "wrong secret",
+			stamp:         func(t *testing.T) Stamp { return stamp(t, "new", keys["old"], 0) },
+			target:        target,
+			body:          body,
+			expectedError: ErrInvalid,
+		},
+		{
+			name:          "tampered body",
+			stamp:         func(t *testing.T) Stamp { return stamp(t, "new", keys["new"], 0) },
+			target:        target,
+			body:          []byte(`[]`),
+			expectedError: ErrInvalid,
+		},
+		{
+			name:          "other target",
+			stamp:         func(t *testing.T) Stamp { return stamp(t, "new", keys["new"], 0) },
+			target:        "POST /update/",
+			body:          body,
+			expectedError: ErrInvalid,
+		},
+		{
+			name:          "stale",
+			stamp:         func(t *testing.T) Stamp { return stamp(t, "new", keys["new"], -10*time.Minute) },
+			target:        target,
+			body:          body,
+			expectedError: ErrStale,
+		},
+		{
+			name:          "from future",
+			stamp:         func(t *testing.T) Stamp { return stamp(t, "new", keys["new"], 10*time.Minute) },
+			target:        target,
+			body:          body,
+			expectedError: ErrStale,
+		},
+		{
+			name:          "malformed",
+			stamp:         func(t *testing.T) Stamp { return Stamp{KeyID: "new", Timestamp: time.Now().Unix()} },
+			target:        target,
+			body:          body,
+			expectedError: ErrMalformed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := NewVerifier(keys, DefaultWindow)
+			err := v.Verify(tc.stamp(t), tc.target, tc.body)
+			if tc.expectedError != nil {
+				require.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestVerifierReplay(t *testing.T) {
+	keys := Keyring{DefaultKeyID: Signature("secret")}
+	body := []byte("body")
+
+	v := NewVerifier(keys, time.Minute)
+	st, err := NewStamp(DefaultKeyID, keys[DefaultKeyID], "rpc", body)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Verify(st, "rpc", body))
+	require.ErrorIs(t, v.Verify(st, "rpc", body), ErrReplay)
+
+	// после окна nonce забывается, но и сама подпись уже устарела
+	now := time.Now()
+	v.now = func() time.Time { return now.Add(2 * time.Minute) }
+	require.ErrorIs(t, v.Verify(st, "rpc", body), ErrStale)
+
+	st2, err := NewStamp(DefaultKeyID, keys[DefaultKeyID], "rpc", body)
+	require.NoError(t, err)
+	st2.Timestamp = now.Add(2 * time.Minute).Unix()
+	st2 = resign(st2, keys[DefaultKeyID], "rpc", body)
+	require.NoError(t, v.Verify(st2, "rpc", body))
+	require.NotContains(t, v.nonces, DefaultKeyID+":"+st.Nonce)
+}
+
+func TestHeader(t *testing.T) {
+	st, err := NewStamp("k1", Signature("secret"), "POST /updates/", nil)
+	require.NoError(t, err)
+
+	h := http.Header{}
+	_, ok, err := StampFromHeader(h)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	st.SetHeader(h)
+	got, ok, err := StampFromHeader(h)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, st, got)
+
+	h.Set(HeaderTimestamp, "now")
+	_, _, err = StampFromHeader(h)
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestParseKeyring(t *testing.T) {
+	keys, err := ParseKeyring([]string{"k1:secret1", "k2:sec:ret2"})
+	require.NoError(t, err)
+	require.Equal(t, Keyring{"k1": Signature("secret1"), "k2": Signature("sec:ret2")}, keys)
+
+	_, err = ParseKeyring([]string{"secret"})
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+// resign пересчитывает подпись после изменения параметров.
+func resign(st Stamp, key Signature, target string, body []byte) Stamp {
+	st.Signature = base64.RawURLEncoding.EncodeToString(key.Sign(st.canonical(target, body)))
+	return st
+}
@@ -0,0 +1,86 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNoCertificates is returned when CA file contains no PEM certificates.
+var ErrNoCertificates = errors.New("no certificates found")
+
+// ServerConfig returns TLS config for a server with the given certificate and key.
+// If caFile is set, client certificates are verified against it: they are
+// required when requireClientCert is true and verified only if presented otherwise.
+func ServerConfig(certFile, keyFile, caFile string, requireClientCert bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tlsutil: load key pair: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if caFile == "" {
+		if requireClientCert {
+			return nil, errors.New("tlsutil: client certificates required but ca file is not set")
+		}
+		return cfg, nil
+	}
+
+	pool, err := CertPool(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("tlsutil: %w", err)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if requireClientCert {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// ClientConfig returns TLS config for a client.
+// Server certificate is verified against caFile or system roots if caFile is empty.
+// Client certificate is presented if certFile and keyFile are set.
+func ClientConfig(certFile, keyFile, caFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		pool, err := CertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("tlsutil: %w", err)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tlsutil: load key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// CertPool returns pool with PEM certificates from the given file.
+func CertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("parse ca file [%s]: %w", caFile, ErrNoCertificates)
+	}
+	return pool, nil
+}
@@ -0,0 +1,162 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testPKI writes CA, server and client certificates to dir.
+type testPKI struct {
+	caFile, serverCert, serverKey, clientCert, clientKey string
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		certFile := filepath.Join(dir, name+".crt")
+		keyFile := filepath.Join(dir, name+".key")
+		writePEM(t, certFile, "CERTIFICATE", der)
+		writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+		return certFile, keyFile
+	}
+
+	p := testPKI{caFile: filepath.Join(dir, "ca.crt")}
+	writePEM(t, p.caFile, "CERTIFICATE", caDER)
+	p.serverCert, p.serverKey = issue("server", 2, x509.ExtKeyUsageServerAuth)
+	p.clientCert, p.clientKey = issue("agent-1", 3, x509.ExtKeyUsageClientAuth)
+	return p
+}
+
+func writePEM(t *testing.T, name, typ string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	require.NoError(t, os.WriteFile(name, data, 0600))
+}
+
+func TestMutualTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	testCases := []struct {
+		name              string
+		requireClientCert bool
+		clientCert        bool
+		wantErr           bool
+		expectedPeer      string
+	}{
+		{
+			name:              "client certificate required and presented",
+			requireClientCert: true,
+			clientCert:        true,
+			expectedPeer:      "agent-1",
+		},
+		{
+			name:              "client certificate required but missing",
+			requireClientCert: true,
+			clientCert:        false,
+			wantErr:           true,
+		},
+		{
+			name:              "client certificate optional",
+			requireClientCert: false,
+			clientCert:        false,
+			expectedPeer:      "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			serverCfg, err := ServerConfig(pki.serverCert, pki.serverKey, pki.caFile, tc.requireClientCert)
+			require.NoError(t, err)
+
+			var peer string
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(r.TLS.VerifiedChains) > 0 {
+					peer = r.TLS.VerifiedChains[0][0].Subject.CommonName
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			srv.TLS = serverCfg
+			srv.StartTLS()
+			defer srv.Close()
+
+			certFile, keyFile := "", ""
+			if tc.clientCert {
+				certFile, keyFile = pki.clientCert, pki.clientKey
+			}
+			clientCfg, err := ClientConfig(certFile, keyFile, pki.caFile, "")
+			require.NoError(t, err)
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientCfg}}
+			resp, err := client.Get(srv.URL)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tc.expectedPeer, peer)
+		})
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	pki := newTestPKI(t)
+
+	_, err := ServerConfig(pki.serverCert, pki.serverKey, "", true)
+	require.Error(t, err)
+
+	_, err = ServerConfig(pki.serverCert, pki.serverKey, pki.serverKey, false)
+	require.ErrorIs(t, err, ErrNoCertificates)
+
+	_, err = ClientConfig(pki.clientCert, "", pki.caFile, "")
+	require.Error(t, err)
+}